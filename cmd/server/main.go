@@ -19,11 +19,13 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	_ "github.com/rhajizada/llamero/docs"
+	"github.com/rhajizada/llamero/internal/auth"
 	"github.com/rhajizada/llamero/internal/config"
 	"github.com/rhajizada/llamero/internal/db"
+	"github.com/rhajizada/llamero/internal/guardrails"
 	"github.com/rhajizada/llamero/internal/logging"
+	"github.com/rhajizada/llamero/internal/profiles"
 	"github.com/rhajizada/llamero/internal/redisstore"
-	"github.com/rhajizada/llamero/internal/repository"
 	"github.com/rhajizada/llamero/internal/roles"
 	"github.com/rhajizada/llamero/internal/server"
 	"github.com/rhajizada/llamero/internal/service"
@@ -92,6 +94,16 @@ func newServerEnvironment(
 		return nil, fmt.Errorf("load roles: %w", err)
 	}
 
+	profileStore, err := profiles.Load(cfg.Profiles.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("load profiles: %w", err)
+	}
+
+	guardChain, err := guardrails.Load(cfg.Guardrails.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("load guardrails: %w", err)
+	}
+
 	pool, err := setupDatabase(ctx, cfg)
 	if err != nil {
 		return nil, err
@@ -106,8 +118,7 @@ func newServerEnvironment(
 		return nil, fmt.Errorf("connect redis: %w", err)
 	}
 
-	queries := repository.New(pool)
-	svc := service.New(queries, cacheStore)
+	svc := service.New(pool, cacheStore)
 
 	defs, err := config.LoadBackendDefinitions(cfg.Backends.FilePath)
 	if err != nil {
@@ -131,7 +142,8 @@ func newServerEnvironment(
 		}
 	})
 
-	srv, err := server.New(cfg, roleStore, svc, taskClient, logger)
+	states := newStateStore(cfg, cacheStore)
+	srv, err := server.New(cfg, roleStore, profileStore, guardChain, svc, states, taskClient, logger)
 	if err != nil {
 		env.Close()
 		return nil, fmt.Errorf("init server: %w", err)
@@ -142,6 +154,13 @@ func newServerEnvironment(
 	return env, nil
 }
 
+func newStateStore(cfg *config.ServerConfig, cacheStore *redisstore.Store) auth.StateStore {
+	if cfg.OAuth.StateStore == config.StateStoreMemory {
+		return auth.NewMemoryStateStore(cfg.OAuth.StateTTL)
+	}
+	return auth.NewRedisStateStore(cacheStore.Client(), cfg.OAuth.StateTTL)
+}
+
 func setupDatabase(ctx context.Context, cfg *config.ServerConfig) (*pgxpool.Pool, error) {
 	dsn := cfg.Database.Postgres.DSN()
 	if err := db.Migrate(ctx, dsn, cfg.Database.MigrationsDir); err != nil {
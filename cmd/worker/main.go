@@ -13,13 +13,19 @@ import (
 
 	"github.com/rhajizada/llamero/internal/config"
 	"github.com/rhajizada/llamero/internal/db"
+	"github.com/rhajizada/llamero/internal/guardrails"
 	"github.com/rhajizada/llamero/internal/logging"
+	"github.com/rhajizada/llamero/internal/profiles"
 	"github.com/rhajizada/llamero/internal/redisstore"
-	"github.com/rhajizada/llamero/internal/repository"
 	"github.com/rhajizada/llamero/internal/service"
 	"github.com/rhajizada/llamero/internal/workers"
 )
 
+const (
+	defaultQueuePriority = 3
+	lowQueuePriority     = 1
+)
+
 func main() {
 	logger := logging.New()
 	slog.SetDefault(logger)
@@ -45,9 +51,12 @@ func run() error {
 	}
 	defer env.Close()
 
-	handler := workers.NewHandler(env.service)
+	handler := workers.NewHandler(env.service, env.batches, env.jobs, env.tasks, cfg.Webhooks)
 	env.mux.HandleFunc(workers.TypeSyncBackends, handler.HandleSyncBackends)
 	env.mux.HandleFunc(workers.TypeSyncBackendByID, handler.HandleSyncBackendByID)
+	env.mux.HandleFunc(workers.TypeRunBatch, handler.HandleRunBatch)
+	env.mux.HandleFunc(workers.TypeRunJob, handler.HandleRunJob)
+	env.mux.HandleFunc(workers.TypeDeliverWebhook, handler.HandleDeliverWebhook)
 
 	return env.server.Run(env.mux)
 }
@@ -56,6 +65,9 @@ type workerEnvironment struct {
 	server  *asynq.Server
 	mux     *asynq.ServeMux
 	service *service.Service
+	batches *service.BatchRunner
+	jobs    *service.JobRunner
+	tasks   *asynq.Client
 	closers []func()
 }
 
@@ -89,8 +101,19 @@ func newWorkerEnvironment(ctx context.Context, cfg *config.WorkerConfig) (*worke
 		return nil, fmt.Errorf("connect redis: %w", err)
 	}
 
-	queries := repository.New(pool)
-	svc := service.New(queries, cacheStore)
+	profileStore, err := profiles.Load(cfg.Profiles.FilePath)
+	if err != nil {
+		env.Close()
+		return nil, fmt.Errorf("load profiles: %w", err)
+	}
+
+	guardChain, err := guardrails.Load(cfg.Guardrails.FilePath)
+	if err != nil {
+		env.Close()
+		return nil, fmt.Errorf("load guardrails: %w", err)
+	}
+
+	svc := service.New(pool, cacheStore)
 
 	connOpt := &asynq.RedisClientOpt{
 		Addr:     cfg.Store.Addr,
@@ -102,13 +125,18 @@ func newWorkerEnvironment(ctx context.Context, cfg *config.WorkerConfig) (*worke
 	server := asynq.NewServer(connOpt, asynq.Config{
 		Concurrency: cfg.Worker.Concurrency,
 		Queues: map[string]int{
-			"default": 1,
+			"default":        defaultQueuePriority,
+			workers.QueueLow: lowQueuePriority,
 		},
 	})
 
 	env.server = server
 	env.mux = asynq.NewServeMux()
 	env.service = svc
+	env.batches = service.NewBatchRunner(svc, profileStore, guardChain, cfg.Batches)
+	env.jobs = service.NewJobRunner(svc, profileStore, guardChain, cfg.Jobs, cfg.Webhooks)
+	env.tasks = asynq.NewClient(connOpt)
+	env.addCloser(func() { _ = env.tasks.Close() })
 	return env, nil
 }
 
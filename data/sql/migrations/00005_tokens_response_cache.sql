@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE tokens
+    ADD COLUMN response_cache BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE tokens
+    DROP COLUMN IF EXISTS response_cache;
@@ -0,0 +1,59 @@
+-- +goose Up
+CREATE TABLE files (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    bytes BIGINT NOT NULL,
+    content BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX files_user_id_idx ON files(user_id);
+
+CREATE TABLE batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    input_file_id UUID NOT NULL REFERENCES files(id) ON DELETE RESTRICT,
+    output_file_id UUID REFERENCES files(id) ON DELETE SET NULL,
+    error_file_id UUID REFERENCES files(id) ON DELETE SET NULL,
+    completion_window TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'validating',
+    error_message TEXT,
+    metadata JSONB,
+    request_total INTEGER NOT NULL DEFAULT 0,
+    request_completed INTEGER NOT NULL DEFAULT 0,
+    request_failed INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    in_progress_at TIMESTAMPTZ,
+    finalizing_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    failed_at TIMESTAMPTZ,
+    expired_at TIMESTAMPTZ,
+    cancelling_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX batches_user_id_idx ON batches(user_id);
+
+CREATE TABLE batch_results (
+    batch_id UUID NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    custom_id TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    body BYTEA,
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (batch_id, line)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS batch_results;
+DROP INDEX IF EXISTS batches_user_id_idx;
+DROP TABLE IF EXISTS batches;
+DROP INDEX IF EXISTS files_user_id_idx;
+DROP TABLE IF EXISTS files;
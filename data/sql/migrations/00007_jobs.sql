@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    model TEXT NOT NULL,
+    request BYTEA NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    status_code INTEGER,
+    result BYTEA,
+    error_message TEXT,
+    callback_url TEXT,
+    webhook_status TEXT,
+    webhook_attempts INTEGER NOT NULL DEFAULT 0,
+    webhook_last_error TEXT,
+    webhook_delivered_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX jobs_user_id_idx ON jobs(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_user_id_idx;
+DROP TABLE IF EXISTS jobs;
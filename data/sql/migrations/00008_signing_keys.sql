@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE signing_keys (
+    kid TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    private_key TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    retired_at TIMESTAMPTZ,
+    verify_until TIMESTAMPTZ
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS signing_keys;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    jti TEXT NOT NULL UNIQUE,
+    refresh_token_hash TEXT NOT NULL UNIQUE,
+    user_agent TEXT,
+    ip_address TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions(user_id);
+
+CREATE TABLE session_retired_refresh_tokens (
+    refresh_token_hash TEXT PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    retired_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX session_retired_refresh_tokens_session_id_idx ON session_retired_refresh_tokens(session_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS session_retired_refresh_tokens_session_id_idx;
+DROP TABLE IF EXISTS session_retired_refresh_tokens;
+DROP INDEX IF EXISTS sessions_user_id_idx;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN role_override TEXT,
+    ADD COLUMN scopes_override TEXT[];
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN IF EXISTS scopes_override,
+    DROP COLUMN IF EXISTS role_override,
+    DROP COLUMN IF EXISTS disabled;
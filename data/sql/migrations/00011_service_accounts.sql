@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN kind TEXT NOT NULL DEFAULT 'user' CHECK (kind IN ('user', 'service')),
+    ADD COLUMN description TEXT,
+    ADD COLUMN owner_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX users_owner_id_idx ON users(owner_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS users_owner_id_idx;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS owner_id,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS kind;
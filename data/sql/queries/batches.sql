@@ -0,0 +1,98 @@
+-- name: CreateBatch :one
+INSERT INTO batches (user_id, role, endpoint, input_file_id, completion_window, metadata, request_total, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListBatchesByUser :many
+SELECT *
+FROM batches
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: GetBatchByID :one
+SELECT *
+FROM batches
+WHERE id = $1
+  AND user_id = $2;
+
+-- name: GetBatchForRun :one
+SELECT *
+FROM batches
+WHERE id = $1;
+
+-- name: GetBatchStatus :one
+SELECT status
+FROM batches
+WHERE id = $1;
+
+-- name: StartBatch :exec
+UPDATE batches
+SET status = 'in_progress',
+    in_progress_at = COALESCE(in_progress_at, now()),
+    updated_at = now()
+WHERE id = $1
+  AND status = 'validating';
+
+-- name: CancelBatch :one
+UPDATE batches
+SET status = 'cancelling',
+    cancelling_at = now(),
+    updated_at = now()
+WHERE id = $1
+  AND user_id = $2
+  AND status IN ('validating', 'in_progress')
+RETURNING *;
+
+-- name: MarkBatchFinalizing :exec
+UPDATE batches
+SET status = CASE WHEN status = 'in_progress' THEN 'finalizing' ELSE status END,
+    finalizing_at = now(),
+    updated_at = now()
+WHERE id = $1;
+
+-- name: FinishBatch :exec
+UPDATE batches
+SET status = sqlc.arg(status)::text,
+    output_file_id = sqlc.narg(output_file_id),
+    error_file_id = sqlc.narg(error_file_id),
+    error_message = sqlc.narg(error_message),
+    completed_at = CASE WHEN sqlc.arg(status)::text = 'completed' THEN now() ELSE completed_at END,
+    failed_at = CASE WHEN sqlc.arg(status)::text = 'failed' THEN now() ELSE failed_at END,
+    expired_at = CASE WHEN sqlc.arg(status)::text = 'expired' THEN now() ELSE expired_at END,
+    cancelled_at = CASE WHEN sqlc.arg(status)::text = 'cancelled' THEN now() ELSE cancelled_at END,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: RecordBatchResult :exec
+WITH inserted AS (
+    INSERT INTO batch_results (batch_id, line, custom_id, status_code, body, error_message)
+    VALUES (sqlc.arg(batch_id), sqlc.arg(line), sqlc.arg(custom_id), sqlc.arg(status_code), sqlc.arg(body),
+            sqlc.narg(error_message))
+    ON CONFLICT (batch_id, line) DO NOTHING
+    RETURNING status_code
+)
+UPDATE batches
+SET request_completed = request_completed + (
+        SELECT count(*) FROM inserted WHERE status_code BETWEEN 200 AND 299
+    ),
+    request_failed = request_failed + (
+        SELECT count(*) FROM inserted WHERE status_code NOT BETWEEN 200 AND 299
+    ),
+    updated_at = now()
+WHERE id = sqlc.arg(batch_id);
+
+-- name: ListBatchResultLines :many
+SELECT line
+FROM batch_results
+WHERE batch_id = $1;
+
+-- name: ListBatchResults :many
+SELECT *
+FROM batch_results
+WHERE batch_id = $1
+ORDER BY line;
+
+-- name: DeleteBatchResults :exec
+DELETE FROM batch_results
+WHERE batch_id = $1;
@@ -0,0 +1,27 @@
+-- name: CreateFile :one
+INSERT INTO files (user_id, filename, purpose, bytes, content)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, filename, purpose, bytes, created_at;
+
+-- name: ListFilesByUser :many
+SELECT id, user_id, filename, purpose, bytes, created_at
+FROM files
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetFileByID :one
+SELECT id, user_id, filename, purpose, bytes, created_at
+FROM files
+WHERE id = $1
+  AND user_id = $2;
+
+-- name: GetFileContent :one
+SELECT content
+FROM files
+WHERE id = $1
+  AND user_id = $2;
+
+-- name: DeleteFile :execrows
+DELETE FROM files
+WHERE id = $1
+  AND user_id = $2;
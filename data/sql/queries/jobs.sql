@@ -0,0 +1,44 @@
+-- name: CreateJob :one
+INSERT INTO jobs (user_id, role, kind, model, request, callback_url)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetJobByID :one
+SELECT *
+FROM jobs
+WHERE id = $1
+  AND user_id = $2;
+
+-- name: GetJobForRun :one
+SELECT *
+FROM jobs
+WHERE id = $1;
+
+-- name: StartJob :exec
+UPDATE jobs
+SET status = 'running',
+    started_at = COALESCE(started_at, now()),
+    updated_at = now()
+WHERE id = $1
+  AND status IN ('queued', 'running');
+
+-- name: FinishJob :one
+UPDATE jobs
+SET status = sqlc.arg(status)::text,
+    status_code = sqlc.narg(status_code),
+    result = sqlc.narg(result),
+    error_message = sqlc.narg(error_message),
+    webhook_status = CASE WHEN callback_url IS NULL THEN NULL ELSE 'pending' END,
+    finished_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: RecordJobWebhookAttempt :exec
+UPDATE jobs
+SET webhook_status = sqlc.arg(webhook_status)::text,
+    webhook_attempts = webhook_attempts + 1,
+    webhook_last_error = sqlc.narg(webhook_last_error),
+    webhook_delivered_at = CASE WHEN sqlc.arg(webhook_status)::text = 'delivered' THEN now() ELSE NULL END,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
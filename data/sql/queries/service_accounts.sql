@@ -0,0 +1,40 @@
+-- name: CreateServiceAccount :one
+INSERT INTO users (sub, provider, email, display_name, role, scopes, groups, kind, description, owner_id)
+VALUES (
+    sqlc.arg(name),
+    sqlc.arg(provider),
+    sqlc.arg(email),
+    sqlc.arg(name),
+    sqlc.arg(role),
+    sqlc.arg(scopes),
+    '{}',
+    'service',
+    sqlc.narg(description),
+    sqlc.narg(owner_id)
+)
+RETURNING *;
+
+-- name: ListServiceAccounts :many
+SELECT * FROM users
+WHERE kind = 'service'
+ORDER BY created_at DESC;
+
+-- name: GetServiceAccount :one
+SELECT * FROM users
+WHERE id = $1
+  AND kind = 'service';
+
+-- name: UpdateServiceAccount :one
+UPDATE users
+SET description = sqlc.narg(description),
+    scopes = sqlc.arg(scopes),
+    owner_id = sqlc.narg(owner_id),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND kind = 'service'
+RETURNING *;
+
+-- name: DeleteServiceAccount :execrows
+DELETE FROM users
+WHERE id = $1
+  AND kind = 'service';
@@ -0,0 +1,62 @@
+-- name: CreateSession :one
+INSERT INTO sessions (user_id, jti, refresh_token_hash, user_agent, ip_address, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetSessionByID :one
+SELECT *
+FROM sessions
+WHERE id = $1;
+
+-- name: GetSessionByRefreshToken :one
+SELECT *
+FROM sessions
+WHERE refresh_token_hash = $1;
+
+-- name: GetSessionByRetiredRefreshToken :one
+SELECT *
+FROM sessions
+WHERE id = (
+    SELECT session_id
+    FROM session_retired_refresh_tokens
+    WHERE session_retired_refresh_tokens.refresh_token_hash = $1
+);
+
+-- name: ListActiveSessionsByUser :many
+SELECT *
+FROM sessions
+WHERE user_id = $1
+  AND revoked = FALSE
+  AND expires_at > now()
+ORDER BY COALESCE(last_used_at, created_at) DESC;
+
+-- name: RotateSessionRefreshToken :one
+UPDATE sessions
+SET jti = sqlc.arg(jti),
+    refresh_token_hash = sqlc.arg(refresh_token_hash),
+    last_used_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND refresh_token_hash = sqlc.arg(current_refresh_token_hash)
+  AND revoked = FALSE
+RETURNING *;
+
+-- name: RetireSessionRefreshToken :exec
+INSERT INTO session_retired_refresh_tokens (refresh_token_hash, session_id)
+VALUES ($1, $2)
+ON CONFLICT (refresh_token_hash) DO NOTHING;
+
+-- name: RevokeSession :one
+UPDATE sessions
+SET revoked = TRUE,
+    updated_at = now()
+WHERE id = $1
+  AND user_id = $2
+RETURNING *;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions
+SET revoked = TRUE,
+    updated_at = now()
+WHERE user_id = $1
+  AND revoked = FALSE;
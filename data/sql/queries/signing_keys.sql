@@ -0,0 +1,23 @@
+-- name: ListSigningKeys :many
+SELECT *
+FROM signing_keys
+ORDER BY created_at DESC;
+
+-- name: CreateSigningKey :exec
+INSERT INTO signing_keys (kid, algorithm, public_key, private_key)
+VALUES ($1, $2, $3, $4);
+
+-- name: RetireSigningKey :exec
+INSERT INTO signing_keys (kid, algorithm, public_key, retired_at, verify_until)
+VALUES ($1, $2, $3, now(), sqlc.arg(verify_until)::timestamptz)
+ON CONFLICT (kid) DO UPDATE
+SET private_key = NULL,
+    retired_at = now(),
+    verify_until = EXCLUDED.verify_until
+WHERE signing_keys.retired_at IS NULL;
+
+-- name: LatestPersonalAccessTokenExpiry :one
+SELECT COALESCE(max(expires_at), now())::timestamptz AS expires_at
+FROM tokens
+WHERE token_type = 'pat'
+  AND revoked = FALSE;
@@ -1,23 +1,23 @@
 -- name: CreateToken :one
-INSERT INTO tokens (user_id, name, scopes, token_type, jti, expires_at)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at;
+INSERT INTO tokens (user_id, name, scopes, token_type, jti, expires_at, response_cache)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache;
 
 -- name: ListTokensByUser :many
-SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 FROM tokens
 WHERE user_id = $1
   AND COALESCE(revoked, FALSE) = FALSE
 ORDER BY created_at DESC;
 
 -- name: GetTokenByID :one
-SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 FROM tokens
 WHERE id = $1
   AND user_id = $2;
 
 -- name: GetTokenByJTI :one
-SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 FROM tokens
 WHERE jti = $1;
 
@@ -27,7 +27,7 @@ SET revoked = TRUE,
     updated_at = now()
 WHERE id = $1
   AND user_id = $2
-RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at;
+RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache;
 
 -- name: MarkTokenUsed :exec
 UPDATE tokens
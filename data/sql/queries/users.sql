@@ -5,10 +5,10 @@ ON CONFLICT (provider, sub)
 DO UPDATE SET
     email = EXCLUDED.email,
     display_name = EXCLUDED.display_name,
-    role = EXCLUDED.role,
-    scopes = EXCLUDED.scopes,
+    role = COALESCE(users.role_override, EXCLUDED.role),
+    scopes = COALESCE(users.scopes_override, EXCLUDED.scopes),
     groups = EXCLUDED.groups,
-    last_login_at = EXCLUDED.last_login_at,
+    last_login_at = CASE WHEN users.disabled THEN users.last_login_at ELSE EXCLUDED.last_login_at END,
     updated_at = now()
 RETURNING *;
 
@@ -19,7 +19,45 @@ SELECT * FROM users WHERE id = $1;
 SELECT * FROM users WHERE provider = $1 AND sub = $2;
 
 -- name: ListUsers :many
-SELECT * FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2;
+SELECT * FROM users
+WHERE kind = 'user'
+  AND (
+    sqlc.narg(search)::text IS NULL
+    OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+    OR display_name ILIKE '%' || sqlc.narg(search)::text || '%'
+    OR sub = sqlc.narg(search)::text
+  )
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
 
--- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1;
+-- name: CountUsers :one
+SELECT count(*) FROM users
+WHERE kind = 'user'
+  AND (
+    sqlc.narg(search)::text IS NULL
+    OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+    OR display_name ILIKE '%' || sqlc.narg(search)::text || '%'
+    OR sub = sqlc.narg(search)::text
+  );
+
+-- name: UpdateUserAccess :one
+UPDATE users
+SET role = sqlc.arg(role),
+    scopes = sqlc.arg(scopes),
+    role_override = sqlc.narg(role_override),
+    scopes_override = sqlc.narg(scopes_override),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND kind = 'user'
+RETURNING *;
+
+-- name: SetUserDisabled :one
+UPDATE users
+SET disabled = $2,
+    updated_at = now()
+WHERE id = $1
+  AND kind = 'user'
+RETURNING *;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1 AND kind = 'user';
@@ -18,21 +18,30 @@ type Claims struct {
 	Scopes      []string `json:"scopes"`
 	Type        string   `json:"type"`
 	ExternalSub string   `json:"ext_sub,omitempty"`
+	// SessionID identifies the login session of a session token; revoking it rejects the token.
+	SessionID string `json:"sid,omitempty"`
+	// ResponseCache marks personal access tokens that opted in to the LLM response cache.
+	ResponseCache bool `json:"cache,omitempty"`
 }
 
-// HasScopes returns true when all required scopes exist in the claim set.
+// HasScopes returns true when the claim set covers every required scope for all resources,
+// honouring wildcards such as "llm:*".
 func (c *Claims) HasScopes(required []string) bool {
-	if len(required) == 0 {
-		return true
-	}
-	available := make(map[string]struct{}, len(c.Scopes))
-	for _, scope := range c.Scopes {
-		available[scope] = struct{}{}
-	}
+	return HasScopes(c.Scopes, required)
+}
+
+// GrantsScopes returns true when the claim set reaches every required scope, possibly only for
+// some models or backends. Handlers enforce the constraints with PermitsResource.
+func (c *Claims) GrantsScopes(required []string) bool {
 	for _, scope := range required {
-		if _, ok := available[scope]; !ok {
+		if !GrantsScope(c.Scopes, scope) {
 			return false
 		}
 	}
 	return true
 }
+
+// PermitsResource returns true when the claim set allows scope on the resource.
+func (c *Claims) PermitsResource(scope string, res Resource) bool {
+	return PermitsResource(c.Scopes, scope, res)
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/config"
+)
+
+const (
+	// CSRFHeader carries the double-submit CSRF token on cookie-authenticated requests.
+	CSRFHeader = "X-CSRF-Token"
+
+	refreshCookieSuffix = "_refresh"
+	csrfCookieSuffix    = "_csrf"
+	refreshCookiePath   = "/auth"
+)
+
+// SessionCookies stores browser sessions in cookies. The access token and refresh token cookies
+// are HttpOnly; the CSRF cookie is readable by the UI, which echoes it in CSRFHeader on requests
+// that change state.
+type SessionCookies struct {
+	cfg       config.SessionConfig
+	accessTTL time.Duration
+}
+
+// NewSessionCookies builds the cookie settings for browser sessions. The access token cookie
+// lives as long as the access token.
+func NewSessionCookies(cfg config.SessionConfig, accessTTL time.Duration) *SessionCookies {
+	return &SessionCookies{cfg: cfg, accessTTL: accessTTL}
+}
+
+// Enabled reports whether browser sessions use cookies.
+func (c *SessionCookies) Enabled() bool {
+	return c != nil && c.cfg.Cookies
+}
+
+// Set writes the session cookies and returns the new CSRF token.
+func (c *SessionCookies) Set(w http.ResponseWriter, accessToken, refreshToken string) string {
+	csrf := rand.Text()
+	http.SetCookie(w, c.cookie(c.cfg.CookieName, accessToken, "/", c.accessTTL, true))
+	http.SetCookie(w, c.cookie(c.refreshName(), refreshToken, refreshCookiePath, c.cfg.RefreshTTL, true))
+	http.SetCookie(w, c.cookie(c.csrfName(), csrf, "/", c.cfg.RefreshTTL, false))
+	return csrf
+}
+
+// Clear expires the session cookies.
+func (c *SessionCookies) Clear(w http.ResponseWriter) {
+	for _, cookie := range []*http.Cookie{
+		c.cookie(c.cfg.CookieName, "", "/", 0, true),
+		c.cookie(c.refreshName(), "", refreshCookiePath, 0, true),
+		c.cookie(c.csrfName(), "", "/", 0, false),
+	} {
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+	}
+}
+
+// AccessToken returns the access token from the session cookie, if any.
+func (c *SessionCookies) AccessToken(r *http.Request) string {
+	if !c.Enabled() {
+		return ""
+	}
+	return c.value(r, c.cfg.CookieName)
+}
+
+// RefreshToken returns the refresh token from the refresh cookie, if any.
+func (c *SessionCookies) RefreshToken(r *http.Request) string {
+	if !c.Enabled() {
+		return ""
+	}
+	return c.value(r, c.refreshName())
+}
+
+// CheckCSRF reports whether a request may act on its session cookies. Safe methods always pass;
+// other requests must echo the CSRF cookie in CSRFHeader.
+func (c *SessionCookies) CheckCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	expected := c.value(r, c.csrfName())
+	actual := strings.TrimSpace(r.Header.Get(CSRFHeader))
+	if expected == "" || actual == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
+
+func (c *SessionCookies) value(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(cookie.Value)
+}
+
+func (c *SessionCookies) cookie(name, value, path string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.cfg.CookieDomain,
+		MaxAge:   int(ttl.Seconds()),
+		Secure:   c.cfg.CookieSecure,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite(),
+	}
+}
+
+func (c *SessionCookies) sameSite() http.SameSite {
+	switch c.cfg.CookieSameSite {
+	case config.SameSiteStrict:
+		return http.SameSiteStrictMode
+	case config.SameSiteNone:
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// CSRFCookieName is the name of the readable cookie the UI echoes in CSRFHeader.
+func (c *SessionCookies) CSRFCookieName() string {
+	return c.csrfName()
+}
+
+func (c *SessionCookies) refreshName() string {
+	return c.cfg.CookieName + refreshCookieSuffix
+}
+
+func (c *SessionCookies) csrfName() string {
+	return c.cfg.CookieName + csrfCookieSuffix
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+)
+
+const (
+	// uncompressedPointPrefix marks an uncompressed elliptic curve point in SEC 1 encoding.
+	uncompressedPointPrefix = 0x04
+	bitsPerByte             = 8
+	minRSAExponent          = 3
+)
+
+// JSONWebKey is a public key in a JSON Web Key Set (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key.
+func (k JSONWebKey) Thumbprint() string {
+	// The thumbprint hashes the required members only, in lexicographic order, which is how
+	// encoding/json orders map keys.
+	members := map[string]string{"kty": k.Kty}
+	switch k.Kty {
+	case "RSA":
+		members["n"], members["e"] = k.N, k.E
+	case "EC":
+		members["crv"], members["x"], members["y"] = k.Crv, k.X, k.Y
+	case "OKP":
+		members["crv"], members["x"] = k.Crv, k.X
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// fetchJWKS downloads a key set and returns its signing keys by key ID. Encryption keys and keys
+// of unsupported types are skipped.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]any, error) {
+	var set struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+	if err := getProviderJSON(ctx, client, url, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k JSONWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		return k.rsaKey()
+	case "EC":
+		return k.ecKey()
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (k JSONWebKey) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode rsa modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode rsa exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < minRSAExponent || exponent.Int64() > math.MaxInt32 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func (k JSONWebKey) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("decode ec x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("decode ec y: %w", err)
+	}
+	size := (curve.Params().BitSize + bitsPerByte - 1) / bitsPerByte
+	if len(x) > size || len(y) > size {
+		return nil, errors.New("invalid ec coordinates")
+	}
+	point := []byte{uncompressedPointPrefix}
+	point = append(point, leftPad(x, size)...)
+	point = append(point, leftPad(y, size)...)
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
+
+// leftPad restores leading zero bytes that encoders may strip from fixed-size coordinates.
+func leftPad(b []byte, size int) []byte {
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
+
+// publicJWK encodes a public key Llamero signs with as a JSON Web Key.
+func publicJWK(public any) (JSONWebKey, error) {
+	switch key := public.(type) {
+	case ed25519.PublicKey:
+		return JSONWebKey{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(key)}, nil
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	default:
+		return JSONWebKey{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
@@ -0,0 +1,554 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/rhajizada/llamero/internal/config"
+)
+
+// Key sources reported in SigningKey.Source.
+const (
+	KeySourceConfig   = "config"
+	KeySourceDatabase = "database"
+)
+
+const (
+	// keyRefreshInterval bounds how stale a replica's view of the key set gets.
+	keyRefreshInterval = time.Minute
+	// unknownKeyRefreshInterval rate-limits reloads triggered by tokens with an unknown key ID.
+	unknownKeyRefreshInterval = 5 * time.Second
+	rsaKeyBits                = 2048
+	pemPrivateKeyType         = "PRIVATE KEY"
+	pemPublicKeyType          = "PUBLIC KEY"
+	// sealedKeyPrefix marks a private key encrypted with the key encryption key. Unprefixed rows
+	// hold plain PEM, as written before encryption was configured.
+	sealedKeyPrefix     = "aes-256-gcm:"
+	keyEncryptionKeyLen = 32
+)
+
+// ErrUnknownSigningKey reports a token signed with a key that is not, or no longer, trusted.
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// SigningKey is a key Llamero tokens are signed or verified with.
+type SigningKey struct {
+	// ID is the RFC 7638 thumbprint of the public key, sent as the kid header.
+	ID     string
+	Method jwt.SigningMethod
+	Public any
+	Source string
+	// CreatedAt is unset for keys loaded from files.
+	CreatedAt   *time.Time
+	RetiredAt   *time.Time
+	VerifyUntil *time.Time
+
+	private any
+}
+
+// CanSign reports whether the key holds private material and has not been retired.
+func (k *SigningKey) CanSign() bool {
+	return k.private != nil && k.RetiredAt == nil
+}
+
+// JWK returns the public half of the key as a JSON Web Key.
+func (k *SigningKey) JWK() (JSONWebKey, error) {
+	jwk, err := publicJWK(k.Public)
+	if err != nil {
+		return JSONWebKey{}, err
+	}
+	jwk.Kid = k.ID
+	jwk.Use = "sig"
+	jwk.Alg = k.Method.Alg()
+	return jwk, nil
+}
+
+// StoredKey is a signing key as persisted by a KeyStore. Keys are PEM encoded, and the private
+// key is encrypted when a key encryption key is configured; retired keys keep no private key.
+type StoredKey struct {
+	ID          string
+	Algorithm   string
+	PublicKey   string
+	PrivateKey  string
+	CreatedAt   time.Time
+	RetiredAt   *time.Time
+	VerifyUntil *time.Time
+}
+
+// KeyStore persists rotated keys so every replica signs and verifies with the same set.
+type KeyStore interface {
+	// ListSigningKeys returns stored keys, newest first.
+	ListSigningKeys(ctx context.Context) ([]StoredKey, error)
+	// RotateSigningKeys retires the given keys and stores next as the active key. Retired keys
+	// must stay valid for verification until at least verifyUntil.
+	RotateSigningKeys(ctx context.Context, retire []StoredKey, next StoredKey, verifyUntil time.Time) error
+}
+
+// Keyring holds the active signing key and the keys still accepted for verification. The key from
+// LLAMERO_JWT_PRIVATE_KEY_PATH signs until the first rotation; rotated keys live in the KeyStore
+// and retired keys are dropped once the tokens they signed have expired.
+type Keyring struct {
+	cfg   config.JWTConfig
+	store KeyStore
+	// sealer encrypts stored private keys, which are never kept as plain PEM.
+	sealer cipher.AEAD
+	// static holds the keys loaded from files; static[0] is the configured signing key.
+	static []*SigningKey
+
+	reloadMu sync.Mutex
+	mu       sync.RWMutex
+	keys     map[string]*SigningKey
+	active   *SigningKey
+	loadedAt time.Time
+}
+
+// NewKeyring loads the configured signing key and verification-only keys from disk. A nil store
+// limits the keyring to those keys.
+func NewKeyring(cfg config.JWTConfig, store KeyStore) (*Keyring, error) {
+	signing, err := loadSigningKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	static := []*SigningKey{signing}
+	for _, path := range cfg.VerifyKeyPaths {
+		if strings.TrimSpace(path) == "" {
+			continue
+		}
+		key, loadErr := loadVerifyKey(path)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		static = append(static, key)
+	}
+
+	sealer, err := newKeySealer(cfg.KeyEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	k := &Keyring{cfg: cfg, store: store, static: static, sealer: sealer}
+	k.keys, k.active, err = k.build(nil)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Active returns the key new tokens are signed with. The key set is cached and reloaded at most
+// every keyRefreshInterval, so other replicas pick up a rotation within that interval; a failed
+// reload keeps signing with the cached key.
+func (k *Keyring) Active(ctx context.Context) (*SigningKey, error) {
+	if k.age() > keyRefreshInterval {
+		if err := k.reload(ctx); err != nil && !k.loaded() {
+			return nil, fmt.Errorf("load signing keys: %w", err)
+		}
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.active == nil {
+		return nil, errors.New("no active signing key")
+	}
+	return k.active, nil
+}
+
+// Lookup returns the key a token was signed with. Tokens without a key ID predate key IDs and
+// were signed with the configured key.
+func (k *Keyring) Lookup(ctx context.Context, kid string) (*SigningKey, error) {
+	if kid == "" {
+		kid = k.static[0].ID
+	}
+	key, ok := k.get(kid)
+	if age := k.age(); age > keyRefreshInterval || (!ok && age > unknownKeyRefreshInterval) {
+		// A failed reload keeps the cached set so verification survives a database outage.
+		if err := k.reload(ctx); err == nil {
+			key, ok = k.get(kid)
+		}
+	}
+	if !ok || expired(key, time.Now()) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSigningKey, kid)
+	}
+	return key, nil
+}
+
+// Keys returns every key still accepted for verification, the active key first.
+func (k *Keyring) Keys(ctx context.Context) ([]*SigningKey, error) {
+	if k.age() > keyRefreshInterval {
+		if err := k.reload(ctx); err != nil {
+			return nil, fmt.Errorf("load signing keys: %w", err)
+		}
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := time.Now()
+	out := make([]*SigningKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		if !expired(key, now) {
+			out = append(out, key)
+		}
+	}
+	slices.SortFunc(out, func(a, b *SigningKey) int {
+		switch {
+		case a == k.active:
+			return -1
+		case b == k.active:
+			return 1
+		default:
+			return createdAt(b).Compare(createdAt(a))
+		}
+	})
+	return out, nil
+}
+
+// Rotate generates a new signing key with the configured method and retires the current ones.
+// Retired keys keep verifying until every token they signed has expired.
+func (k *Keyring) Rotate(ctx context.Context) (*SigningKey, error) {
+	if k.store == nil {
+		return nil, errors.New("key rotation requires a key store")
+	}
+	if err := k.reload(ctx); err != nil {
+		return nil, fmt.Errorf("load signing keys: %w", err)
+	}
+
+	next, err := generateKey(k.static[0].Method)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	var retire []StoredKey
+	for _, key := range k.keys {
+		if key.CanSign() {
+			stored, encodeErr := encodeKey(key, false)
+			if encodeErr != nil {
+				k.mu.RUnlock()
+				return nil, encodeErr
+			}
+			retire = append(retire, stored)
+		}
+	}
+	k.mu.RUnlock()
+
+	stored, err := encodeKey(next, true)
+	if err != nil {
+		return nil, err
+	}
+	if stored.PrivateKey, err = k.seal(stored.ID, stored.PrivateKey); err != nil {
+		return nil, err
+	}
+	// Replicas that have not reloaded yet keep signing with the retired key for up to one refresh
+	// interval, so its tokens must verify that much longer.
+	verifyUntil := time.Now().Add(k.cfg.TTL + keyRefreshInterval)
+	if err = k.store.RotateSigningKeys(ctx, retire, stored, verifyUntil); err != nil {
+		return nil, err
+	}
+	if err = k.reload(ctx); err != nil {
+		return nil, fmt.Errorf("load signing keys: %w", err)
+	}
+	return k.Active(ctx)
+}
+
+func (k *Keyring) get(kid string) (*SigningKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// loaded reports whether the key set has been read from the store at least once.
+func (k *Keyring) loaded() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return !k.loadedAt.IsZero()
+}
+
+func (k *Keyring) age() time.Duration {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return time.Since(k.loadedAt)
+}
+
+func (k *Keyring) reload(ctx context.Context) error {
+	if k.store == nil {
+		return nil
+	}
+	k.reloadMu.Lock()
+	defer k.reloadMu.Unlock()
+
+	stored, err := k.store.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	keys, active, err := k.build(stored)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys, k.active, k.loadedAt = keys, active, time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// build merges the file keys with stored keys. A stored row for a file key records its
+// retirement; the newest stored key that can sign becomes active, falling back to the configured
+// key until the first rotation.
+func (k *Keyring) build(stored []StoredKey) (map[string]*SigningKey, *SigningKey, error) {
+	keys := make(map[string]*SigningKey, len(k.static)+len(stored))
+	for _, key := range k.static {
+		clone := *key
+		keys[key.ID] = &clone
+	}
+
+	var active *SigningKey
+	for _, row := range stored {
+		if existing, ok := keys[row.ID]; ok && existing.Source == KeySourceConfig {
+			existing.RetiredAt = row.RetiredAt
+			existing.VerifyUntil = row.VerifyUntil
+			continue
+		}
+		private, err := k.open(row.ID, row.PrivateKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decrypt signing key %s: %w", row.ID, err)
+		}
+		row.PrivateKey = private
+		key, err := decodeKey(row)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode signing key %s: %w", row.ID, err)
+		}
+		keys[key.ID] = key
+		if active == nil && key.CanSign() {
+			active = key
+		}
+	}
+	if configured := keys[k.static[0].ID]; active == nil && configured.CanSign() {
+		active = configured
+	}
+	return keys, active, nil
+}
+
+func expired(key *SigningKey, now time.Time) bool {
+	return key.VerifyUntil != nil && now.After(*key.VerifyUntil)
+}
+
+func createdAt(key *SigningKey) time.Time {
+	if key.CreatedAt == nil {
+		return time.Time{}
+	}
+	return *key.CreatedAt
+}
+
+func loadSigningKey(cfg config.JWTConfig) (*SigningKey, error) {
+	method, err := signingMethod(cfg.SigningMethod)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	private, err := parsePrivateKey(raw, method)
+	if err != nil {
+		return nil, err
+	}
+	public, err := publicKeyOf(private)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cfg.PublicKeyPath) != "" {
+		if public, err = loadPublicKey(cfg.PublicKeyPath, method); err != nil {
+			return nil, err
+		}
+	}
+	return newSigningKey(method, public, private, KeySourceConfig)
+}
+
+func loadVerifyKey(path string) (*SigningKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read verification key: %w", err)
+	}
+	public, method, err := parsePublicKeyPEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse verification key %s: %w", path, err)
+	}
+	return newSigningKey(method, public, nil, KeySourceConfig)
+}
+
+func loadPublicKey(path string, method jwt.SigningMethod) (any, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	public, keyMethod, err := parsePublicKeyPEM(raw)
+	if err != nil {
+		return nil, err
+	}
+	if keyMethod != method {
+		return nil, fmt.Errorf("public key does not match signing method %s", method.Alg())
+	}
+	return public, nil
+}
+
+func newSigningKey(method jwt.SigningMethod, public, private any, source string) (*SigningKey, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{
+		ID:      jwk.Thumbprint(),
+		Method:  method,
+		Public:  public,
+		Source:  source,
+		private: private,
+	}, nil
+}
+
+func generateKey(method jwt.SigningMethod) (*SigningKey, error) {
+	var (
+		public, private any
+		err             error
+	)
+	switch method {
+	case jwt.SigningMethodEdDSA:
+		public, private, err = ed25519.GenerateKey(rand.Reader)
+	case jwt.SigningMethodRS256:
+		var key *rsa.PrivateKey
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err == nil {
+			public, private = &key.PublicKey, key
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing method %s", method.Alg())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate signing key: %w", err)
+	}
+	key, err := newSigningKey(method, public, private, KeySourceDatabase)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	key.CreatedAt = &now
+	return key, nil
+}
+
+func encodeKey(key *SigningKey, withPrivate bool) (StoredKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return StoredKey{}, fmt.Errorf("encode public key: %w", err)
+	}
+	stored := StoredKey{
+		ID:        key.ID,
+		Algorithm: key.Method.Alg(),
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: pemPublicKeyType, Bytes: der})),
+	}
+	if withPrivate {
+		if der, err = x509.MarshalPKCS8PrivateKey(key.private); err != nil {
+			return StoredKey{}, fmt.Errorf("encode private key: %w", err)
+		}
+		stored.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: pemPrivateKeyType, Bytes: der}))
+	}
+	return stored, nil
+}
+
+func decodeKey(row StoredKey) (*SigningKey, error) {
+	method, err := signingMethod(row.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	public, keyMethod, err := parsePublicKeyPEM([]byte(row.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	if keyMethod != method {
+		return nil, fmt.Errorf("public key does not match signing method %s", method.Alg())
+	}
+	var private any
+	if row.PrivateKey != "" && row.RetiredAt == nil {
+		if private, err = parsePrivateKey([]byte(row.PrivateKey), method); err != nil {
+			return nil, err
+		}
+	}
+	createdAt := row.CreatedAt
+	return &SigningKey{
+		ID:          row.ID,
+		Method:      method,
+		Public:      public,
+		Source:      KeySourceDatabase,
+		CreatedAt:   &createdAt,
+		RetiredAt:   row.RetiredAt,
+		VerifyUntil: row.VerifyUntil,
+		private:     private,
+	}, nil
+}
+
+// newKeySealer returns the cipher for the base64-encoded key encryption key. The key is required:
+// without it rotated private keys would sit in Postgres as plain PEM, and anyone who can read the
+// database or a backup could mint tokens.
+func newKeySealer(encoded string) (cipher.AEAD, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, errors.New("a key encryption key is required to store signing keys")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode key encryption key: %w", err)
+	}
+	if len(key) != keyEncryptionKeyLen {
+		return nil, fmt.Errorf("key encryption key must be %d bytes, got %d", keyEncryptionKeyLen, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts a PEM private key with the key encryption key, binding it to its key ID.
+func (k *Keyring) seal(kid, private string) (string, error) {
+	if private == "" {
+		return private, nil
+	}
+	nonce := make([]byte, k.sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := k.sealer.Seal(nonce, nonce, []byte(private), []byte(kid))
+	return sealedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open reverses seal. Stored private keys that are not encrypted are rejected.
+func (k *Keyring) open(kid, stored string) (string, error) {
+	if stored == "" {
+		return stored, nil
+	}
+	encoded, ok := strings.CutPrefix(stored, sealedKeyPrefix)
+	if !ok {
+		return "", errors.New("stored private key is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	size := k.sealer.NonceSize()
+	if len(sealed) < size {
+		return "", errors.New("encrypted private key is truncated")
+	}
+	private, err := k.sealer.Open(nil, sealed[:size], sealed[size:], []byte(kid))
+	if err != nil {
+		return "", err
+	}
+	return string(private), nil
+}
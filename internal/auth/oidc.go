@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/rhajizada/llamero/internal/config"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// maxProviderDocumentBytes bounds discovery documents and key sets fetched from the provider.
+	maxProviderDocumentBytes = 1 << 20
+	// jwksRefreshInterval rate-limits key set refreshes triggered by unknown key IDs.
+	jwksRefreshInterval = time.Minute
+	idTokenLeeway       = 30 * time.Second
+	// defaultIDTokenAlg is the algorithm OpenID Connect mandates when a provider lists none.
+	defaultIDTokenAlg = "RS256"
+)
+
+// ErrInvalidIDToken reports an ID token that failed verification.
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// Endpoints lists the provider URLs used during login.
+type Endpoints struct {
+	Issuer        string
+	Authorization string
+	Token         string
+	UserInfo      string
+	JWKS          string
+	// SigningAlgs lists the algorithms the provider signs ID tokens with.
+	SigningAlgs []string
+}
+
+// OIDCProvider resolves provider endpoints, through OpenID Connect discovery when an issuer is
+// configured, and verifies the ID tokens the provider returns.
+type OIDCProvider struct {
+	cfg    config.OAuthConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	endpoints *Endpoints
+
+	keysMu        sync.Mutex
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserInfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	IDTokenSigningAlgs    []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// NewOIDCProvider builds a provider. Discovery runs on first use and is retried until it succeeds.
+func NewOIDCProvider(cfg config.OAuthConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &OIDCProvider{cfg: cfg, client: client}
+}
+
+// Endpoints returns the provider endpoints. Explicitly configured URLs override discovered ones.
+func (p *OIDCProvider) Endpoints(ctx context.Context) (Endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.endpoints != nil {
+		return *p.endpoints, nil
+	}
+
+	endpoints := Endpoints{}
+	if issuer := strings.TrimSpace(p.cfg.IssuerURL); issuer != "" {
+		var doc discoveryDocument
+		url := strings.TrimRight(issuer, "/") + discoveryPath
+		if err := getProviderJSON(ctx, p.client, url, &doc); err != nil {
+			return Endpoints{}, fmt.Errorf("discover provider: %w", err)
+		}
+		if strings.TrimRight(doc.Issuer, "/") != strings.TrimRight(issuer, "/") {
+			return Endpoints{}, fmt.Errorf("discovery document issuer %q does not match %q", doc.Issuer, issuer)
+		}
+		endpoints = Endpoints{
+			Issuer:        doc.Issuer,
+			Authorization: doc.AuthorizationEndpoint,
+			Token:         doc.TokenEndpoint,
+			UserInfo:      doc.UserInfoEndpoint,
+			JWKS:          doc.JWKSURI,
+			SigningAlgs:   doc.IDTokenSigningAlgs,
+		}
+	}
+	endpoints.Authorization = firstNonEmpty(p.cfg.AuthorizeURL, endpoints.Authorization)
+	endpoints.Token = firstNonEmpty(p.cfg.TokenURL, endpoints.Token)
+	endpoints.UserInfo = firstNonEmpty(p.cfg.UserInfoURL, endpoints.UserInfo)
+	endpoints.JWKS = firstNonEmpty(p.cfg.JWKSURL, endpoints.JWKS)
+	if len(endpoints.SigningAlgs) == 0 {
+		endpoints.SigningAlgs = []string{defaultIDTokenAlg}
+	}
+	if endpoints.Authorization == "" || endpoints.Token == "" {
+		return Endpoints{}, errors.New("provider authorization and token endpoints are unknown")
+	}
+
+	p.endpoints = &endpoints
+	return endpoints, nil
+}
+
+// VerifiesIDTokens reports whether the provider publishes signing keys and asks for an ID token.
+// ID tokens are required and verified in that case; otherwise identity comes from userinfo.
+func (p *OIDCProvider) VerifiesIDTokens(endpoints Endpoints) bool {
+	return endpoints.JWKS != "" && slices.Contains(p.cfg.Scopes, "openid")
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token and
+// returns its claims. Failures wrap ErrInvalidIDToken.
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, raw, nonce string) (jwt.MapClaims, error) {
+	endpoints, err := p.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if endpoints.JWKS == "" {
+		return nil, errors.New("provider does not publish signing keys")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+		jwt.WithValidMethods(endpoints.SigningAlgs),
+	}
+	if endpoints.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(endpoints.Issuer))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		return p.signingKey(ctx, endpoints.JWKS, token)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if sub, _ := claims.GetSubject(); sub == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	// With several audiences the token must name this client as its authorized party.
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("%w: authorized party %q does not match client", ErrInvalidIDToken, azp)
+		}
+	}
+	return claims, nil
+}
+
+// signingKey looks up the key a token was signed with, refreshing the key set when the key ID is
+// unknown so provider key rotation is picked up.
+func (p *OIDCProvider) signingKey(ctx context.Context, jwksURL string, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	if key, ok := lookupKey(p.keys, kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := fetchJWKS(ctx, p.client, jwksURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch signing keys: %w", err)
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	if key, ok := lookupKey(p.keys, kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a key by ID. Tokens without a key ID match a key set holding a single key.
+func lookupKey(keys map[string]any, kid string) (any, bool) {
+	if key, ok := keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func getProviderJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxProviderDocumentBytes)).Decode(out)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const refreshTokenBytes = 32
+
+// NewRefreshToken returns an opaque session refresh token. Only its hash is stored.
+func NewRefreshToken() string {
+	buf := make([]byte, refreshTokenBytes)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// HashRefreshToken returns the hash a refresh token is stored and looked up by.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ScopeAll grants every scope.
+	ScopeAll = "*"
+
+	scopeSeparator   = ":"
+	scopeWildcard    = scopeSeparator + "*"
+	constraintSymbol = "="
+	globWildcard     = "*"
+)
+
+// Resource keys a scope can be narrowed to, as in "llm:chat:model=llama3*" or
+// "backends:pullModel:tag=gpu".
+const (
+	ResourceModel   = "model"
+	ResourceBackend = "backend"
+	ResourceTag     = "tag"
+)
+
+// KnownScopes lists every scope a route can require. Roles, tokens and service accounts may only
+// grant these, or wildcards that cover at least one of them.
+func KnownScopes() []string {
+	return []string{
+		"backends:list",
+		"backends:listModels",
+		"backends:ps",
+		"backends:createModel",
+		"backends:pullModel",
+		"backends:pushModel",
+		"backends:deleteModel",
+		"keys:list",
+		"keys:rotate",
+		"users:list",
+		"users:manage",
+		"service-accounts:list",
+		"service-accounts:manage",
+		"models:list",
+		"llm:chat",
+		"llm:embeddings",
+		"llm:batch",
+		"profile:get",
+	}
+}
+
+// resourceKeys lists the resource keys a known scope can be narrowed by.
+func resourceKeys(scope string) []string {
+	switch {
+	case scope == "models:list", scope == "llm:chat", scope == "llm:embeddings":
+		return []string{ResourceModel}
+	case strings.HasPrefix(scope, "backends"+scopeSeparator):
+		return []string{ResourceBackend, ResourceTag}
+	default:
+		return nil
+	}
+}
+
+// Resource is the concrete model or backend a request acts on.
+type Resource struct {
+	Model   string
+	Backend string
+	Tags    []string
+}
+
+// Scope is a parsed scope: a name such as "llm:chat" or "llm:*", optionally narrowed to the
+// resources whose Key matches the glob Pattern.
+type Scope struct {
+	Name    string
+	Key     string
+	Pattern string
+}
+
+// ParseScope splits a scope into its name and resource constraint. The constraint is the last
+// segment when it contains "="; everything after "=" is the pattern, so model names with colons
+// such as "llm:chat:model=llama3.1:8b" parse as expected.
+func ParseScope(raw string) Scope {
+	eq := strings.Index(raw, constraintSymbol)
+	if eq < 0 {
+		return Scope{Name: raw}
+	}
+	head := raw[:eq]
+	sep := strings.LastIndex(head, scopeSeparator)
+	if sep < 0 {
+		return Scope{Name: raw}
+	}
+	return Scope{Name: head[:sep], Key: head[sep+1:], Pattern: raw[eq+1:]}
+}
+
+// allows reports whether the scope's constraint admits a resource. Unconstrained scopes admit
+// everything.
+func (s Scope) allows(res Resource) bool {
+	switch s.Key {
+	case "":
+		return true
+	case ResourceModel:
+		return res.Model != "" && globMatch(s.Pattern, res.Model)
+	case ResourceBackend:
+		return res.Backend != "" && globMatch(s.Pattern, res.Backend)
+	case ResourceTag:
+		return slices.ContainsFunc(res.Tags, func(tag string) bool {
+			return globMatch(s.Pattern, tag)
+		})
+	default:
+		return false
+	}
+}
+
+// ScopeCovers reports whether a granted scope includes another scope. "*" includes everything, and
+// a scope ending in ":*" includes every scope below its prefix, so "llm:*" covers "llm:chat" and
+// "backends:*" covers "backends:pullModel". Wildcards are compared the same way, so "*" covers
+// "llm:*" but "llm:chat" does not. A constrained grant only covers scopes narrowed at least as far,
+// so "llm:chat:model=llama3*" covers "llm:chat:model=llama3.1:8b" but not "llm:chat".
+func ScopeCovers(granted, scope string) bool {
+	g, s := ParseScope(granted), ParseScope(scope)
+	if !nameCovers(g.Name, s.Name) {
+		return false
+	}
+	if g.Key == "" {
+		return true
+	}
+	return g.Key == s.Key && patternCovers(g.Pattern, s.Pattern)
+}
+
+// HasScope reports whether any granted scope covers scope.
+func HasScope(granted []string, scope string) bool {
+	return slices.ContainsFunc(granted, func(g string) bool {
+		return ScopeCovers(g, scope)
+	})
+}
+
+// HasScopes reports whether the granted scopes cover every required scope.
+func HasScopes(granted, required []string) bool {
+	for _, scope := range required {
+		if !HasScope(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// GrantsScope reports whether any granted scope reaches scope for at least some resources. It
+// gates routes; the handler then checks the concrete resource with PermitsResource. A constrained
+// grant only reaches scopes that can be narrowed by its key, so "*:model=llama3*" reaches
+// "llm:chat" but not "users:manage".
+func GrantsScope(granted []string, scope string) bool {
+	return slices.ContainsFunc(granted, func(g string) bool {
+		return ParseScope(g).reaches(scope)
+	})
+}
+
+// PermitsResource reports whether any granted scope allows scope on the resource.
+func PermitsResource(granted []string, scope string, res Resource) bool {
+	return slices.ContainsFunc(granted, func(g string) bool {
+		parsed := ParseScope(g)
+		return parsed.reaches(scope) && parsed.allows(res)
+	})
+}
+
+// reaches reports whether the scope's name covers scope and its constraint, if any, applies to it.
+func (s Scope) reaches(scope string) bool {
+	if !nameCovers(s.Name, scope) {
+		return false
+	}
+	return s.Key == "" || slices.Contains(resourceKeys(scope), s.Key)
+}
+
+// ValidateScope rejects scopes that are neither known nor a wildcard covering a known scope, and
+// constraints on resources the scope does not act on, so typos fail loudly instead of granting
+// nothing.
+func ValidateScope(raw string) error {
+	scope := ParseScope(raw)
+	if scope.Name != ScopeAll && strings.Contains(strings.TrimSuffix(scope.Name, scopeWildcard), "*") {
+		return fmt.Errorf("scope %q may only end in a %q wildcard", raw, scopeWildcard)
+	}
+	if scope.Key != "" && strings.TrimSpace(scope.Pattern) == "" {
+		return fmt.Errorf("scope %q has an empty %s pattern", raw, scope.Key)
+	}
+	for _, known := range KnownScopes() {
+		if !nameCovers(scope.Name, known) {
+			continue
+		}
+		if scope.Key == "" || slices.Contains(resourceKeys(known), scope.Key) {
+			return nil
+		}
+	}
+	if scope.Key != "" {
+		return fmt.Errorf("scope %q cannot be narrowed by %s", raw, scope.Key)
+	}
+	return fmt.Errorf("unknown scope %q", raw)
+}
+
+func nameCovers(granted, name string) bool {
+	if granted == name || granted == ScopeAll {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(granted, "*")
+	if !ok || !strings.HasSuffix(prefix, scopeSeparator) {
+		return false
+	}
+	return strings.HasPrefix(name, prefix) && len(name) > len(prefix)
+}
+
+// patternCovers reports whether every value matching requested also matches granted. Only
+// literal patterns and a trailing wildcard are compared exactly; anything else must be equal.
+func patternCovers(granted, requested string) bool {
+	if granted == requested {
+		return true
+	}
+	if !strings.Contains(requested, globWildcard) {
+		return globMatch(granted, requested)
+	}
+	prefix, ok := strings.CutSuffix(granted, globWildcard)
+	return ok && !strings.Contains(prefix, globWildcard) && strings.HasPrefix(requested, prefix)
+}
+
+// globMatch matches value against a pattern in which "*" stands for any run of characters.
+func globMatch(pattern, value string) bool {
+	parts := strings.Split(pattern, globWildcard)
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return strings.HasSuffix(value, parts[last])
+}
@@ -0,0 +1,174 @@
+package auth
+
+import "testing"
+
+func TestScopeCovers(t *testing.T) {
+	tests := []struct {
+		granted string
+		scope   string
+		want    bool
+	}{
+		{"llm:chat", "llm:chat", true},
+		{"llm:chat", "llm:embeddings", false},
+		{ScopeAll, "users:manage", true},
+		{ScopeAll, "llm:*", true},
+		{"llm:*", "llm:chat", true},
+		{"llm:*", "llm:*", true},
+		{"llm:*", "llm:", false},
+		{"llm:*", "llmx:chat", false},
+		{"llm:*", "users:manage", false},
+		{"llm:chat", "llm:*", false},
+		{"backends:*", "backends:pullModel", true},
+		{"llm*", "llm:chat", false},
+		{"llm:chat:model=llama3*", "llm:chat:model=llama3.1:8b", true},
+		{"llm:chat:model=llama3*", "llm:chat:model=llama3.1*", true},
+		{"llm:chat:model=llama3*", "llm:chat", false},
+		{"llm:chat:model=llama3*", "llm:chat:model=mistral", false},
+		{"llm:chat:model=llama3*", "llm:chat:backend=gpu", false},
+		{"llm:*", "llm:chat:model=mistral", true},
+		{"backends:*:tag=gpu", "backends:pullModel:tag=gpu", true},
+		{"backends:*:tag=gpu", "backends:pullModel:tag=cpu", false},
+	}
+	for _, tt := range tests {
+		if got := ScopeCovers(tt.granted, tt.scope); got != tt.want {
+			t.Errorf("ScopeCovers(%q, %q) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestHasScopes(t *testing.T) {
+	granted := []string{"llm:*", "models:list"}
+	if !HasScopes(granted, []string{"llm:chat", "llm:embeddings", "models:list"}) {
+		t.Fatal("HasScopes() = false, want true for covered scopes")
+	}
+	if HasScopes(granted, []string{"llm:chat", "users:manage"}) {
+		t.Fatal("HasScopes() = true, want false when one scope is not covered")
+	}
+	if !HasScopes([]string{ScopeAll}, []string{"users:manage", "keys:rotate"}) {
+		t.Fatal("HasScopes() = false, want true for the admin scope")
+	}
+	if HasScopes(nil, []string{"profile:get"}) {
+		t.Fatal("HasScopes() = true, want false without grants")
+	}
+}
+
+func TestGrantsScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{"exact", []string{"llm:chat"}, "llm:chat", true},
+		{"admin", []string{ScopeAll}, "users:manage", true},
+		{"prefix wildcard", []string{"llm:*"}, "llm:chat", true},
+		{"other prefix", []string{"llm:*"}, "users:manage", false},
+		{"missing", []string{"llm:embeddings"}, "llm:chat", false},
+		{"constrained", []string{"llm:chat:model=llama3*"}, "llm:chat", true},
+		{"constrained wildcard on model route", []string{"*:model=llama3*"}, "llm:chat", true},
+		{"constrained wildcard on users route", []string{"*:model=llama3*"}, "users:manage", false},
+		{"constrained wildcard on keys route", []string{"*:model=llama3*"}, "keys:rotate", false},
+		{"constrained wildcard on batch route", []string{"llm:*:model=llama3*"}, "llm:batch", false},
+		{"backend tag", []string{"backends:*:tag=gpu"}, "backends:pullModel", true},
+		{"backend tag on models route", []string{"*:tag=gpu"}, "models:list", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GrantsScope(tt.granted, tt.scope); got != tt.want {
+				t.Fatalf("GrantsScope(%q, %q) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermitsResource(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		scope   string
+		res     Resource
+		want    bool
+	}{
+		{"admin", []string{ScopeAll}, "llm:chat", Resource{Model: "mistral"}, true},
+		{"unconstrained", []string{"llm:chat"}, "llm:chat", Resource{Model: "mistral"}, true},
+		{"model match", []string{"llm:chat:model=llama3*"}, "llm:chat", Resource{Model: "llama3.1:8b"}, true},
+		{"model mismatch", []string{"llm:chat:model=llama3*"}, "llm:chat", Resource{Model: "mistral"}, false},
+		{"model missing", []string{"llm:chat:model=llama3*"}, "llm:chat", Resource{}, false},
+		{"wrong scope", []string{"llm:chat:model=llama3*"}, "llm:embeddings", Resource{Model: "llama3"}, false},
+		{"wildcard on model route", []string{"*:model=llama3*"}, "llm:chat", Resource{Model: "llama3"}, true},
+		{"wildcard on users route", []string{"*:model=llama3*"}, "users:manage", Resource{Model: "llama3"}, false},
+		{"backend match", []string{"backends:*:backend=gpu-*"}, "backends:ps", Resource{Backend: "gpu-1"}, true},
+		{"backend mismatch", []string{"backends:*:backend=gpu-*"}, "backends:ps", Resource{Backend: "cpu-1"}, false},
+		{"tag match", []string{"backends:pullModel:tag=gpu"}, "backends:pullModel", Resource{Tags: []string{"fast", "gpu"}}, true},
+		{"tag mismatch", []string{"backends:pullModel:tag=gpu"}, "backends:pullModel", Resource{Tags: []string{"cpu"}}, false},
+		{"any grant", []string{"llm:chat:model=mistral", "llm:chat:model=llama3*"}, "llm:chat", Resource{Model: "llama3"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PermitsResource(tt.granted, tt.scope, tt.res); got != tt.want {
+				t.Fatalf("PermitsResource(%q, %q, %+v) = %v, want %v", tt.granted, tt.scope, tt.res, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	valid := []string{
+		ScopeAll,
+		"llm:chat",
+		"llm:*",
+		"backends:*",
+		"service-accounts:manage",
+		"llm:chat:model=llama3.1:8b",
+		"*:model=llama3*",
+		"backends:pullModel:tag=gpu",
+		"backends:*:backend=gpu-*",
+	}
+	for _, raw := range valid {
+		if err := ValidateScope(raw); err != nil {
+			t.Errorf("ValidateScope(%q) = %v, want nil", raw, err)
+		}
+	}
+
+	invalid := []string{
+		"llm:chats",
+		"nope:*",
+		"ll*:chat",
+		"llm:chat:model=",
+		"users:manage:model=llama3",
+		"llm:chat:backend=gpu",
+		"llm:batch:model=llama3",
+		"llm:chat:colour=red",
+		"llm:*:*",
+		"llm",
+		"",
+	}
+	for _, raw := range invalid {
+		if err := ValidateScope(raw); err == nil {
+			t.Errorf("ValidateScope(%q) = nil, want error", raw)
+		}
+	}
+}
+
+func TestPatternCovers(t *testing.T) {
+	tests := []struct {
+		granted   string
+		requested string
+		want      bool
+	}{
+		{"llama3", "llama3", true},
+		{"llama3*", "llama3.1:8b", true},
+		{"llama3*", "llama3.1*", true},
+		{"llama3*", "llama*", false},
+		{"llama3*", "mistral", false},
+		{"*", "anything*", true},
+		{"llama3", "llama3*", false},
+		{"*3*", "llama3*", false},
+		{"*3*", "llama3.1", true},
+	}
+	for _, tt := range tests {
+		if got := patternCovers(tt.granted, tt.requested); got != tt.want {
+			t.Errorf("patternCovers(%q, %q) = %v, want %v", tt.granted, tt.requested, got, tt.want)
+		}
+	}
+}
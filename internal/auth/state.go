@@ -1,64 +1,107 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// StateStore tracks OAuth state parameters to prevent CSRF.
-type StateStore struct {
+// codeVerifierBytes yields a 43-character PKCE code verifier, the shortest RFC 7636 allows.
+const codeVerifierBytes = 32
+
+// LoginState is the data bound to an OAuth state parameter for the duration of a login.
+type LoginState struct {
+	// Nonce is sent in the authorization request and must come back in the ID token.
+	Nonce string `json:"nonce"`
+	// CodeVerifier is the PKCE secret sent with the code exchange.
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// CodeChallenge returns the S256 PKCE challenge for the code verifier.
+func (l LoginState) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(l.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// StateStore tracks OAuth state parameters to prevent CSRF. A state can be consumed once, and only
+// before it expires.
+type StateStore interface {
+	// Issue creates a new opaque state token and the login state bound to it.
+	Issue(ctx context.Context) (string, LoginState, error)
+	// Consume validates and removes the supplied state token, returning the login state bound to it.
+	Consume(ctx context.Context, token string) (LoginState, bool, error)
+}
+
+func newLoginState() (string, LoginState) {
+	verifier := make([]byte, codeVerifierBytes)
+	_, _ = rand.Read(verifier)
+	return uuid.NewString(), LoginState{
+		Nonce:        rand.Text(),
+		CodeVerifier: base64.RawURLEncoding.EncodeToString(verifier),
+	}
+}
+
+// MemoryStateStore keeps states in process memory. It only suits a single server replica.
+type MemoryStateStore struct {
 	ttl   time.Duration
 	mu    sync.Mutex
-	state map[string]time.Time
+	state map[string]stateEntry
 }
 
-// NewStateStore builds an in-memory store.
-func NewStateStore(ttl time.Duration) *StateStore {
-	return &StateStore{
+type stateEntry struct {
+	login  LoginState
+	expiry time.Time
+}
+
+// NewMemoryStateStore builds an in-memory store.
+func NewMemoryStateStore(ttl time.Duration) *MemoryStateStore {
+	return &MemoryStateStore{
 		ttl:   ttl,
-		state: make(map[string]time.Time),
+		state: make(map[string]stateEntry),
 	}
 }
 
-// Issue creates a new opaque state token.
-func (s *StateStore) Issue() string {
+// Issue implements StateStore.
+func (s *MemoryStateStore) Issue(context.Context) (string, LoginState, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.cleanupLocked()
 
-	token := uuid.NewString()
-	s.state[token] = time.Now().Add(s.ttl)
-	return token
+	token, login := newLoginState()
+	s.state[token] = stateEntry{login: login, expiry: time.Now().Add(s.ttl)}
+	return token, login, nil
 }
 
-// Consume validates and removes the supplied state token.
-func (s *StateStore) Consume(token string) bool {
+// Consume implements StateStore.
+func (s *MemoryStateStore) Consume(_ context.Context, token string) (LoginState, bool, error) {
 	if token == "" {
-		return false
+		return LoginState{}, false, nil
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.cleanupLocked()
 
-	expiry, ok := s.state[token]
+	entry, ok := s.state[token]
 	if !ok {
-		return false
-	}
-	if time.Now().After(expiry) {
-		delete(s.state, token)
-		return false
+		return LoginState{}, false, nil
 	}
 	delete(s.state, token)
-	return true
+	if time.Now().After(entry.expiry) {
+		return LoginState{}, false, nil
+	}
+	return entry.login, true, nil
 }
 
-func (s *StateStore) cleanupLocked() {
+func (s *MemoryStateStore) cleanupLocked() {
 	now := time.Now()
-	for token, expiry := range s.state {
-		if now.After(expiry) {
+	for token, entry := range s.state {
+		if now.After(entry.expiry) {
 			delete(s.state, token)
 		}
 	}
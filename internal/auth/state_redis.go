@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const oauthStateKey = "oauth:state:"
+
+// RedisStateStore keeps states in Redis so a login can start and finish on different server
+// replicas. Redis expires states after the TTL, and GETDEL makes consuming atomic.
+type RedisStateStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStateStore builds a store on an existing Redis connection.
+func NewRedisStateStore(client *redis.Client, ttl time.Duration) *RedisStateStore {
+	return &RedisStateStore{client: client, ttl: ttl}
+}
+
+// Issue implements StateStore.
+func (s *RedisStateStore) Issue(ctx context.Context) (string, LoginState, error) {
+	token, login := newLoginState()
+	data, err := json.Marshal(login)
+	if err != nil {
+		return "", LoginState{}, err
+	}
+	if err = s.client.Set(ctx, oauthStateKey+token, data, s.ttl).Err(); err != nil {
+		return "", LoginState{}, err
+	}
+	return token, login, nil
+}
+
+// Consume implements StateStore.
+func (s *RedisStateStore) Consume(ctx context.Context, token string) (LoginState, bool, error) {
+	if token == "" {
+		return LoginState{}, false, nil
+	}
+	raw, err := s.client.GetDel(ctx, oauthStateKey+token).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return LoginState{}, false, nil
+	}
+	if err != nil {
+		return LoginState{}, false, err
+	}
+	var login LoginState
+	if err = json.Unmarshal(raw, &login); err != nil {
+		return LoginState{}, false, err
+	}
+	return login, true, nil
+}
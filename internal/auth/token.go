@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
@@ -8,7 +9,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 	"time"
 
@@ -18,39 +18,36 @@ import (
 	"github.com/rhajizada/llamero/internal/config"
 )
 
-// TokenIssuer signs internal Llamero JWTs.
+// TokenIssuer signs internal Llamero JWTs with the active key of a keyring.
 type TokenIssuer struct {
-	cfg        config.JWTConfig
-	method     jwt.SigningMethod
-	privateKey any
+	cfg  config.JWTConfig
+	keys *Keyring
 }
 
-// NewTokenIssuer loads the signing key from disk and prepares the issuer.
-func NewTokenIssuer(cfg config.JWTConfig) (*TokenIssuer, error) {
-	method, err := signingMethod(cfg.SigningMethod)
-	if err != nil {
-		return nil, err
-	}
-
-	keyBytes, err := os.ReadFile(cfg.PrivateKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("read private key: %w", err)
-	}
-
-	key, err := parsePrivateKey(keyBytes, method)
-	if err != nil {
-		return nil, err
+// NewTokenIssuer prepares an issuer that signs with the keyring's active key.
+func NewTokenIssuer(cfg config.JWTConfig, keys *Keyring) (*TokenIssuer, error) {
+	if keys == nil {
+		return nil, errors.New("keyring is required")
 	}
-
 	return &TokenIssuer{
-		cfg:        cfg,
-		method:     method,
-		privateKey: key,
+		cfg:  cfg,
+		keys: keys,
 	}, nil
 }
 
-// Issue signs a standard session JWT with the supplied identity metadata.
-func (i *TokenIssuer) Issue(userID uuid.UUID, externalSub, email, role string, scopes []string) (string, error) {
+// Issue signs a standard session JWT with the supplied identity metadata. The token is bound to
+// the login session and carries the caller-provided identifier.
+func (i *TokenIssuer) Issue(
+	ctx context.Context,
+	userID uuid.UUID,
+	externalSub, email, role string,
+	scopes []string,
+	sessionID uuid.UUID,
+	jti string,
+) (string, error) {
+	if sessionID == uuid.Nil {
+		return "", errors.New("session id cannot be empty")
+	}
 	payload := issuePayload{
 		UserID:      userID,
 		ExternalSub: externalSub,
@@ -58,34 +55,38 @@ func (i *TokenIssuer) Issue(userID uuid.UUID, externalSub, email, role string, s
 		Role:        role,
 		Scopes:      scopes,
 		TokenType:   TokenTypeSession,
-		JTI:         uuid.NewString(),
+		JTI:         jti,
+		SessionID:   sessionID,
 		ExpiresAt:   time.Now().Add(i.cfg.TTL),
 	}
-	return i.issue(payload)
+	return i.issue(ctx, payload)
 }
 
 // IssuePAT signs a personal access token using caller-provided expiry and identifier.
 func (i *TokenIssuer) IssuePAT(
+	ctx context.Context,
 	userID uuid.UUID,
 	externalSub, email, role string,
 	scopes []string,
 	jti string,
 	expiresAt time.Time,
+	responseCache bool,
 ) (string, error) {
 	if expiresAt.IsZero() {
 		return "", errors.New("expires_at cannot be empty")
 	}
 	payload := issuePayload{
-		UserID:      userID,
-		ExternalSub: externalSub,
-		Email:       email,
-		Role:        role,
-		Scopes:      scopes,
-		TokenType:   TokenTypePAT,
-		JTI:         jti,
-		ExpiresAt:   expiresAt,
-	}
-	return i.issue(payload)
+		UserID:        userID,
+		ExternalSub:   externalSub,
+		Email:         email,
+		Role:          role,
+		Scopes:        scopes,
+		TokenType:     TokenTypePAT,
+		JTI:           jti,
+		ExpiresAt:     expiresAt,
+		ResponseCache: responseCache,
+	}
+	return i.issue(ctx, payload)
 }
 
 func signingMethod(name string) (jwt.SigningMethod, error) {
@@ -151,17 +152,19 @@ func parseRSAPrivateKey(der []byte) (*rsa.PrivateKey, error) {
 }
 
 type issuePayload struct {
-	UserID      uuid.UUID
-	ExternalSub string
-	Email       string
-	Role        string
-	Scopes      []string
-	TokenType   string
-	JTI         string
-	ExpiresAt   time.Time
+	UserID        uuid.UUID
+	ExternalSub   string
+	Email         string
+	Role          string
+	Scopes        []string
+	TokenType     string
+	JTI           string
+	SessionID     uuid.UUID
+	ExpiresAt     time.Time
+	ResponseCache bool
 }
 
-func (i *TokenIssuer) issue(payload issuePayload) (string, error) {
+func (i *TokenIssuer) issue(ctx context.Context, payload issuePayload) (string, error) {
 	if payload.UserID == uuid.Nil {
 		return "", errors.New("user id cannot be empty")
 	}
@@ -201,9 +204,20 @@ func (i *TokenIssuer) issue(payload issuePayload) (string, error) {
 		"exp":     payload.ExpiresAt.Unix(),
 		"aud":     i.cfg.Audience,
 	}
+	if payload.SessionID != uuid.Nil {
+		claims["sid"] = payload.SessionID.String()
+	}
+	if payload.ResponseCache {
+		claims["cache"] = true
+	}
 
-	t := jwt.NewWithClaims(i.method, claims)
-	return t.SignedString(i.privateKey)
+	key, err := i.keys.Active(ctx)
+	if err != nil {
+		return "", err
+	}
+	t := jwt.NewWithClaims(key.Method, claims)
+	t.Header["kid"] = key.ID
+	return t.SignedString(key.private)
 }
 
 // LoadReader is exposed for tests.
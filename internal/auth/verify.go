@@ -8,8 +8,6 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 
@@ -18,27 +16,18 @@ import (
 
 // TokenVerifier validates JWTs issued by Llamero.
 type TokenVerifier struct {
-	cfg    config.JWTConfig
-	method jwt.SigningMethod
-	key    any
+	cfg  config.JWTConfig
+	keys *Keyring
 }
 
-// NewTokenVerifier loads the public key (or derives it from the private key) needed to verify tokens.
-func NewTokenVerifier(cfg config.JWTConfig) (*TokenVerifier, error) {
-	method, err := signingMethod(cfg.SigningMethod)
-	if err != nil {
-		return nil, err
+// NewTokenVerifier builds a verifier that accepts tokens signed by any key in the keyring.
+func NewTokenVerifier(cfg config.JWTConfig, keys *Keyring) (*TokenVerifier, error) {
+	if keys == nil {
+		return nil, errors.New("keyring is required")
 	}
-
-	key, err := loadVerificationKey(cfg, method)
-	if err != nil {
-		return nil, err
-	}
-
 	return &TokenVerifier{
-		cfg:    cfg,
-		method: method,
-		key:    key,
+		cfg:  cfg,
+		keys: keys,
 	}, nil
 }
 
@@ -53,11 +42,17 @@ func (v *TokenVerifier) Verify(ctx context.Context, tokenString string) (*Claims
 
 	claims := &Claims{}
 	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-		if token.Method.Alg() != v.method.Alg() {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.keys.Lookup(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
 		}
-		return v.key, nil
+		return key.Public, nil
 	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}),
 		jwt.WithIssuer(v.cfg.Issuer),
 		jwt.WithAudience(v.cfg.Audience),
 	)
@@ -67,73 +62,40 @@ func (v *TokenVerifier) Verify(ctx context.Context, tokenString string) (*Claims
 	return claims, nil
 }
 
-func loadVerificationKey(cfg config.JWTConfig, method jwt.SigningMethod) (any, error) {
-	if strings.TrimSpace(cfg.PublicKeyPath) != "" {
-		pub, err := os.ReadFile(cfg.PublicKeyPath)
-		if err != nil {
-			return nil, fmt.Errorf("read public key: %w", err)
-		}
-		return parsePublicKey(pub, method)
+// parsePublicKeyPEM parses a PKIX (or PKCS#1 RSA) public key and returns the signing method it
+// verifies.
+func parsePublicKeyPEM(raw []byte) (any, jwt.SigningMethod, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, nil, errors.New("invalid PEM block for public key")
 	}
 
-	// Fall back to private key if no public key was provided.
-	privBytes, err := os.ReadFile(cfg.PrivateKeyPath)
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("read private key: %w", err)
+		rsaPub, pkcs1Err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if pkcs1Err != nil {
+			return nil, nil, fmt.Errorf("parse public key: %w", err)
+		}
+		key = rsaPub
 	}
 
-	switch method {
-	case jwt.SigningMethodEdDSA:
-		privKey, parseErr := parseEd25519PrivateKey(privBytes)
-		if parseErr != nil {
-			return nil, parseErr
-		}
-		public, ok := privKey.Public().(ed25519.PublicKey)
-		if !ok {
-			return nil, errors.New("ed25519 private key missing public component")
-		}
-		return public, nil
-	case jwt.SigningMethodRS256:
-		privKey, parseErr := parseRSAPrivateKey(privBytes)
-		if parseErr != nil {
-			return nil, parseErr
-		}
-		return &privKey.PublicKey, nil
+	switch pub := key.(type) {
+	case ed25519.PublicKey:
+		return pub, jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		return pub, jwt.SigningMethodRS256, nil
 	default:
-		return nil, fmt.Errorf("unsupported signing method %s", method.Alg())
+		return nil, nil, fmt.Errorf("unsupported public key type %T", key)
 	}
 }
 
-func parsePublicKey(raw []byte, method jwt.SigningMethod) (any, error) {
-	block, _ := pem.Decode(raw)
-	if block == nil {
-		return nil, errors.New("invalid PEM block for public key")
-	}
-
-	switch method {
-	case jwt.SigningMethodEdDSA:
-		key, err := x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
-			return nil, fmt.Errorf("parse ed25519 public key: %w", err)
-		}
-		pub, ok := key.(ed25519.PublicKey)
-		if !ok {
-			return nil, errors.New("provided public key is not ed25519")
-		}
-		return pub, nil
-	case jwt.SigningMethodRS256:
-		key, parseErr := x509.ParsePKIXPublicKey(block.Bytes)
-		if parseErr == nil {
-			if rsaPub, ok := key.(*rsa.PublicKey); ok {
-				return rsaPub, nil
-			}
-		}
-		rsaPub, pkcs1Err := x509.ParsePKCS1PublicKey(block.Bytes)
-		if pkcs1Err == nil {
-			return rsaPub, nil
-		}
-		return nil, fmt.Errorf("parse rsa public key: %w", pkcs1Err)
+func publicKeyOf(private any) (any, error) {
+	switch key := private.(type) {
+	case ed25519.PrivateKey:
+		return key.Public(), nil
+	case *rsa.PrivateKey:
+		return &key.PublicKey, nil
 	default:
-		return nil, fmt.Errorf("unsupported signing method %s", method.Alg())
+		return nil, fmt.Errorf("unsupported private key type %T", private)
 	}
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -22,34 +23,84 @@ type ServerConfig struct {
 	ExternalURL string `env:"LLAMERO_SERVER_EXTERNAL_URL" envDefault:"http://localhost:8080"`
 	OAuth       OAuthConfig
 	JWT         JWTConfig
+	Sessions    SessionConfig
 	Roles       RoleMappingConfig
 	Database    DatabaseConfig
 	Store       RedisConfig
 	Backends    BackendsConfig
+	Profiles    ProfilesConfig
+	Guardrails  GuardrailsConfig
+	Structured  StructuredOutputConfig
+	Choices     ChoicesConfig
+	Embeddings  EmbeddingsConfig
+	Proxy       ProxyConfig
+	Cache       ResponseCacheConfig
+	Files       FilesConfig
+	Batches     BatchConfig
+	Jobs        JobsConfig
+	Webhooks    WebhookConfig
 }
 
-// OAuthConfig captures the OAuth2 provider integration points.
+// OAuthConfig captures the OAuth2 provider integration points. With an issuer URL the provider
+// endpoints are discovered from its OpenID configuration; explicitly set URLs take precedence.
+// Public clients authenticate with PKCE alone and need no client secret. Login states live in
+// Redis by default; the memory store only works with a single server replica.
 type OAuthConfig struct {
-	ProviderName string   `env:"LLAMERO_OAUTH_PROVIDER_NAME"          envDefault:"oauth"`
-	ClientID     string   `env:"LLAMERO_OAUTH_CLIENT_ID,notEmpty"`
-	ClientSecret string   `env:"LLAMERO_OAUTH_CLIENT_SECRET,notEmpty"`
-	AuthorizeURL string   `env:"LLAMERO_OAUTH_AUTHORIZE_URL,notEmpty"`
-	TokenURL     string   `env:"LLAMERO_OAUTH_TOKEN_URL,notEmpty"`
-	UserInfoURL  string   `env:"LLAMERO_OAUTH_USERINFO_URL,notEmpty"`
-	RedirectURL  string   `env:"LLAMERO_OAUTH_REDIRECT_URL,notEmpty"`
-	Scopes       []string `env:"LLAMERO_OAUTH_SCOPES"                 envDefault:"openid,email,profile" envSeparator:","`
+	ProviderName string        `env:"LLAMERO_OAUTH_PROVIDER_NAME"        envDefault:"oauth"`
+	ClientID     string        `env:"LLAMERO_OAUTH_CLIENT_ID,notEmpty"`
+	ClientSecret string        `env:"LLAMERO_OAUTH_CLIENT_SECRET"`
+	PublicClient bool          `env:"LLAMERO_OAUTH_PUBLIC_CLIENT"        envDefault:"false"`
+	PKCE         bool          `env:"LLAMERO_OAUTH_PKCE"                 envDefault:"true"`
+	IssuerURL    string        `env:"LLAMERO_OAUTH_ISSUER_URL"`
+	AuthorizeURL string        `env:"LLAMERO_OAUTH_AUTHORIZE_URL"`
+	TokenURL     string        `env:"LLAMERO_OAUTH_TOKEN_URL"`
+	UserInfoURL  string        `env:"LLAMERO_OAUTH_USERINFO_URL"`
+	JWKSURL      string        `env:"LLAMERO_OAUTH_JWKS_URL"`
+	RedirectURL  string        `env:"LLAMERO_OAUTH_REDIRECT_URL,notEmpty"`
+	Scopes       []string      `env:"LLAMERO_OAUTH_SCOPES"               envDefault:"openid,email,profile" envSeparator:","`
+	StateStore   string        `env:"LLAMERO_OAUTH_STATE_STORE"          envDefault:"redis"`
+	StateTTL     time.Duration `env:"LLAMERO_OAUTH_STATE_TTL"            envDefault:"5m"`
 }
 
-// JWTConfig defines how internal tokens are signed.
+// OAuth state stores accepted in LLAMERO_OAUTH_STATE_STORE.
+const (
+	StateStoreRedis  = "redis"
+	StateStoreMemory = "memory"
+)
+
+// JWTConfig defines how internal tokens are signed. The private key signs until the first
+// rotation; keys in VerifyKeyPaths are only used to verify tokens. KeyEncryptionKey is the
+// required base64-encoded 32-byte key that encrypts rotated private keys at rest in Postgres.
 type JWTConfig struct {
-	Issuer         string        `env:"LLAMERO_JWT_ISSUER"                    envDefault:"llamero"`
-	Audience       string        `env:"LLAMERO_JWT_AUDIENCE"                  envDefault:"ollama-clients"`
-	PrivateKeyPath string        `env:"LLAMERO_JWT_PRIVATE_KEY_PATH,notEmpty"`
-	PublicKeyPath  string        `env:"LLAMERO_JWT_PUBLIC_KEY_PATH"`
-	SigningMethod  string        `env:"LLAMERO_JWT_SIGNING_METHOD"            envDefault:"EdDSA"`
-	TTL            time.Duration `env:"LLAMERO_JWT_TTL"                       envDefault:"1h"`
+	Issuer           string        `env:"LLAMERO_JWT_ISSUER"                    envDefault:"llamero"`
+	Audience         string        `env:"LLAMERO_JWT_AUDIENCE"                  envDefault:"ollama-clients"`
+	PrivateKeyPath   string        `env:"LLAMERO_JWT_PRIVATE_KEY_PATH,notEmpty"`
+	PublicKeyPath    string        `env:"LLAMERO_JWT_PUBLIC_KEY_PATH"`
+	VerifyKeyPaths   []string      `env:"LLAMERO_JWT_VERIFY_KEY_PATHS"          envSeparator:","`
+	SigningMethod    string        `env:"LLAMERO_JWT_SIGNING_METHOD"            envDefault:"EdDSA"`
+	TTL              time.Duration `env:"LLAMERO_JWT_TTL"                       envDefault:"1h"`
+	KeyEncryptionKey string        `env:"LLAMERO_JWT_KEY_ENCRYPTION_KEY,notEmpty"`
 }
 
+// SessionConfig controls browser login sessions. A session ends when its refresh token expires
+// or it is revoked, whichever comes first. With Cookies set, the callback stores the session in
+// HttpOnly cookies instead of handing the tokens to the UI in the redirect.
+type SessionConfig struct {
+	RefreshTTL     time.Duration `env:"LLAMERO_SESSION_REFRESH_TTL"     envDefault:"720h"`
+	Cookies        bool          `env:"LLAMERO_SESSION_COOKIES"         envDefault:"false"`
+	CookieName     string        `env:"LLAMERO_SESSION_COOKIE_NAME"     envDefault:"llamero_session"`
+	CookieDomain   string        `env:"LLAMERO_SESSION_COOKIE_DOMAIN"`
+	CookieSecure   bool          `env:"LLAMERO_SESSION_COOKIE_SECURE"   envDefault:"true"`
+	CookieSameSite string        `env:"LLAMERO_SESSION_COOKIE_SAMESITE" envDefault:"lax"`
+}
+
+// SameSite values accepted in LLAMERO_SESSION_COOKIE_SAMESITE.
+const (
+	SameSiteStrict = "strict"
+	SameSiteLax    = "lax"
+	SameSiteNone   = "none"
+)
+
 // RoleMappingConfig maps IdP group names to internal role names defined in roles.yaml.
 type RoleMappingConfig struct {
 	Raw    string              `env:"LLAMERO_ROLE_GROUPS"`
@@ -75,6 +126,81 @@ type BackendsConfig struct {
 	FilePath string `env:"LLAMERO_BACKENDS_FILE" envDefault:"config/backends.yaml"`
 }
 
+// ProfilesConfig points at the per-model request profiles.
+type ProfilesConfig struct {
+	FilePath string `env:"LLAMERO_PROFILES_FILE" envDefault:"config/profiles.yaml"`
+}
+
+// GuardrailsConfig points at the guardrail filters applied to prompts and completions.
+type GuardrailsConfig struct {
+	FilePath string `env:"LLAMERO_GUARDRAILS_FILE" envDefault:"config/guardrails.yaml"`
+}
+
+// StructuredOutputConfig controls how strict json_schema chat completions are validated. Batches
+// and jobs retry schema mismatches within their own attempt budget.
+type StructuredOutputConfig struct {
+	MaxAttempts int `env:"LLAMERO_STRUCTURED_OUTPUT_MAX_ATTEMPTS" envDefault:"2"`
+}
+
+// ChoicesConfig bounds chat requests that ask for several completions with n. Each choice is a
+// separate backend request.
+type ChoicesConfig struct {
+	Max int `env:"LLAMERO_CHAT_MAX_CHOICES" envDefault:"8"`
+}
+
+// EmbeddingsConfig controls how large embedding requests are split across backends.
+type EmbeddingsConfig struct {
+	BatchSize   int `env:"LLAMERO_EMBEDDINGS_BATCH_SIZE"   envDefault:"64"`
+	MaxParallel int `env:"LLAMERO_EMBEDDINGS_MAX_PARALLEL" envDefault:"8"`
+	MaxAttempts int `env:"LLAMERO_EMBEDDINGS_MAX_ATTEMPTS" envDefault:"3"`
+	// CacheTTL controls how long vectors stay cached per model digest; zero disables the cache.
+	CacheTTL time.Duration `env:"LLAMERO_EMBEDDINGS_CACHE_TTL" envDefault:"168h"`
+}
+
+// ProxyConfig caps request body sizes per proxied route group. Roles may override these limits
+// in roles.yaml.
+type ProxyConfig struct {
+	MaxLLMBodyBytes        int64 `env:"LLAMERO_PROXY_MAX_LLM_BODY_BYTES"        envDefault:"5242880"`
+	MaxEmbeddingsBodyBytes int64 `env:"LLAMERO_PROXY_MAX_EMBEDDINGS_BODY_BYTES" envDefault:"5242880"`
+	MaxAdminBodyBytes      int64 `env:"LLAMERO_PROXY_MAX_ADMIN_BODY_BYTES"      envDefault:"5242880"`
+}
+
+// ResponseCacheConfig bounds the LLM response cache. Caching itself is enabled per profile and
+// per token.
+type ResponseCacheConfig struct {
+	TTL      time.Duration `env:"LLAMERO_RESPONSE_CACHE_TTL"       envDefault:"1h"`
+	MaxBytes int           `env:"LLAMERO_RESPONSE_CACHE_MAX_BYTES" envDefault:"1048576"`
+}
+
+// FilesConfig bounds uploaded files.
+type FilesConfig struct {
+	MaxBytes int64 `env:"LLAMERO_FILES_MAX_BYTES" envDefault:"104857600"`
+}
+
+// BatchConfig controls how batches are validated by the server and executed by the worker.
+type BatchConfig struct {
+	MaxRequests    int           `env:"LLAMERO_BATCH_MAX_REQUESTS"    envDefault:"50000"`
+	Concurrency    int           `env:"LLAMERO_BATCH_CONCURRENCY"     envDefault:"4"`
+	MaxAttempts    int           `env:"LLAMERO_BATCH_MAX_ATTEMPTS"    envDefault:"3"`
+	RequestTimeout time.Duration `env:"LLAMERO_BATCH_REQUEST_TIMEOUT" envDefault:"10m"`
+}
+
+// JobsConfig controls asynchronous generation jobs.
+type JobsConfig struct {
+	Timeout     time.Duration `env:"LLAMERO_JOBS_TIMEOUT"      envDefault:"1h"`
+	MaxAttempts int           `env:"LLAMERO_JOBS_MAX_ATTEMPTS" envDefault:"3"`
+}
+
+// WebhookConfig signs and retries job completion webhooks. Callbacks are refused while no secret
+// is configured. Deliveries to loopback, private, link-local and unspecified addresses are blocked
+// unless AllowPrivateNetworks is set.
+type WebhookConfig struct {
+	Secret               string        `env:"LLAMERO_WEBHOOK_SECRET"`
+	Timeout              time.Duration `env:"LLAMERO_WEBHOOK_TIMEOUT"                envDefault:"10s"`
+	MaxAttempts          int           `env:"LLAMERO_WEBHOOK_MAX_ATTEMPTS"           envDefault:"8"`
+	AllowPrivateNetworks bool          `env:"LLAMERO_WEBHOOK_ALLOW_PRIVATE_NETWORKS" envDefault:"false"`
+}
+
 // WorkerSettings control the background worker runtime.
 type WorkerSettings struct {
 	Concurrency int `env:"LLAMERO_WORKER_CONCURRENCY" envDefault:"5"`
@@ -87,9 +213,14 @@ type SchedulerSettings struct {
 
 // WorkerConfig contains only the knobs needed by the worker binary.
 type WorkerConfig struct {
-	Database DatabaseConfig
-	Store    RedisConfig
-	Worker   WorkerSettings
+	Database   DatabaseConfig
+	Store      RedisConfig
+	Worker     WorkerSettings
+	Profiles   ProfilesConfig
+	Guardrails GuardrailsConfig
+	Batches    BatchConfig
+	Jobs       JobsConfig
+	Webhooks   WebhookConfig
 }
 
 // SchedulerConfig contains the scheduler-only runtime knobs.
@@ -127,6 +258,12 @@ func LoadServer() (*ServerConfig, error) {
 		return nil, err
 	}
 	cfg.Roles.Groups = groups
+	if err = cfg.OAuth.validate(); err != nil {
+		return nil, err
+	}
+	if err = cfg.Sessions.validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -148,6 +285,49 @@ func LoadScheduler() (*SchedulerConfig, error) {
 	return &cfg, nil
 }
 
+func (c OAuthConfig) validate() error {
+	if c.StateStore != StateStoreRedis && c.StateStore != StateStoreMemory {
+		return fmt.Errorf("unknown LLAMERO_OAUTH_STATE_STORE %q", c.StateStore)
+	}
+	if c.PublicClient && !c.PKCE {
+		return errors.New("LLAMERO_OAUTH_PUBLIC_CLIENT requires LLAMERO_OAUTH_PKCE")
+	}
+	if !c.PublicClient && strings.TrimSpace(c.ClientSecret) == "" {
+		return errors.New("LLAMERO_OAUTH_CLIENT_SECRET is required unless LLAMERO_OAUTH_PUBLIC_CLIENT is set")
+	}
+	if strings.TrimSpace(c.IssuerURL) != "" {
+		return nil
+	}
+	if strings.TrimSpace(c.AuthorizeURL) == "" || strings.TrimSpace(c.TokenURL) == "" {
+		return errors.New(
+			"LLAMERO_OAUTH_AUTHORIZE_URL and LLAMERO_OAUTH_TOKEN_URL are required without LLAMERO_OAUTH_ISSUER_URL",
+		)
+	}
+	if strings.TrimSpace(c.UserInfoURL) == "" && strings.TrimSpace(c.JWKSURL) == "" {
+		return errors.New("LLAMERO_OAUTH_USERINFO_URL or LLAMERO_OAUTH_JWKS_URL is required without LLAMERO_OAUTH_ISSUER_URL")
+	}
+	return nil
+}
+
+func (c SessionConfig) validate() error {
+	if !c.Cookies {
+		return nil
+	}
+	if strings.TrimSpace(c.CookieName) == "" {
+		return errors.New("LLAMERO_SESSION_COOKIE_NAME is required with LLAMERO_SESSION_COOKIES")
+	}
+	switch c.CookieSameSite {
+	case SameSiteStrict, SameSiteLax:
+	case SameSiteNone:
+		if !c.CookieSecure {
+			return errors.New("LLAMERO_SESSION_COOKIE_SAMESITE=none requires LLAMERO_SESSION_COOKIE_SECURE")
+		}
+	default:
+		return fmt.Errorf("unknown LLAMERO_SESSION_COOKIE_SAMESITE %q", c.CookieSameSite)
+	}
+	return nil
+}
+
 func parseRoleGroups(value string) (map[string][]string, error) {
 	result := make(map[string][]string)
 	if strings.TrimSpace(value) == "" {
@@ -0,0 +1,3 @@
+// Package contextwindow keeps chat conversations within a model's context window, either by
+// rejecting oversized requests or by dropping the oldest messages.
+package contextwindow
@@ -0,0 +1,57 @@
+package contextwindow
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Estimators accepted by NewEstimator.
+const (
+	EstimatorChars = "chars"
+	EstimatorWords = "words"
+)
+
+const (
+	charsPerToken    = 4
+	tokensPerWordNum = 4
+	tokensPerWordDiv = 3
+)
+
+// Estimator approximates how many tokens a text takes up. Estimates need not be exact but should
+// err on the high side, since the backend truncates whatever does not fit.
+type Estimator interface {
+	Tokens(text string) int
+}
+
+// NewEstimator returns a built-in estimator by name. An empty name selects chars.
+func NewEstimator(name string) (Estimator, error) {
+	switch strings.TrimSpace(name) {
+	case "", EstimatorChars:
+		return CharEstimator{}, nil
+	case EstimatorWords:
+		return WordEstimator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown token estimator %q", name)
+	}
+}
+
+// CharEstimator counts one token per four characters, a common rule of thumb for English text.
+type CharEstimator struct{}
+
+// Tokens implements Estimator.
+func (CharEstimator) Tokens(text string) int {
+	return ceilDiv(utf8.RuneCountInString(text), charsPerToken)
+}
+
+// WordEstimator counts four tokens per three whitespace-separated words.
+type WordEstimator struct{}
+
+// Tokens implements Estimator.
+func (WordEstimator) Tokens(text string) int {
+	return ceilDiv(len(strings.Fields(text))*tokensPerWordNum, tokensPerWordDiv)
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
@@ -0,0 +1,144 @@
+package contextwindow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Policies accepted in model profiles.
+const (
+	PolicyReject = "reject"
+	PolicyTrim   = "trim"
+)
+
+const (
+	roleSystem = "system"
+	roleTool   = "tool"
+
+	// messageOverhead approximates the tokens a chat template spends framing each message.
+	messageOverhead = 4
+)
+
+// ExceededError reports a conversation that does not fit the context window.
+type ExceededError struct {
+	Tokens int
+	Limit  int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf(
+		"conversation needs about %d tokens but the model's context window leaves room for %d",
+		e.Tokens,
+		e.Limit,
+	)
+}
+
+// Policy fits chat messages into a token budget.
+type Policy struct {
+	// Mode is PolicyReject or PolicyTrim.
+	Mode      string
+	Estimator Estimator
+}
+
+type message struct {
+	Role      string          `json:"role"`
+	Content   json.RawMessage `json:"content"`
+	ToolCalls json.RawMessage `json:"tool_calls"`
+}
+
+type contentPart struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Fit returns the messages that fit in limit tokens and how many were dropped. Trimming drops the
+// oldest messages first but keeps system messages and the latest message, and drops tool results
+// together with the assistant turn that requested them. When the latest message is a tool result,
+// the assistant turn that requested it is kept as well. An *ExceededError is returned when the
+// policy rejects the conversation or trimming cannot make it fit.
+func (p Policy) Fit(messages []json.RawMessage, limit int) ([]json.RawMessage, int, error) {
+	roles := make([]string, len(messages))
+	tokens := make([]int, len(messages))
+	total := 0
+	for i, raw := range messages {
+		roles[i], tokens[i] = p.estimate(raw)
+		total += tokens[i]
+	}
+	if total <= limit {
+		return messages, 0, nil
+	}
+	if p.Mode != PolicyTrim {
+		return nil, 0, &ExceededError{Tokens: total, Limit: limit}
+	}
+
+	dropped := make([]bool, len(messages))
+	count := 0
+	keep := tailStart(roles)
+	for i := 0; i < keep && total > limit; i++ {
+		if roles[i] == roleSystem {
+			continue
+		}
+		dropped[i] = true
+		total -= tokens[i]
+		count++
+		for i+1 < keep && roles[i+1] == roleTool {
+			i++
+			dropped[i] = true
+			total -= tokens[i]
+			count++
+		}
+	}
+	if total > limit {
+		return nil, 0, &ExceededError{Tokens: total, Limit: limit}
+	}
+
+	kept := make([]json.RawMessage, 0, len(messages)-count)
+	for i, raw := range messages {
+		if !dropped[i] {
+			kept = append(kept, raw)
+		}
+	}
+	return kept, count, nil
+}
+
+// tailStart returns the index of the first message trimming must keep: the latest message or, when
+// it is a tool result, the turn before its run of tool results.
+func tailStart(roles []string) int {
+	start := len(roles) - 1
+	for start > 0 && roles[start] == roleTool {
+		start--
+	}
+	return start
+}
+
+// estimate returns the role of a message and the tokens it takes up.
+func (p Policy) estimate(raw json.RawMessage) (string, int) {
+	var msg message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return "", messageOverhead + p.Estimator.Tokens(string(raw))
+	}
+	tokens := messageOverhead + p.Estimator.Tokens(contentText(msg.Content))
+	if len(msg.ToolCalls) > 0 {
+		tokens += p.Estimator.Tokens(string(msg.ToolCalls))
+	}
+	return msg.Role, tokens
+}
+
+// contentText joins the text of a string content or of its text parts.
+func contentText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var parts []contentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(part.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package contextwindow
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func chatMessage(t *testing.T, role, content string) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(map[string]string{"role": role, "content": content})
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	return raw
+}
+
+func toolCallMessage(t *testing.T, content string) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{
+		"role":       "assistant",
+		"content":    content,
+		"tool_calls": []map[string]string{{"id": "call_1", "type": "function"}},
+	})
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	return raw
+}
+
+func messageRoles(t *testing.T, messages []json.RawMessage) []string {
+	t.Helper()
+	roles := make([]string, len(messages))
+	for i, raw := range messages {
+		var msg message
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			t.Fatalf("unmarshal message: %v", err)
+		}
+		roles[i] = msg.Role
+	}
+	return roles
+}
+
+func TestFitKeepsConversationWithinLimit(t *testing.T) {
+	policy := Policy{Mode: PolicyReject, Estimator: CharEstimator{}}
+	messages := []json.RawMessage{chatMessage(t, "user", "hello")}
+
+	kept, dropped, err := policy.Fit(messages, 100)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if dropped != 0 || len(kept) != 1 {
+		t.Fatalf("Fit kept %d and dropped %d messages, want 1 and 0", len(kept), dropped)
+	}
+}
+
+func TestFitRejectsOversizedConversation(t *testing.T) {
+	policy := Policy{Mode: PolicyReject, Estimator: CharEstimator{}}
+	messages := []json.RawMessage{
+		chatMessage(t, "user", strings.Repeat("a", 400)),
+		chatMessage(t, "user", "hello"),
+	}
+
+	_, _, err := policy.Fit(messages, 50)
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Fit error = %v, want *ExceededError", err)
+	}
+	if exceeded.Limit != 50 || exceeded.Tokens <= 50 {
+		t.Fatalf("ExceededError = %+v, want tokens above limit 50", exceeded)
+	}
+}
+
+func TestFitTrimsOldestMessagesAndKeepsSystem(t *testing.T) {
+	policy := Policy{Mode: PolicyTrim, Estimator: CharEstimator{}}
+	messages := []json.RawMessage{
+		chatMessage(t, "system", "be brief"),
+		chatMessage(t, "user", strings.Repeat("a", 200)),
+		chatMessage(t, "assistant", strings.Repeat("b", 200)),
+		chatMessage(t, "user", "hello"),
+	}
+
+	kept, dropped, err := policy.Fit(messages, 30)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if dropped != 2 {
+		t.Fatalf("Fit dropped %d messages, want 2", dropped)
+	}
+	if got, want := messageRoles(t, kept), []string{"system", "user"}; !slices.Equal(got, want) {
+		t.Fatalf("Fit kept roles %v, want %v", got, want)
+	}
+}
+
+func TestFitDropsToolResultsWithTheirToolCall(t *testing.T) {
+	policy := Policy{Mode: PolicyTrim, Estimator: CharEstimator{}}
+	messages := []json.RawMessage{
+		chatMessage(t, "user", strings.Repeat("a", 200)),
+		toolCallMessage(t, ""),
+		chatMessage(t, "tool", strings.Repeat("c", 200)),
+		chatMessage(t, "user", "hello"),
+	}
+
+	kept, dropped, err := policy.Fit(messages, 10)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if dropped != 3 {
+		t.Fatalf("Fit dropped %d messages, want 3", dropped)
+	}
+	if got, want := messageRoles(t, kept), []string{"user"}; !slices.Equal(got, want) {
+		t.Fatalf("Fit kept roles %v, want %v", got, want)
+	}
+}
+
+func TestFitKeepsToolCallForLatestToolResult(t *testing.T) {
+	policy := Policy{Mode: PolicyTrim, Estimator: CharEstimator{}}
+	messages := []json.RawMessage{
+		chatMessage(t, "user", strings.Repeat("a", 400)),
+		toolCallMessage(t, ""),
+		chatMessage(t, "tool", "sunny"),
+		chatMessage(t, "tool", "warm"),
+	}
+
+	kept, dropped, err := policy.Fit(messages, 60)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if dropped != 1 {
+		t.Fatalf("Fit dropped %d messages, want 1", dropped)
+	}
+	if got, want := messageRoles(t, kept), []string{"assistant", "tool", "tool"}; !slices.Equal(got, want) {
+		t.Fatalf("Fit kept roles %v, want %v", got, want)
+	}
+}
+
+func TestFitRejectsWhenLatestToolResultCannotFitWithItsToolCall(t *testing.T) {
+	policy := Policy{Mode: PolicyTrim, Estimator: CharEstimator{}}
+	messages := []json.RawMessage{
+		chatMessage(t, "user", "weather?"),
+		toolCallMessage(t, strings.Repeat("b", 400)),
+		chatMessage(t, "tool", "sunny"),
+	}
+
+	_, _, err := policy.Fit(messages, 60)
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Fit error = %v, want *ExceededError", err)
+	}
+}
@@ -0,0 +1,3 @@
+// Package guardrails runs configurable filters over LLM prompts before they reach a backend and
+// over completions before they reach the caller, including streamed completions.
+package guardrails
@@ -0,0 +1,159 @@
+package guardrails
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+const contentPartText = "text"
+
+// slot is an editable text field inside a decoded JSON document.
+type slot struct {
+	text string
+	set  func(string)
+}
+
+// CheckRequest runs the pre filters over the prompt text of a chat or completion request body:
+// message contents, text content parts and prompts. It returns the body, rewritten when a filter
+// redacted text, or a *Violation when the request is blocked.
+func (c *Chain) CheckRequest(ctx context.Context, body []byte, meta Meta) ([]byte, error) {
+	if !c.Active(StagePre, meta.Model) {
+		return body, nil
+	}
+	return c.checkDocument(ctx, body, meta, StagePre, requestSlots)
+}
+
+// CheckResponse runs the post filters over the choices of a non-streamed chat or completion
+// response body.
+func (c *Chain) CheckResponse(ctx context.Context, body []byte, meta Meta) ([]byte, error) {
+	if !c.Active(StagePost, meta.Model) {
+		return body, nil
+	}
+	return c.checkDocument(ctx, body, meta, StagePost, choiceSlots)
+}
+
+func (c *Chain) checkDocument(
+	ctx context.Context,
+	body []byte,
+	meta Meta,
+	stage Stage,
+	collect func(map[string]any) []*slot,
+) ([]byte, error) {
+	doc, ok := decodeObject(body)
+	if !ok {
+		return body, nil
+	}
+	slots := collect(doc)
+	if len(slots) == 0 {
+		return body, nil
+	}
+
+	content := &Content{Meta: meta, Stage: stage, Texts: make([]string, len(slots))}
+	for i, s := range slots {
+		content.Texts[i] = s.text
+	}
+	if err := c.Run(ctx, content); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for i, s := range slots {
+		if content.Texts[i] != s.text {
+			s.set(content.Texts[i])
+			changed = true
+		}
+	}
+	if !changed {
+		return body, nil
+	}
+	return json.Marshal(doc)
+}
+
+// decodeObject decodes a JSON object, keeping numbers verbatim so re-encoding does not alter them.
+func decodeObject(body []byte) (map[string]any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil || doc == nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+func requestSlots(doc map[string]any) []*slot {
+	var slots []*slot
+	if messages, ok := doc["messages"].([]any); ok {
+		for _, item := range messages {
+			if message, isMap := item.(map[string]any); isMap {
+				slots = append(slots, contentSlots(message, "content")...)
+			}
+		}
+	}
+	switch prompt := doc["prompt"].(type) {
+	case string:
+		slots = append(slots, fieldSlot(doc, "prompt", prompt))
+	case []any:
+		for i, item := range prompt {
+			if text, ok := item.(string); ok {
+				slots = append(slots, indexSlot(prompt, i, text))
+			}
+		}
+	}
+	return slots
+}
+
+// choiceSlots returns the generated text of every choice: message or delta content for chat and
+// text for legacy completions.
+func choiceSlots(doc map[string]any) []*slot {
+	choices, ok := doc["choices"].([]any)
+	if !ok {
+		return nil
+	}
+	var slots []*slot
+	for _, item := range choices {
+		choice, isMap := item.(map[string]any)
+		if !isMap {
+			continue
+		}
+		for _, key := range []string{"message", "delta"} {
+			if message, found := choice[key].(map[string]any); found {
+				slots = append(slots, contentSlots(message, "content")...)
+			}
+		}
+		if text, found := choice["text"].(string); found {
+			slots = append(slots, fieldSlot(choice, "text", text))
+		}
+	}
+	return slots
+}
+
+// contentSlots handles content given either as a string or as an array of typed parts.
+func contentSlots(message map[string]any, key string) []*slot {
+	switch content := message[key].(type) {
+	case string:
+		return []*slot{fieldSlot(message, key, content)}
+	case []any:
+		var slots []*slot
+		for _, item := range content {
+			part, ok := item.(map[string]any)
+			if !ok || part["type"] != contentPartText {
+				continue
+			}
+			if text, found := part[contentPartText].(string); found {
+				slots = append(slots, fieldSlot(part, contentPartText, text))
+			}
+		}
+		return slots
+	default:
+		return nil
+	}
+}
+
+func fieldSlot(parent map[string]any, key, text string) *slot {
+	return &slot{text: text, set: func(value string) { parent[key] = value }}
+}
+
+func indexSlot(parent []any, index int, text string) *slot {
+	return &slot{text: text, set: func(value string) { parent[index] = value }}
+}
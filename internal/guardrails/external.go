@@ -0,0 +1,117 @@
+package guardrails
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const maxPolicyResponseBytes = 1 << 20
+
+// externalFilter asks an HTTP policy endpoint whether content is allowed.
+//
+// The endpoint receives {"stage", "model", "role", "subject", "texts"} and answers with
+// {"allow": bool, "reason": string, "texts": [...]}. Returned texts replace the inspected ones
+// when they have the same length, so the policy can redact as well as block.
+type externalFilter struct {
+	name     string
+	url      string
+	failOpen bool
+	client   *http.Client
+}
+
+type policyRequest struct {
+	Stage   Stage    `json:"stage"`
+	Model   string   `json:"model"`
+	Role    string   `json:"role,omitempty"`
+	Subject string   `json:"subject,omitempty"`
+	Texts   []string `json:"texts"`
+}
+
+type policyResponse struct {
+	Allow  bool     `json:"allow"`
+	Reason string   `json:"reason"`
+	Texts  []string `json:"texts"`
+}
+
+func newExternalFilter(name, rawURL, timeout string, failOpen bool) (*externalFilter, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("url must be an absolute http or https URL")
+	}
+	wait := defaultExternalTimeout
+	if timeout != "" {
+		wait, err = time.ParseDuration(timeout)
+		if err != nil || wait <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", timeout)
+		}
+	}
+	return &externalFilter{
+		name:     name,
+		url:      parsed.String(),
+		failOpen: failOpen,
+		client:   &http.Client{Timeout: wait},
+	}, nil
+}
+
+func (f *externalFilter) Check(ctx context.Context, content *Content) error {
+	decision, err := f.ask(ctx, content)
+	if err != nil {
+		if f.failOpen && ctx.Err() == nil {
+			return nil
+		}
+		return fmt.Errorf("guardrail %q: %w", f.name, err)
+	}
+	if !decision.Allow {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "denied by policy"
+		}
+		return &Violation{Filter: f.name, Reason: reason}
+	}
+	if len(decision.Texts) == len(content.Texts) {
+		copy(content.Texts, decision.Texts)
+	}
+	return nil
+}
+
+func (f *externalFilter) ask(ctx context.Context, content *Content) (policyResponse, error) {
+	payload, err := json.Marshal(policyRequest{
+		Stage:   content.Stage,
+		Model:   content.Model,
+		Role:    content.Role,
+		Subject: content.Subject,
+		Texts:   content.Texts,
+	})
+	if err != nil {
+		return policyResponse{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(payload))
+	if err != nil {
+		return policyResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return policyResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return policyResponse{}, fmt.Errorf("policy endpoint returned status %d", resp.StatusCode)
+	}
+
+	var decision policyResponse
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, maxPolicyResponseBytes))
+	if decodeErr := decoder.Decode(&decision); decodeErr != nil {
+		return policyResponse{}, errors.New("invalid policy response")
+	}
+	return decision, nil
+}
@@ -0,0 +1,251 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Filter types accepted in the guardrails file.
+const (
+	TypeBlocklist = "blocklist"
+	TypePII       = "pii"
+	TypeMaxLength = "max_length"
+	TypeExternal  = "external"
+)
+
+const (
+	stageBoth = "both"
+
+	piiEmail = "email"
+	piiCard  = "card"
+
+	defaultReplacement     = "[REDACTED]"
+	defaultExternalTimeout = 5 * time.Second
+
+	luhnDoubleLimit = 9
+	luhnModulus     = 10
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+// Config describes a single filter in the guardrails file. Fields that do not apply to the
+// filter type are ignored.
+type Config struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// Stage is pre, post or both (the default).
+	Stage string `yaml:"stage"`
+	// Models limits the filter to the listed models; empty applies it to every model.
+	Models []string `yaml:"models"`
+
+	// Keywords and Patterns are matched by blocklist filters. Keywords are case-insensitive.
+	Keywords []string `yaml:"keywords"`
+	Patterns []string `yaml:"patterns"`
+
+	// Detect lists the PII kinds to redact (email, card); empty redacts both.
+	Detect      []string `yaml:"detect"`
+	Replacement string   `yaml:"replacement"`
+
+	// MaxChars bounds the prompt length for max_length filters.
+	MaxChars int `yaml:"max_chars"`
+
+	// URL, Timeout and FailOpen configure external policy filters.
+	URL      string `yaml:"url"`
+	Timeout  string `yaml:"timeout"`
+	FailOpen bool   `yaml:"fail_open"`
+}
+
+func (c Config) build() (entry, error) {
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = c.Type
+	}
+	stages, err := c.stages(name)
+	if err != nil {
+		return entry{}, err
+	}
+
+	var filter Filter
+	switch c.Type {
+	case TypeBlocklist:
+		filter, err = newBlocklistFilter(name, c.Keywords, c.Patterns)
+	case TypePII:
+		filter, err = newPIIFilter(c.Detect, c.Replacement)
+	case TypeMaxLength:
+		if c.MaxChars <= 0 {
+			return entry{}, fmt.Errorf("guardrail %q max_chars must be positive", name)
+		}
+		if Stage(c.Stage) == StagePost {
+			return entry{}, fmt.Errorf("guardrail %q: max_length only applies to prompts", name)
+		}
+		stages = []Stage{StagePre}
+		filter = &maxLengthFilter{name: name, limit: c.MaxChars}
+	case TypeExternal:
+		filter, err = newExternalFilter(name, c.URL, c.Timeout, c.FailOpen)
+	default:
+		return entry{}, fmt.Errorf("guardrail %q has unknown type %q", name, c.Type)
+	}
+	if err != nil {
+		return entry{}, fmt.Errorf("guardrail %q: %w", name, err)
+	}
+
+	models := make([]string, 0, len(c.Models))
+	for _, model := range c.Models {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+	return entry{name: name, stages: stages, models: models, filter: filter}, nil
+}
+
+func (c Config) stages(name string) ([]Stage, error) {
+	switch Stage(strings.TrimSpace(c.Stage)) {
+	case "", stageBoth:
+		return []Stage{StagePre, StagePost}, nil
+	case StagePre:
+		return []Stage{StagePre}, nil
+	case StagePost:
+		return []Stage{StagePost}, nil
+	default:
+		return nil, fmt.Errorf("guardrail %q has unknown stage %q", name, c.Stage)
+	}
+}
+
+// blocklistFilter rejects content containing a keyword or matching a pattern.
+type blocklistFilter struct {
+	name     string
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+func newBlocklistFilter(name string, keywords, patterns []string) (*blocklistFilter, error) {
+	filter := &blocklistFilter{name: name}
+	for _, keyword := range keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			filter.keywords = append(filter.keywords, keyword)
+		}
+	}
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		filter.patterns = append(filter.patterns, compiled)
+	}
+	if len(filter.keywords) == 0 && len(filter.patterns) == 0 {
+		return nil, errors.New("blocklist needs keywords or patterns")
+	}
+	return filter, nil
+}
+
+func (f *blocklistFilter) Check(_ context.Context, content *Content) error {
+	for _, text := range content.Texts {
+		lower := strings.ToLower(text)
+		for _, keyword := range f.keywords {
+			if strings.Contains(lower, keyword) {
+				return &Violation{Filter: f.name, Reason: "content contains a blocked keyword"}
+			}
+		}
+		for _, pattern := range f.patterns {
+			if pattern.MatchString(text) {
+				return &Violation{Filter: f.name, Reason: "content matches a blocked pattern"}
+			}
+		}
+	}
+	return nil
+}
+
+// piiFilter redacts email addresses and payment card numbers.
+type piiFilter struct {
+	emails      bool
+	cards       bool
+	replacement string
+}
+
+func newPIIFilter(detect []string, replacement string) (*piiFilter, error) {
+	filter := &piiFilter{replacement: replacement}
+	if filter.replacement == "" {
+		filter.replacement = defaultReplacement
+	}
+	if len(detect) == 0 {
+		filter.emails, filter.cards = true, true
+	}
+	for _, kind := range detect {
+		switch strings.TrimSpace(kind) {
+		case piiEmail:
+			filter.emails = true
+		case piiCard:
+			filter.cards = true
+		default:
+			return nil, fmt.Errorf("unknown pii kind %q", kind)
+		}
+	}
+	return filter, nil
+}
+
+func (f *piiFilter) Check(_ context.Context, content *Content) error {
+	for i, text := range content.Texts {
+		if f.emails {
+			text = emailPattern.ReplaceAllString(text, f.replacement)
+		}
+		if f.cards {
+			text = cardPattern.ReplaceAllStringFunc(text, func(match string) string {
+				if luhnValid(match) {
+					return f.replacement
+				}
+				return match
+			})
+		}
+		content.Texts[i] = text
+	}
+	return nil
+}
+
+// luhnValid reports whether the digits in value pass the Luhn checksum used by card numbers.
+func luhnValid(value string) bool {
+	sum, double := 0, false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > luhnDoubleLimit {
+				digit -= luhnDoubleLimit
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%luhnModulus == 0
+}
+
+// maxLengthFilter rejects prompts longer than the configured number of characters.
+type maxLengthFilter struct {
+	name  string
+	limit int
+}
+
+func (f *maxLengthFilter) Check(_ context.Context, content *Content) error {
+	total := 0
+	for _, text := range content.Texts {
+		total += utf8.RuneCountInString(text)
+	}
+	if total > f.limit {
+		return &Violation{
+			Filter: f.name,
+			Reason: fmt.Sprintf("prompt is %d characters, the limit is %d", total, f.limit),
+		}
+	}
+	return nil
+}
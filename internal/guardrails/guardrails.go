@@ -0,0 +1,146 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath defines where the server looks for guardrails if no override is supplied.
+const DefaultPath = "config/guardrails.yaml"
+
+const defaultStreamWindow = 64
+
+// Stage identifies the side of an LLM exchange a filter inspects.
+type Stage string
+
+const (
+	// StagePre inspects prompts before they are forwarded to a backend.
+	StagePre Stage = "pre"
+	// StagePost inspects completions before they are returned to the caller.
+	StagePost Stage = "post"
+)
+
+// Meta describes the request a filter runs for.
+type Meta struct {
+	Model   string
+	Role    string
+	Subject string
+}
+
+// Content is the text a filter inspects. Filters may rewrite Texts in place to redact them.
+type Content struct {
+	Meta
+
+	Stage Stage
+	Texts []string
+}
+
+// Filter inspects content and returns a *Violation to block it.
+type Filter interface {
+	Check(ctx context.Context, content *Content) error
+}
+
+// Violation reports content blocked by a filter.
+type Violation struct {
+	Filter string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("blocked by guardrail %q: %s", v.Filter, v.Reason)
+}
+
+// Chain runs the configured filters in order.
+type Chain struct {
+	entries []entry
+	window  int
+}
+
+type entry struct {
+	name   string
+	stages []Stage
+	models []string
+	filter Filter
+}
+
+type document struct {
+	StreamWindow int      `yaml:"stream_window"`
+	Filters      []Config `yaml:"filters"`
+}
+
+// Load reads the YAML document from disk and builds a Chain.
+func Load(path string) (*Chain, error) {
+	if strings.TrimSpace(path) == "" {
+		path = DefaultPath
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("read guardrails file: %w", err)
+	}
+
+	var doc document
+	if err = yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse guardrails file: %w", err)
+	}
+	if doc.StreamWindow < 0 {
+		return nil, errors.New("guardrails stream_window must not be negative")
+	}
+
+	chain := &Chain{window: doc.StreamWindow}
+	if chain.window == 0 {
+		chain.window = defaultStreamWindow
+	}
+	for i := range doc.Filters {
+		built, buildErr := doc.Filters[i].build()
+		if buildErr != nil {
+			return nil, buildErr
+		}
+		chain.entries = append(chain.entries, built)
+	}
+	return chain, nil
+}
+
+// Active reports whether any filter runs at the stage for the model.
+func (c *Chain) Active(stage Stage, model string) bool {
+	if c == nil {
+		return false
+	}
+	for _, e := range c.entries {
+		if e.applies(stage, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run passes the content through every filter that applies to its stage and model. It stops at
+// the first violation or error.
+func (c *Chain) Run(ctx context.Context, content *Content) error {
+	if c == nil {
+		return nil
+	}
+	for _, e := range c.entries {
+		if !e.applies(content.Stage, content.Model) {
+			continue
+		}
+		if err := e.filter.Check(ctx, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e entry) applies(stage Stage, model string) bool {
+	if !slices.Contains(e.stages, stage) {
+		return false
+	}
+	return len(e.models) == 0 || slices.Contains(e.models, strings.TrimSpace(model))
+}
@@ -0,0 +1,228 @@
+package guardrails
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+const streamReleaseFactor = 2
+
+var (
+	eventSeparator = []byte("\n\n")
+	dataPrefix     = []byte("data:")
+)
+
+// StreamWriter applies the post filters to an OpenAI server-sent event stream written through
+// it. Text deltas are held back until twice the stream window is buffered; the filters then run
+// over the buffered text and all but the last window is released. A redaction that spans chunks
+// is therefore applied before any part of the match reaches the caller, as long as the match is
+// no longer than the window. Filters see each released window, not the whole completion.
+//
+// Write returns a *Violation when a filter blocks the output; the caller should then terminate
+// the stream.
+type StreamWriter struct {
+	ctx     context.Context
+	chain   *Chain
+	meta    Meta
+	out     io.Writer
+	buf     []byte
+	pending map[int]*pendingChoice
+	order   []int
+	err     error
+}
+
+// pendingChoice holds the text buffered for one choice and the latest chunk to re-encode it with.
+type pendingChoice struct {
+	template map[string]any
+	slot     *slot
+	text     string
+}
+
+// NewStreamWriter returns a StreamWriter that forwards the filtered stream to out.
+func (c *Chain) NewStreamWriter(ctx context.Context, out io.Writer, meta Meta) *StreamWriter {
+	return &StreamWriter{
+		ctx:     ctx,
+		chain:   c,
+		meta:    meta,
+		out:     out,
+		pending: make(map[int]*pendingChoice),
+	}
+}
+
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.buf = append(s.buf, p...)
+	for {
+		end := bytes.Index(s.buf, eventSeparator)
+		if end < 0 {
+			break
+		}
+		event := s.buf[:end+len(eventSeparator)]
+		if err := s.handleEvent(event); err != nil {
+			s.err = err
+			return 0, err
+		}
+		s.buf = s.buf[end+len(eventSeparator):]
+	}
+	return len(p), nil
+}
+
+// Close releases any buffered text and incomplete event once the upstream stream has ended.
+func (s *StreamWriter) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.flushAll(); err != nil {
+		s.err = err
+		return err
+	}
+	if len(s.buf) > 0 {
+		_, err := s.out.Write(s.buf)
+		s.buf = nil
+		return err
+	}
+	return nil
+}
+
+func (s *StreamWriter) handleEvent(event []byte) error {
+	data, ok := eventData(event)
+	if !ok {
+		return s.passThrough(event)
+	}
+	doc, ok := decodeObject(data)
+	if !ok {
+		return s.passThrough(event)
+	}
+
+	if index, textSlot, bufferable := streamDelta(doc); bufferable {
+		pending, found := s.pending[index]
+		if !found {
+			pending = &pendingChoice{}
+			s.pending[index] = pending
+			s.order = append(s.order, index)
+		}
+		pending.template = doc
+		pending.slot = textSlot
+		pending.text += textSlot.text
+		if utf8.RuneCountInString(pending.text) >= streamReleaseFactor*s.chain.window {
+			return s.release(pending, false)
+		}
+		return nil
+	}
+
+	if err := s.flushAll(); err != nil {
+		return err
+	}
+	filtered, err := s.chain.checkDocument(s.ctx, data, s.meta, StagePost, choiceSlots)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(filtered, data) {
+		_, err = s.out.Write(event)
+		return err
+	}
+	return s.writeData(filtered)
+}
+
+func (s *StreamWriter) passThrough(event []byte) error {
+	if err := s.flushAll(); err != nil {
+		return err
+	}
+	_, err := s.out.Write(event)
+	return err
+}
+
+func (s *StreamWriter) flushAll() error {
+	for _, index := range s.order {
+		if pending := s.pending[index]; pending.text != "" {
+			if err := s.release(pending, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// release filters the buffered text and emits it, keeping the last window back unless final.
+func (s *StreamWriter) release(pending *pendingChoice, final bool) error {
+	content := &Content{Meta: s.meta, Stage: StagePost, Texts: []string{pending.text}}
+	if err := s.chain.Run(s.ctx, content); err != nil {
+		return err
+	}
+	text := content.Texts[0]
+	pending.text = ""
+	if !final {
+		runes := []rune(text)
+		if len(runes) > s.chain.window {
+			cut := len(runes) - s.chain.window
+			pending.text = string(runes[cut:])
+			text = string(runes[:cut])
+		} else {
+			pending.text, text = text, ""
+		}
+	}
+	if text == "" {
+		return nil
+	}
+	pending.slot.set(text)
+	encoded, err := json.Marshal(pending.template)
+	if err != nil {
+		return err
+	}
+	return s.writeData(encoded)
+}
+
+func (s *StreamWriter) writeData(data []byte) error {
+	payload := slices.Concat([]byte("data: "), data, eventSeparator)
+	_, err := s.out.Write(payload)
+	return err
+}
+
+// eventData returns the payload of an event made of a single data line.
+func eventData(event []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(event)
+	if !bytes.HasPrefix(trimmed, dataPrefix) || bytes.ContainsRune(trimmed, '\n') {
+		return nil, false
+	}
+	return bytes.TrimSpace(trimmed[len(dataPrefix):]), true
+}
+
+// streamDelta reports whether a chunk carries nothing but text for a single choice, so its text
+// can be merged with neighbouring chunks.
+func streamDelta(doc map[string]any) (int, *slot, bool) {
+	choices, ok := doc["choices"].([]any)
+	if !ok || len(choices) != 1 {
+		return 0, nil, false
+	}
+	choice, ok := choices[0].(map[string]any)
+	if !ok || choice["finish_reason"] != nil {
+		return 0, nil, false
+	}
+	if delta, found := choice["delta"].(map[string]any); found {
+		for key := range delta {
+			if key != "content" && key != "role" {
+				return 0, nil, false
+			}
+		}
+	}
+	slots := choiceSlots(doc)
+	if len(slots) != 1 {
+		return 0, nil, false
+	}
+	index := 0
+	if raw, found := choice["index"].(json.Number); found {
+		parsed, err := strconv.Atoi(raw.String())
+		if err != nil {
+			return 0, nil, false
+		}
+		index = parsed
+	}
+	return index, slots[0], true
+}
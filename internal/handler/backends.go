@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"io"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/rhajizada/llamero/internal/models"
 	"github.com/rhajizada/llamero/internal/requestctx"
+	"github.com/rhajizada/llamero/internal/roles"
 	"github.com/rhajizada/llamero/internal/service"
 	"github.com/rhajizada/llamero/internal/workers"
 )
@@ -34,7 +34,7 @@ var (
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {array} models.Backend
-// @Failure 500 {object} map[string]string
+// @Failure 500 {object} models.ErrorResponse
 // @Router /api/backends [get].
 func (h *Handler) HandleListBackends(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -43,7 +43,7 @@ func (h *Handler) HandleListBackends(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list backends")
 		return
 	}
-	writeJSON(w, http.StatusOK, backends)
+	writeJSON(w, http.StatusOK, filterBackends(r, backends))
 }
 
 // HandleBackendProcesses godoc
@@ -54,8 +54,8 @@ func (h *Handler) HandleListBackends(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param backendID path string true "Backend ID"
 // @Success 200 {object} models.ProcessResponse
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/ps [get].
 func (h *Handler) HandleBackendProcesses(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendGET(w, r, "/api/ps")
@@ -70,9 +70,10 @@ func (h *Handler) HandleBackendProcesses(w http.ResponseWriter, r *http.Request)
 // @Param backendID path string true "Backend ID"
 // @Param request body models.BackendCreateModelRequest true "Create model payload"
 // @Success 200 {object} models.BackendOperationResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/create [post].
 func (h *Handler) HandleBackendCreate(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendProxyWithBody(w, r, http.MethodPost, "/api/create", true)
@@ -87,9 +88,10 @@ func (h *Handler) HandleBackendCreate(w http.ResponseWriter, r *http.Request) {
 // @Param backendID path string true "Backend ID"
 // @Param request body models.BackendCopyModelRequest true "Copy model payload"
 // @Success 200 {object} models.BackendOperationResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/copy [post].
 func (h *Handler) HandleBackendCopy(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendProxyWithBody(w, r, http.MethodPost, "/api/copy", true)
@@ -104,9 +106,10 @@ func (h *Handler) HandleBackendCopy(w http.ResponseWriter, r *http.Request) {
 // @Param backendID path string true "Backend ID"
 // @Param request body models.BackendPullModelRequest true "Pull model payload"
 // @Success 200 {object} models.BackendOperationResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/pull [post].
 func (h *Handler) HandleBackendPull(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendProxyWithBody(w, r, http.MethodPost, "/api/pull", true)
@@ -121,9 +124,10 @@ func (h *Handler) HandleBackendPull(w http.ResponseWriter, r *http.Request) {
 // @Param backendID path string true "Backend ID"
 // @Param request body models.BackendPushModelRequest true "Push model payload"
 // @Success 200 {object} models.BackendOperationResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/push [post].
 func (h *Handler) HandleBackendPush(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendProxyWithBody(w, r, http.MethodPost, "/api/push", true)
@@ -138,9 +142,10 @@ func (h *Handler) HandleBackendPush(w http.ResponseWriter, r *http.Request) {
 // @Param backendID path string true "Backend ID"
 // @Param request body models.BackendDeleteModelRequest true "Delete model payload"
 // @Success 200 {object} models.BackendOperationResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/delete [delete].
 func (h *Handler) HandleBackendDelete(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendProxyWithBody(w, r, http.MethodDelete, "/api/delete", true)
@@ -155,9 +160,10 @@ func (h *Handler) HandleBackendDelete(w http.ResponseWriter, r *http.Request) {
 // @Param backendID path string true "Backend ID"
 // @Param request body models.BackendShowModelRequest true "Show model payload"
 // @Success 200 {object} models.BackendShowModelResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/show [post].
 func (h *Handler) HandleBackendShow(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendProxyWithBody(w, r, http.MethodPost, "/api/show", false)
@@ -170,8 +176,8 @@ func (h *Handler) HandleBackendShow(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param backendID path string true "Backend ID"
 // @Success 200 {object} models.BackendTagsResponse
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/tags [get].
 func (h *Handler) HandleBackendTags(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendGET(w, r, "/api/tags")
@@ -184,8 +190,8 @@ func (h *Handler) HandleBackendTags(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param backendID path string true "Backend ID"
 // @Success 200 {object} models.BackendVersionResponse
-// @Failure 404 {object} map[string]string
-// @Failure 502 {object} map[string]string
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /api/backends/{backendID}/version [get].
 func (h *Handler) HandleBackendVersion(w http.ResponseWriter, r *http.Request) {
 	h.handleBackendGET(w, r, "/api/version")
@@ -206,23 +212,31 @@ func (h *Handler) handleBackendProxyWithBody(
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusBadGateway, "failed to resolve backend")
 		}
 		return
 	}
-	body, err := h.readProxyPayload(r)
+	if !authorizeBackend(w, r, route) {
+		return
+	}
+	body, err := h.streamProxyPayload(w, r, roles.RouteGroupAdmin)
 	if err != nil {
 		h.writeProxyReadError(w, err)
 		return
 	}
+	defer body.Close()
 
 	ctx := requestctx.WithBackendID(r.Context(), backendID)
 	req := r.WithContext(ctx)
 
 	resp, err := h.proxyBackendWithBody(req, route, method, backendPath, body)
 	if err != nil {
+		if readErr := bodyReadError(err); readErr != err {
+			h.writeProxyReadError(w, readErr)
+			return
+		}
 		h.logger.ErrorContext(
 			req.Context(),
 			"proxy backend mutation",
@@ -238,6 +252,10 @@ func (h *Handler) handleBackendProxyWithBody(
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusBadRequest {
+		writeBackendError(w, resp)
+		return
+	}
 	copyHeaders(w.Header(), resp.Header)
 	stripHopHeaders(w.Header())
 	w.WriteHeader(resp.StatusCode)
@@ -245,10 +263,8 @@ func (h *Handler) handleBackendProxyWithBody(
 		h.logger.ErrorContext(req.Context(), "write backend mutation response", "backend_id", backendID, "err", copyErr)
 	}
 
-	if resp.StatusCode >= http.StatusOK && resp.StatusCode < 400 {
-		if sync {
-			h.enqueueSyncBackendByID(req.Context(), backendID)
-		}
+	if sync {
+		h.enqueueSyncBackendByID(req.Context(), backendID)
 	}
 }
 
@@ -263,12 +279,15 @@ func (h *Handler) handleBackendGET(w http.ResponseWriter, r *http.Request, backe
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusBadGateway, "failed to resolve backend")
 		}
 		return
 	}
+	if !authorizeBackend(w, r, route) {
+		return
+	}
 
 	ctx := requestctx.WithBackendID(r.Context(), backendID)
 	req := r.WithContext(ctx)
@@ -290,6 +309,10 @@ func (h *Handler) handleBackendGET(w http.ResponseWriter, r *http.Request, backe
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusBadRequest {
+		writeBackendError(w, resp)
+		return
+	}
 	copyHeaders(w.Header(), resp.Header)
 	stripHopHeaders(w.Header())
 	w.WriteHeader(resp.StatusCode)
@@ -302,7 +325,7 @@ func (h *Handler) proxyBackendWithBody(
 	r *http.Request,
 	route service.BackendRoute,
 	method, path string,
-	body []byte,
+	body io.Reader,
 ) (*http.Response, error) {
 	target := strings.TrimRight(route.Address, "/")
 	if !strings.HasPrefix(path, "/") {
@@ -314,10 +337,13 @@ func (h *Handler) proxyBackendWithBody(
 		target += "?" + raw
 	}
 
-	req, err := http.NewRequestWithContext(r.Context(), method, target, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(r.Context(), method, target, body)
 	if err != nil {
 		return nil, err
 	}
+	if req.ContentLength == 0 && r.ContentLength > 0 {
+		req.ContentLength = r.ContentLength
+	}
 	copyHeaders(req.Header, r.Header)
 	stripProxyHeaders(req.Header)
 	applyForwardHeaders(req, r)
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/service"
+	"github.com/rhajizada/llamero/internal/workers"
+)
+
+const (
+	defaultBatchListLimit = 20
+	maxBatchListLimit     = 100
+)
+
+// HandleCreateBatch godoc
+// @Summary Create a batch
+// @Description Validates an uploaded JSONL file and schedules its requests on the worker. Requests
+// @Description run at low priority with model profiles applied for the caller's role; results and
+// @Description errors are written to output files when the batch finishes.
+// @Tags Batches
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateBatchRequest true "Batch payload"
+// @Success 200 {object} models.Batch
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/batches [post]
+// @Router /v1/batches [post].
+func (h *Handler) HandleCreateBatch(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	inputFileID, err := uuid.Parse(strings.TrimSpace(req.InputFileID))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input_file_id")
+		return
+	}
+	scope, err := service.BatchEndpointScope(req.Endpoint)
+	if err != nil {
+		writeServiceError(w, err, "unsupported batch endpoint")
+		return
+	}
+	if !claims.HasScopes([]string{scope}) {
+		writeError(w, http.StatusForbidden, "insufficient scope for batch endpoint")
+		return
+	}
+
+	batch, err := h.svc.CreateBatch(r.Context(), service.CreateBatchParams{
+		UserID:           userID,
+		Role:             claims.Role,
+		InputFileID:      inputFileID,
+		Endpoint:         req.Endpoint,
+		CompletionWindow: req.CompletionWindow,
+		Metadata:         req.Metadata,
+		MaxRequests:      h.cfg.Batches.MaxRequests,
+	})
+	if err != nil {
+		writeServiceError(w, err, "failed to create batch")
+		return
+	}
+
+	if enqueueErr := h.enqueueBatch(r, batch); enqueueErr != nil {
+		h.logger.ErrorContext(r.Context(), "enqueue batch", "batch_id", batch.ID, "err", enqueueErr)
+		if batchID, parseErr := uuid.Parse(batch.ID); parseErr == nil {
+			_ = h.svc.FailBatch(r.Context(), batchID, "failed to schedule batch")
+		}
+		writeError(w, http.StatusInternalServerError, "failed to schedule batch")
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+// HandleListBatches godoc
+// @Summary List batches
+// @Tags Batches
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Maximum number of batches to return (1-100, default 20)"
+// @Success 200 {object} models.BatchList
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/batches [get]
+// @Router /v1/batches [get].
+func (h *Handler) HandleListBatches(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	limit := defaultBatchListLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxBatchListLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+	batches, err := h.svc.ListBatches(r.Context(), userID, int32(limit))
+	if err != nil {
+		writeServiceError(w, err, "failed to list batches")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.NewBatchList(batches))
+}
+
+// HandleGetBatch godoc
+// @Summary Get a batch
+// @Tags Batches
+// @Produce json
+// @Security BearerAuth
+// @Param batchID path string true "Batch ID"
+// @Success 200 {object} models.Batch
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/batches/{batchID} [get]
+// @Router /v1/batches/{batchID} [get].
+func (h *Handler) HandleGetBatch(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	batchID, ok := pathUUID(w, r, "batchID", "invalid batch id")
+	if !ok {
+		return
+	}
+	batch, err := h.svc.GetBatch(r.Context(), userID, batchID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load batch")
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+// HandleCancelBatch godoc
+// @Summary Cancel a batch
+// @Description The batch moves to cancelling; the worker stops dispatching requests and writes the
+// @Description results collected so far before marking it cancelled.
+// @Tags Batches
+// @Produce json
+// @Security BearerAuth
+// @Param batchID path string true "Batch ID"
+// @Success 200 {object} models.Batch
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/batches/{batchID}/cancel [post]
+// @Router /v1/batches/{batchID}/cancel [post].
+func (h *Handler) HandleCancelBatch(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	batchID, ok := pathUUID(w, r, "batchID", "invalid batch id")
+	if !ok {
+		return
+	}
+	batch, err := h.svc.CancelBatch(r.Context(), userID, batchID)
+	if err != nil {
+		writeServiceError(w, err, "failed to cancel batch")
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+func (h *Handler) enqueueBatch(r *http.Request, batch models.Batch) error {
+	task, err := workers.NewRunBatchTask(batch.ID, time.Unix(batch.ExpiresAt, 0))
+	if err != nil {
+		return err
+	}
+	_, err = h.tasks.EnqueueContext(r.Context(), task)
+	return err
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rhajizada/llamero/internal/middleware"
+	"github.com/rhajizada/llamero/internal/roles"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+// bodyTooLargeError reports that a request body exceeded the limit applicable to the caller.
+type bodyTooLargeError struct {
+	limit int64
+}
+
+func (e *bodyTooLargeError) Error() string {
+	return fmt.Sprintf("request body exceeds the %d byte limit", e.limit)
+}
+
+// bodyLimit resolves the request body limit for a route group, preferring the caller's role
+// override from roles.yaml over the global default.
+func (h *Handler) bodyLimit(r *http.Request, group string) int64 {
+	if claims, ok := middleware.ClaimsFromContext(r.Context()); ok {
+		if limit, found := h.roles.BodyLimit(claims.Role, group); found {
+			return limit
+		}
+	}
+	switch group {
+	case roles.RouteGroupEmbeddings:
+		return h.cfg.Proxy.MaxEmbeddingsBodyBytes
+	case roles.RouteGroupAdmin:
+		return h.cfg.Proxy.MaxAdminBodyBytes
+	default:
+		return h.cfg.Proxy.MaxLLMBodyBytes
+	}
+}
+
+// readProxyPayload buffers a request body that Llamero must inspect before routing.
+func (h *Handler) readProxyPayload(w http.ResponseWriter, r *http.Request, group string) ([]byte, error) {
+	defer r.Body.Close()
+	limit := h.bodyLimit(r, group)
+	if r.ContentLength > limit {
+		return nil, &bodyTooLargeError{limit: limit}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		return nil, bodyReadError(err)
+	}
+	return body, nil
+}
+
+// streamProxyPayload wraps a request body so it can be streamed to a backend without buffering.
+// Exceeding the limit surfaces as a bodyTooLargeError from the upstream call.
+func (h *Handler) streamProxyPayload(w http.ResponseWriter, r *http.Request, group string) (io.ReadCloser, error) {
+	limit := h.bodyLimit(r, group)
+	if r.ContentLength > limit {
+		return nil, &bodyTooLargeError{limit: limit}
+	}
+	return http.MaxBytesReader(w, r.Body, limit), nil
+}
+
+// bodyReadError converts http.MaxBytesError into a bodyTooLargeError.
+func bodyReadError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &bodyTooLargeError{limit: maxErr.Limit}
+	}
+	return err
+}
+
+func (h *Handler) writeProxyReadError(w http.ResponseWriter, err error) {
+	var tooLarge *bodyTooLargeError
+	if errors.As(err, &tooLarge) {
+		writeAppError(w, &service.Error{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    service.ErrorCodeRequestTooLarge,
+			Message: tooLarge.Error(),
+		})
+		return
+	}
+	writeError(w, http.StatusBadRequest, "unable to read request body")
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const (
+	contentPartImageURL   = "image_url"
+	contentPartInputImage = "input_image"
+)
+
+// proxyChatMessage captures the parts of a chat message needed to detect image inputs.
+type proxyChatMessage struct {
+	Content json.RawMessage   `json:"content"`
+	Images  []json.RawMessage `json:"images"`
+}
+
+func (m proxyChatMessage) hasImages() bool {
+	if len(m.Images) > 0 {
+		return true
+	}
+	content := bytes.TrimSpace(m.Content)
+	if len(content) == 0 || content[0] != '[' {
+		return false
+	}
+	var parts []struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return false
+	}
+	for _, part := range parts {
+		if part.Type == contentPartImageURL || part.Type == contentPartInputImage {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredChatCapabilities lists the model capabilities a chat request depends on.
+func requiredChatCapabilities(payload ChatCompletionProxyRequest) []string {
+	required := []string{service.CapabilityCompletion}
+	if len(payload.Tools) > 0 {
+		required = append(required, service.CapabilityTools)
+	}
+	for _, message := range payload.Messages {
+		if message.hasImages() {
+			required = append(required, service.CapabilityVision)
+			break
+		}
+	}
+	return required
+}
+
+// requiredCompletionCapabilities lists the model capabilities a legacy completion depends on.
+func requiredCompletionCapabilities(payload CompletionProxyRequest) []string {
+	required := []string{service.CapabilityCompletion}
+	if payload.Suffix != "" {
+		required = append(required, service.CapabilityInsert)
+	}
+	return required
+}
+
+// capabilityParam names the request field responsible for a capability requirement.
+func capabilityParam(capability string) string {
+	switch capability {
+	case service.CapabilityTools:
+		return "tools"
+	case service.CapabilityVision:
+		return "messages"
+	case service.CapabilityInsert:
+		return "suffix"
+	default:
+		return "model"
+	}
+}
+
+func writeCapabilityError(w http.ResponseWriter, err *service.CapabilityError) {
+	writeAppError(w, &service.Error{
+		Status:  http.StatusBadRequest,
+		Code:    service.ErrorCodeUnsupportedCapability,
+		Param:   capabilityParam(err.Capability),
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,472 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/requestctx"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const sseDoneData = "[DONE]"
+
+// chatChoices validates n and returns how many completions a chat request asks for.
+func (h *Handler) chatChoices(n *int) (int, error) {
+	if n == nil {
+		return 1, nil
+	}
+	if *n < 1 || *n > h.cfg.Choices.Max {
+		return 0, &service.Error{
+			Status:  http.StatusBadRequest,
+			Param:   "n",
+			Message: fmt.Sprintf("n must be between 1 and %d", h.cfg.Choices.Max),
+		}
+	}
+	return *n, nil
+}
+
+// fanOutChoices serves a chat request with n > 1. Ollama ignores n, so the request is sent n times
+// as a single-choice request, spread across every backend able to serve the model, and the
+// responses are merged: choices are indexed by request and usage counts the prompt once.
+func (h *Handler) fanOutChoices(
+	w http.ResponseWriter,
+	r *http.Request,
+	call llmCall,
+	native *ollamanative.Options,
+) {
+	routes, err := h.svc.RouteBackends(r.Context(), call.model, call.required...)
+	if err != nil {
+		h.handleRoutingError(w, err)
+		return
+	}
+	body, stream, err := singleChoiceBody(call.body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return
+	}
+	assigned := make([]service.BackendRoute, call.choices)
+	backendIDs := make([]string, 0, len(routes))
+	for i := range assigned {
+		assigned[i] = routes[i%len(routes)]
+		backendIDs = appendUnique(backendIDs, assigned[i].ID)
+	}
+	ctx := requestctx.WithBackendID(r.Context(), strings.Join(backendIDs, ","))
+
+	if stream {
+		h.streamChoices(w, r.WithContext(ctx), assigned, body, native)
+		return
+	}
+	h.mergeChoices(w, r.WithContext(ctx), assigned, body, native)
+}
+
+// singleChoiceBody drops n from a chat request and reports whether it streams.
+func singleChoiceBody(body []byte) ([]byte, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false, err
+	}
+	delete(fields, "n")
+	var stream bool
+	if raw, ok := fields["stream"]; ok {
+		_ = json.Unmarshal(raw, &stream)
+	}
+	single, err := json.Marshal(fields)
+	return single, stream, err
+}
+
+// mergeChoices sends the single-choice requests in parallel and merges their completions. The
+// first failure cancels the remaining requests and is returned to the caller.
+func (h *Handler) mergeChoices(
+	w http.ResponseWriter,
+	r *http.Request,
+	routes []service.BackendRoute,
+	body []byte,
+	native *ollamanative.Options,
+) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	req := r.WithContext(ctx)
+
+	recorders := make([]*responseRecorder, len(routes))
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		failure *responseRecorder
+	)
+	for i, route := range routes {
+		recorders[i] = &responseRecorder{header: http.Header{}}
+		wg.Go(func() {
+			h.forwardToRoute(recorders[i], req, route, body, native)
+			if recorders[i].status != http.StatusOK {
+				once.Do(func() {
+					failure = recorders[i]
+					cancel()
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	if failure != nil {
+		failure.replay(w)
+		return
+	}
+	bodies := make([][]byte, len(recorders))
+	for i, recorder := range recorders {
+		bodies[i] = recorder.body.Bytes()
+	}
+	merged, err := mergeChatCompletions(bodies)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "merge chat choices", "err", err)
+		writeError(w, http.StatusBadGateway, "invalid backend response")
+		return
+	}
+	writeJSON(w, http.StatusOK, merged)
+}
+
+// mergeChatCompletions combines single-choice chat completions into one response. Choices take
+// the position of their request; prompt tokens are counted once, as for an n > 1 request.
+func mergeChatCompletions(bodies [][]byte) (map[string]any, error) {
+	var (
+		merged  map[string]any
+		choices []any
+		usage   choiceUsage
+	)
+	for i, body := range bodies {
+		doc, err := decodeChoiceDocument(body)
+		if err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = doc
+		}
+		choices = append(choices, indexChoices(doc, i)...)
+		if err = usage.add(doc); err != nil {
+			return nil, err
+		}
+	}
+	merged["choices"] = choices
+	if usage.seen {
+		merged["usage"] = usage.total()
+	}
+	return merged, nil
+}
+
+// streamChoices sends the single-choice requests in parallel and interleaves their event streams
+// as chunks arrive, renumbering each chunk's choice to its request. Usage is reported in a single
+// chunk before [DONE], which is only sent once every stream has completed.
+func (h *Handler) streamChoices(
+	w http.ResponseWriter,
+	r *http.Request,
+	routes []service.BackendRoute,
+	body []byte,
+	native *ollamanative.Options,
+) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	req := r.WithContext(ctx)
+
+	events := make(chan choiceEvent)
+	var wg sync.WaitGroup
+	for i, route := range routes {
+		wg.Go(func() {
+			sw := &choiceStreamWriter{index: i, header: http.Header{}, events: events}
+			h.forwardToRoute(sw, req, route, body, native)
+			sw.close()
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	merger := &choiceMerger{w: w, controller: http.NewResponseController(w)}
+	for event := range events {
+		if !merger.handle(event) {
+			cancel()
+		}
+	}
+	merger.finish()
+	if merger.err != nil && !merger.failed {
+		h.logger.ErrorContext(r.Context(), "merge chat choice stream", "err", merger.err)
+	}
+}
+
+// choiceEvent is a stream chunk, or the outcome of a stream, for one choice.
+type choiceEvent struct {
+	index int
+	data  []byte
+	// failure holds a response that never started streaming.
+	failure *responseRecorder
+	// incomplete reports a stream that ended without [DONE].
+	incomplete bool
+}
+
+// choiceStreamWriter splits the event stream of one single-choice request into chunks. Responses
+// that are not event streams, such as errors, are recorded and reported when the request ends.
+type choiceStreamWriter struct {
+	index     int
+	header    http.Header
+	events    chan<- choiceEvent
+	status    int
+	streaming bool
+	done      bool
+	buf       []byte
+	recorded  *responseRecorder
+}
+
+func (c *choiceStreamWriter) Header() http.Header {
+	return c.header
+}
+
+func (c *choiceStreamWriter) WriteHeader(status int) {
+	if c.status != 0 {
+		return
+	}
+	c.status = status
+	c.streaming = status == http.StatusOK &&
+		strings.HasPrefix(c.header.Get("Content-Type"), contentTypeEventStream)
+	if !c.streaming {
+		c.recorded = &responseRecorder{header: c.header, status: status}
+	}
+}
+
+func (c *choiceStreamWriter) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.WriteHeader(http.StatusOK)
+	}
+	if !c.streaming {
+		return c.recorded.Write(p)
+	}
+	c.buf = append(c.buf, p...)
+	for {
+		end := bytes.Index(c.buf, []byte("\n\n"))
+		if end < 0 {
+			break
+		}
+		c.emit(c.buf[:end])
+		c.buf = c.buf[end+len("\n\n"):]
+	}
+	return len(p), nil
+}
+
+func (c *choiceStreamWriter) emit(event []byte) {
+	data, ok := bytes.CutPrefix(bytes.TrimSpace(event), []byte("data:"))
+	if !ok {
+		return
+	}
+	data = bytes.TrimSpace(data)
+	if string(data) == sseDoneData {
+		c.done = true
+		return
+	}
+	c.events <- choiceEvent{index: c.index, data: bytes.Clone(data)}
+}
+
+func (c *choiceStreamWriter) close() {
+	switch {
+	case !c.streaming:
+		if c.recorded == nil {
+			c.recorded = &responseRecorder{header: c.header, status: http.StatusBadGateway}
+		}
+		c.events <- choiceEvent{index: c.index, failure: c.recorded}
+	case !c.done:
+		c.events <- choiceEvent{index: c.index, incomplete: true}
+	}
+}
+
+// choiceMerger writes interleaved choice chunks to the caller.
+type choiceMerger struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	started    bool
+	failed     bool
+	id         string
+	usage      choiceUsage
+	usageChunk map[string]any
+	err        error
+}
+
+// handle writes one event and reports whether the fan-out should continue.
+func (m *choiceMerger) handle(event choiceEvent) bool {
+	if m.failed {
+		return false
+	}
+	switch {
+	case event.failure != nil:
+		m.failed = true
+		if !m.started {
+			event.failure.replay(m.w)
+			return false
+		}
+		m.writeEvent(bytes.TrimSpace(event.failure.body.Bytes()))
+		return false
+	case event.incomplete:
+		m.failed = true
+		return false
+	}
+
+	doc, err := decodeChoiceDocument(event.data)
+	if err != nil {
+		m.err = err
+		return true
+	}
+	if _, isError := doc["error"]; isError {
+		m.failed = true
+		m.writeEvent(event.data)
+		return false
+	}
+	choices := indexChoices(doc, event.index)
+	if len(choices) == 0 && doc["usage"] != nil {
+		if m.err = m.usage.add(doc); m.err == nil {
+			m.usageChunk = doc
+		}
+		return true
+	}
+	if id, ok := doc["id"].(string); ok && m.id == "" {
+		m.id = id
+	}
+	if m.id != "" {
+		doc["id"] = m.id
+	}
+	doc["choices"] = choices
+	m.writeChunk(doc)
+	return true
+}
+
+// finish sends the aggregated usage and [DONE] once every stream has completed.
+func (m *choiceMerger) finish() {
+	if m.failed {
+		return
+	}
+	if m.usageChunk != nil {
+		m.usageChunk["usage"] = m.usage.total()
+		if m.id != "" {
+			m.usageChunk["id"] = m.id
+		}
+		m.writeChunk(m.usageChunk)
+	}
+	m.writeEvent([]byte(sseDoneData))
+}
+
+func (m *choiceMerger) writeChunk(doc map[string]any) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.writeEvent(data)
+}
+
+func (m *choiceMerger) writeEvent(data []byte) {
+	if !m.started {
+		m.started = true
+		m.w.Header().Set("Content-Type", contentTypeEventStream)
+		m.w.Header().Set("Cache-Control", "no-cache")
+		m.w.WriteHeader(http.StatusOK)
+	}
+	_, _ = fmt.Fprintf(m.w, "data: %s\n\n", data)
+	_ = m.controller.Flush()
+}
+
+// choiceUsage adds up the usage of single-choice responses.
+type choiceUsage struct {
+	seen  bool
+	usage models.ChatCompletionUsage
+}
+
+func (u *choiceUsage) add(doc map[string]any) error {
+	raw, ok := doc["usage"]
+	if !ok || raw == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var usage models.ChatCompletionUsage
+	if err = json.Unmarshal(encoded, &usage); err != nil {
+		return err
+	}
+	if !u.seen {
+		u.usage.PromptTokens = usage.PromptTokens
+	}
+	u.seen = true
+	u.usage.CompletionTokens += usage.CompletionTokens
+	return nil
+}
+
+func (u *choiceUsage) total() models.ChatCompletionUsage {
+	usage := u.usage
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
+
+// indexChoices renumbers the choices of a response to index.
+func indexChoices(doc map[string]any, index int) []any {
+	choices, _ := doc["choices"].([]any)
+	for _, choice := range choices {
+		if object, ok := choice.(map[string]any); ok {
+			object["index"] = index
+		}
+	}
+	return choices
+}
+
+func decodeChoiceDocument(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errInvalidPayload
+	}
+	return doc, nil
+}
+
+// responseRecorder keeps a whole response in memory.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) Header() http.Header {
+	return rr.header
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	return rr.body.Write(p)
+}
+
+// replay writes the recorded response to w.
+func (rr *responseRecorder) replay(w http.ResponseWriter) {
+	if contentType := rr.header.Get("Content-Type"); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	status := rr.status
+	if status == 0 {
+		status = http.StatusBadGateway
+	}
+	w.WriteHeader(status)
+	_, _ = io.Copy(w, &rr.body)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/rhajizada/llamero/internal/contextwindow"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const contextDroppedHeader = "X-Llamero-Context-Dropped"
+
+// fitContextWindow applies the context policy of the model's profile to a chat request. The window
+// is num_ctx when set and otherwise the context length backends report for the model; requests
+// for models with no known window pass through. When a policy applies, the number of dropped
+// messages is reported in X-Llamero-Context-Dropped.
+func (h *Handler) fitContextWindow(
+	w http.ResponseWriter,
+	r *http.Request,
+	model string,
+	body []byte,
+	runtime profiles.Runtime,
+) ([]byte, error) {
+	profile, ok := h.profiles.Match(model)
+	if !ok {
+		return body, nil
+	}
+	policy, ok := profile.ContextPolicy()
+	if !ok {
+		return body, nil
+	}
+	window := runtime.NumCtx
+	if window == 0 {
+		length, err := h.svc.ModelContextLength(r.Context(), model)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "load model context length", "model", model, "err", err)
+		}
+		window = length
+	}
+	if window == 0 {
+		return body, nil
+	}
+
+	var req profiles.Request
+	if err := json.Unmarshal(body, &req); err != nil || req == nil {
+		return nil, errInvalidPayload
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(req["messages"], &messages); err != nil {
+		return nil, errInvalidPayload
+	}
+	reserve := profile.Context.Reserve
+	var maxTokens int
+	if raw, found := req["max_tokens"]; found && json.Unmarshal(raw, &maxTokens) == nil && maxTokens > 0 {
+		reserve = maxTokens
+	}
+
+	kept, dropped, err := policy.Fit(messages, max(window-reserve, 0))
+	if err != nil {
+		return nil, err
+	}
+	w.Header().Set(contextDroppedHeader, strconv.Itoa(dropped))
+	if dropped == 0 {
+		return body, nil
+	}
+	if req["messages"], err = json.Marshal(kept); err != nil {
+		return nil, err
+	}
+	return json.Marshal(req)
+}
+
+func writeContextWindowError(w http.ResponseWriter, err error) {
+	var exceeded *contextwindow.ExceededError
+	if errors.As(err, &exceeded) {
+		writeAppError(w, &service.Error{
+			Status:  http.StatusBadRequest,
+			Code:    service.ErrorCodeContextLengthExceeded,
+			Param:   "messages",
+			Message: exceeded.Error(),
+		})
+		return
+	}
+	writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+}
@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/requestctx"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+// embeddingChunk is a contiguous slice of the caller's input array, encoded as a full request body.
+type embeddingChunk struct {
+	offset int
+	body   []byte
+}
+
+// embeddingChunkResult holds a decoded backend response for a single chunk.
+type embeddingChunkResult struct {
+	backendID string
+	response  embeddingsListResponse
+}
+
+// embeddingsListResponse mirrors models.EmbeddingsResponse but keeps vectors raw so that
+// base64 encoded embeddings survive reassembly untouched.
+type embeddingsListResponse struct {
+	Object string                  `json:"object"`
+	Model  string                  `json:"model"`
+	Data   []embeddingsListItem    `json:"data"`
+	Usage  *models.EmbeddingsUsage `json:"usage,omitempty"`
+}
+
+type embeddingsListItem struct {
+	Object    string          `json:"object"`
+	Embedding json.RawMessage `json:"embedding"`
+	Index     int             `json:"index"`
+}
+
+// upstreamResponseError carries a non-retryable backend response back to the caller.
+type upstreamResponseError struct {
+	status int
+	body   []byte
+}
+
+func (e *upstreamResponseError) Error() string {
+	return fmt.Sprintf("backend returned status %d", e.status)
+}
+
+// splitEmbeddingInputs returns the individual inputs when the payload carries a batch of
+// strings or token arrays. Single strings and single token arrays are not splittable.
+func splitEmbeddingInputs(raw json.RawMessage) ([]json.RawMessage, bool) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil || len(items) == 0 {
+		return nil, false
+	}
+	first := bytes.TrimSpace(items[0])
+	if len(first) == 0 || (first[0] != '"' && first[0] != '[') {
+		return nil, false
+	}
+	return items, true
+}
+
+func buildEmbeddingChunks(body []byte, inputs []json.RawMessage, batchSize int) ([]embeddingChunk, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	chunks := make([]embeddingChunk, 0, (len(inputs)+batchSize-1)/batchSize)
+	for offset := 0; offset < len(inputs); offset += batchSize {
+		end := min(offset+batchSize, len(inputs))
+		input, err := json.Marshal(inputs[offset:end])
+		if err != nil {
+			return nil, err
+		}
+		fields["input"] = input
+		chunkBody, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, embeddingChunk{offset: offset, body: chunkBody})
+	}
+	return chunks, nil
+}
+
+// fanOutEmbeddings splits a large batch into chunks, dispatches them in parallel across every
+// backend able to serve the model and reassembles the vectors in their original order.
+func (h *Handler) fanOutEmbeddings(
+	w http.ResponseWriter,
+	r *http.Request,
+	model string,
+	body []byte,
+	inputs []json.RawMessage,
+) {
+	merged, err := h.collectEmbeddings(r, model, body, inputs)
+	if err != nil {
+		h.writeEmbeddingsError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, merged)
+}
+
+// collectEmbeddings embeds inputs in batches across the backends serving the model and returns
+// the merged response, with indexes relative to inputs.
+func (h *Handler) collectEmbeddings(
+	r *http.Request,
+	model string,
+	body []byte,
+	inputs []json.RawMessage,
+) (embeddingsListResponse, error) {
+	routes, err := h.svc.RouteBackends(r.Context(), model, service.CapabilityEmbedding)
+	if err != nil {
+		return embeddingsListResponse{}, &routingError{err: err}
+	}
+	chunks, err := buildEmbeddingChunks(body, inputs, h.cfg.Embeddings.BatchSize)
+	if err != nil {
+		return embeddingsListResponse{}, errInvalidPayload
+	}
+
+	results, err := h.dispatchEmbeddingChunks(r, routes, chunks)
+	if err != nil {
+		return embeddingsListResponse{}, err
+	}
+
+	merged := embeddingsListResponse{Object: "list", Usage: &models.EmbeddingsUsage{}}
+	backendIDs := make([]string, 0, len(routes))
+	for i, result := range results {
+		if merged.Model == "" {
+			merged.Model = result.response.Model
+		}
+		for _, item := range result.response.Data {
+			item.Index += chunks[i].offset
+			merged.Data = append(merged.Data, item)
+		}
+		if usage := result.response.Usage; usage != nil {
+			merged.Usage.PromptTokens += usage.PromptTokens
+			merged.Usage.TotalTokens += usage.TotalTokens
+		}
+		backendIDs = appendUnique(backendIDs, result.backendID)
+	}
+	sort.SliceStable(merged.Data, func(i, j int) bool {
+		return merged.Data[i].Index < merged.Data[j].Index
+	})
+
+	requestctx.SetBackendID(r.Context(), strings.Join(backendIDs, ","))
+	return merged, nil
+}
+
+func (h *Handler) dispatchEmbeddingChunks(
+	r *http.Request,
+	routes []service.BackendRoute,
+	chunks []embeddingChunk,
+) ([]embeddingChunkResult, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	req := r.WithContext(ctx)
+
+	parallel := max(h.cfg.Embeddings.MaxParallel, 1)
+	sem := make(chan struct{}, parallel)
+	results := make([]embeddingChunkResult, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Go(func() {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = h.sendEmbeddingChunk(req, routes, i, chunk)
+			if errs[i] != nil {
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+
+	return results, firstFanOutError(errs)
+}
+
+// sendEmbeddingChunk posts a chunk to its assigned backend and retries retryable failures on
+// the next candidate backend.
+func (h *Handler) sendEmbeddingChunk(
+	r *http.Request,
+	routes []service.BackendRoute,
+	position int,
+	chunk embeddingChunk,
+) (embeddingChunkResult, error) {
+	attempts := max(h.cfg.Embeddings.MaxAttempts, 1)
+	var lastErr error
+	for attempt := range attempts {
+		route := routes[(position+attempt)%len(routes)]
+		result, err := h.tryEmbeddingChunk(r, route, chunk)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+		var upstreamErr *upstreamResponseError
+		if errors.As(err, &upstreamErr) && !retryableStatus(upstreamErr.status) {
+			return embeddingChunkResult{}, err
+		}
+		if r.Context().Err() != nil {
+			return embeddingChunkResult{}, r.Context().Err()
+		}
+		h.logger.WarnContext(
+			r.Context(),
+			"embeddings chunk failed",
+			"backend_id",
+			route.ID,
+			"offset",
+			chunk.offset,
+			"attempt",
+			attempt+1,
+			"err",
+			err,
+		)
+	}
+	return embeddingChunkResult{}, lastErr
+}
+
+func (h *Handler) tryEmbeddingChunk(
+	r *http.Request,
+	route service.BackendRoute,
+	chunk embeddingChunk,
+) (embeddingChunkResult, error) {
+	resp, err := h.proxyToBackend(r, route, chunk.body)
+	if err != nil {
+		return embeddingChunkResult{}, err
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return embeddingChunkResult{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return embeddingChunkResult{}, &upstreamResponseError{
+			status: resp.StatusCode,
+			body:   payload,
+		}
+	}
+
+	var decoded embeddingsListResponse
+	if decodeErr := json.Unmarshal(payload, &decoded); decodeErr != nil {
+		return embeddingChunkResult{}, fmt.Errorf("decode embeddings response: %w", decodeErr)
+	}
+	return embeddingChunkResult{backendID: route.ID, response: decoded}, nil
+}
+
+// routingError marks failures to select a backend so they are reported like single requests.
+type routingError struct {
+	err error
+}
+
+func (e *routingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *routingError) Unwrap() error {
+	return e.err
+}
+
+func (h *Handler) writeEmbeddingsError(w http.ResponseWriter, r *http.Request, err error) {
+	var routeErr *routingError
+	if errors.As(err, &routeErr) {
+		h.handleRoutingError(w, routeErr.err)
+		return
+	}
+	if errors.Is(err, errInvalidPayload) {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return
+	}
+	var upstreamErr *upstreamResponseError
+	if errors.As(err, &upstreamErr) && !retryableStatus(upstreamErr.status) {
+		appErr := service.BackendError(upstreamErr.status, ollamanative.ErrorMessage(upstreamErr.body))
+		writeAppError(w, appErr)
+		return
+	}
+	h.logger.ErrorContext(r.Context(), "embeddings fan-out failed", "err", err)
+	writeError(w, http.StatusBadGateway, "backend request failed")
+}
+
+// firstFanOutError prefers the root cause over cancellations triggered by it.
+func firstFanOutError(errs []error) error {
+	var cancelled error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, context.Canceled) {
+			cancelled = err
+			continue
+		}
+		return err
+	}
+	return cancelled
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/rhajizada/llamero/internal/models"
+)
+
+const (
+	responseCachePartial = "PARTIAL"
+
+	embeddingObject       = "embedding"
+	defaultEncodingFormat = "float"
+)
+
+var errIncompleteEmbeddings = errors.New("backend returned fewer embeddings than requested")
+
+// serveCachedEmbeddings answers an embeddings request from the vector cache, sending only the
+// cache misses to backends. It reports false when the request cannot use the cache, leaving the
+// caller to proxy it as usual.
+func (h *Handler) serveCachedEmbeddings(
+	w http.ResponseWriter,
+	r *http.Request,
+	payload EmbeddingsProxyRequest,
+	body []byte,
+) bool {
+	if h.cfg.Embeddings.CacheTTL <= 0 {
+		return false
+	}
+	texts, ok := embeddingTexts(payload.Input)
+	if !ok {
+		return false
+	}
+	digest, ok, err := h.svc.ModelDigest(r.Context(), payload.Model)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "resolve embedding digest", "err", err)
+	}
+	if !ok {
+		return false
+	}
+
+	variant := embeddingVariant(body)
+	hashes := make([]string, len(texts))
+	for i, text := range texts {
+		sum := sha256.Sum256([]byte(text))
+		hashes[i] = variant + ":" + hex.EncodeToString(sum[:])
+	}
+
+	vectors := make([]json.RawMessage, len(texts))
+	if !bypassResponseCache(r) {
+		cached, lookupErr := h.svc.CachedEmbeddings(r.Context(), payload.Model, digest, hashes)
+		if lookupErr != nil {
+			h.logger.WarnContext(r.Context(), "read embedding cache", "err", lookupErr)
+		} else {
+			vectors = cached
+		}
+	}
+
+	merged, hits, err := h.fillEmbeddingMisses(r, payload.Model, digest, body, texts, hashes, vectors)
+	if err != nil {
+		h.writeEmbeddingsError(w, r, err)
+		return true
+	}
+
+	switch {
+	case bypassResponseCache(r):
+		w.Header().Set(responseCacheHeader, responseCacheBypass)
+	case hits == len(texts):
+		w.Header().Set(responseCacheHeader, responseCacheHit)
+	case hits == 0:
+		w.Header().Set(responseCacheHeader, responseCacheMiss)
+	default:
+		w.Header().Set(responseCacheHeader, responseCachePartial)
+	}
+	writeJSON(w, http.StatusOK, merged)
+	return true
+}
+
+// fillEmbeddingMisses embeds the inputs without a cached vector, stores the new vectors and
+// returns the full response in input order along with the number of cache hits.
+func (h *Handler) fillEmbeddingMisses(
+	r *http.Request,
+	model, digest string,
+	body []byte,
+	texts, hashes []string,
+	vectors []json.RawMessage,
+) (embeddingsListResponse, int, error) {
+	merged := embeddingsListResponse{Object: "list", Model: model, Usage: &models.EmbeddingsUsage{}}
+
+	var (
+		missInputs    []json.RawMessage
+		missPositions []int
+	)
+	for i, vector := range vectors {
+		if vector != nil {
+			continue
+		}
+		input, err := json.Marshal(texts[i])
+		if err != nil {
+			return embeddingsListResponse{}, 0, err
+		}
+		missInputs = append(missInputs, input)
+		missPositions = append(missPositions, i)
+	}
+	hits := len(texts) - len(missInputs)
+
+	if len(missInputs) > 0 {
+		fresh, err := h.collectEmbeddings(r, model, body, missInputs)
+		if err != nil {
+			return embeddingsListResponse{}, 0, err
+		}
+		merged.Model = fresh.Model
+		merged.Usage = fresh.Usage
+
+		store := make(map[string]json.RawMessage, len(fresh.Data))
+		for _, item := range fresh.Data {
+			if item.Index < 0 || item.Index >= len(missPositions) {
+				continue
+			}
+			position := missPositions[item.Index]
+			vectors[position] = item.Embedding
+			store[hashes[position]] = item.Embedding
+		}
+		ttl := h.cfg.Embeddings.CacheTTL
+		if saveErr := h.svc.CacheEmbeddings(r.Context(), model, digest, store, ttl); saveErr != nil {
+			h.logger.WarnContext(r.Context(), "write embedding cache", "err", saveErr)
+		}
+	}
+
+	for i, vector := range vectors {
+		if vector == nil {
+			return embeddingsListResponse{}, 0, errIncompleteEmbeddings
+		}
+		merged.Data = append(merged.Data, embeddingsListItem{Object: embeddingObject, Embedding: vector, Index: i})
+	}
+	return merged, hits, nil
+}
+
+// embeddingTexts returns the request input as a list of strings. Token array inputs are not cached.
+func embeddingTexts(raw json.RawMessage) ([]string, bool) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil, false
+	}
+	var single string
+	if trimmed[0] == '"' {
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, false
+		}
+		return []string{single}, true
+	}
+	var texts []string
+	if err := json.Unmarshal(trimmed, &texts); err != nil || len(texts) == 0 {
+		return nil, false
+	}
+	return texts, true
+}
+
+// embeddingVariant distinguishes vectors of the same input that differ in encoding or dimensions.
+func embeddingVariant(body []byte) string {
+	var options struct {
+		EncodingFormat string `json:"encoding_format"`
+		Dimensions     int    `json:"dimensions"`
+	}
+	_ = json.Unmarshal(body, &options)
+	variant := options.EncodingFormat
+	if variant == "" {
+		variant = defaultEncodingFormat
+	}
+	if options.Dimensions > 0 {
+		variant += "-d" + strconv.Itoa(options.Dimensions)
+	}
+	return variant
+}
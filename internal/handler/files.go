@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const (
+	// multipartMemoryBytes caps the part of an upload kept in memory; the rest spills to disk.
+	multipartMemoryBytes = 32 << 20
+	// multipartOverheadBytes allows for form boundaries and fields around the uploaded file.
+	multipartOverheadBytes = 1 << 20
+
+	jsonlContentType = "application/jsonl"
+)
+
+// HandleUploadFile godoc
+// @Summary Upload a file
+// @Description Uploads a JSONL file of batch requests. Every line must carry a unique custom_id,
+// @Description method POST, a supported url and a body with a model.
+// @Tags Files
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "JSONL file"
+// @Param purpose formData string true "File purpose" Enums(batch)
+// @Success 200 {object} models.File
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/files [post]
+// @Router /v1/files [post].
+func (h *Handler) HandleUploadFile(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+
+	limit := h.cfg.Files.MaxBytes
+	r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverheadBytes)
+	if err := r.ParseMultipartForm(multipartMemoryBytes); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			h.writeProxyReadError(w, &bodyTooLargeError{limit: limit})
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid multipart form")
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+	if header.Size > limit {
+		h.writeProxyReadError(w, &bodyTooLargeError{limit: limit})
+		return
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "unable to read file")
+		return
+	}
+
+	uploaded, err := h.svc.UploadFile(r.Context(), service.UploadFileParams{
+		UserID:   userID,
+		Filename: header.Filename,
+		Purpose:  strings.TrimSpace(r.FormValue("purpose")),
+		Content:  content,
+	})
+	if err != nil {
+		writeServiceError(w, err, "failed to store file")
+		return
+	}
+	writeJSON(w, http.StatusOK, uploaded)
+}
+
+// HandleListFiles godoc
+// @Summary List files
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.FileList
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/files [get]
+// @Router /v1/files [get].
+func (h *Handler) HandleListFiles(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	files, err := h.svc.ListFiles(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list files")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.NewFileList(files))
+}
+
+// HandleGetFile godoc
+// @Summary Get file metadata
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param fileID path string true "File ID"
+// @Success 200 {object} models.File
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/files/{fileID} [get]
+// @Router /v1/files/{fileID} [get].
+func (h *Handler) HandleGetFile(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	fileID, ok := pathUUID(w, r, "fileID", "invalid file id")
+	if !ok {
+		return
+	}
+	file, err := h.svc.GetFile(r.Context(), userID, fileID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load file")
+		return
+	}
+	writeJSON(w, http.StatusOK, file)
+}
+
+// HandleGetFileContent godoc
+// @Summary Download file contents
+// @Tags Files
+// @Produce application/jsonl
+// @Security BearerAuth
+// @Param fileID path string true "File ID"
+// @Success 200 {string} string "JSONL contents"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/files/{fileID}/content [get]
+// @Router /v1/files/{fileID}/content [get].
+func (h *Handler) HandleGetFileContent(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	fileID, ok := pathUUID(w, r, "fileID", "invalid file id")
+	if !ok {
+		return
+	}
+	content, err := h.svc.GetFileContent(r.Context(), userID, fileID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load file")
+		return
+	}
+	w.Header().Set("Content-Type", jsonlContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, writeErr := w.Write(content); writeErr != nil {
+		h.logger.ErrorContext(r.Context(), "write file content", "file_id", fileID, "err", writeErr)
+	}
+}
+
+// HandleDeleteFile godoc
+// @Summary Delete a file
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param fileID path string true "File ID"
+// @Success 200 {object} models.FileDeleteResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/files/{fileID} [delete]
+// @Router /v1/files/{fileID} [delete].
+func (h *Handler) HandleDeleteFile(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	fileID, ok := pathUUID(w, r, "fileID", "invalid file id")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteFile(r.Context(), userID, fileID); err != nil {
+		writeServiceError(w, err, "failed to delete file")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.NewFileDeleteResponse(fileID))
+}
+
+func pathUUID(w http.ResponseWriter, r *http.Request, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(strings.TrimSpace(r.PathValue(name)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
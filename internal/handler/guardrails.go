@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/rhajizada/llamero/internal/guardrails"
+	"github.com/rhajizada/llamero/internal/middleware"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const contentTypeJSON = "application/json"
+
+var errStreamBlocked = errors.New("stream blocked by guardrail")
+
+// guardMeta describes the caller and model for guardrail filters.
+func guardMeta(r *http.Request, model string) guardrails.Meta {
+	meta := guardrails.Meta{Model: model}
+	if claims, ok := middleware.ClaimsFromContext(r.Context()); ok {
+		meta.Role = claims.Role
+		meta.Subject = claims.Subject
+	}
+	return meta
+}
+
+// guardRequest runs the pre filters over a chat or completion request body.
+func (h *Handler) guardRequest(r *http.Request, model string, body []byte) ([]byte, error) {
+	return h.guards.CheckRequest(r.Context(), body, guardMeta(r, model))
+}
+
+func (h *Handler) writeGuardrailError(w http.ResponseWriter, r *http.Request, err error) {
+	var violation *guardrails.Violation
+	if errors.As(err, &violation) {
+		h.logger.InfoContext(r.Context(), "guardrail blocked llm exchange", "filter", violation.Filter)
+		writeAppError(w, guardrailAppError(violation))
+		return
+	}
+	h.logger.ErrorContext(r.Context(), "guardrail check failed", "err", err)
+	writeError(w, http.StatusBadGateway, "guardrail check failed")
+}
+
+func guardrailAppError(violation *guardrails.Violation) *service.Error {
+	return &service.Error{
+		Status:  http.StatusBadRequest,
+		Code:    service.ErrorCodeContentFilter,
+		Message: violation.Error(),
+	}
+}
+
+// guardWriter applies the guardrail post filters to an LLM response. JSON completions are held
+// until the handler returns; event streams are filtered as they are written and terminated with
+// an error event when a filter trips. Other responses, including errors, pass through.
+type guardWriter struct {
+	http.ResponseWriter
+
+	h           *Handler
+	r           *http.Request
+	meta        guardrails.Meta
+	status      int
+	wroteHeader bool
+	buffered    bool
+	body        bytes.Buffer
+	stream      *guardrails.StreamWriter
+	blocked     bool
+}
+
+func (h *Handler) newGuardWriter(w http.ResponseWriter, r *http.Request, model string) *guardWriter {
+	return &guardWriter{ResponseWriter: w, h: h, r: r, meta: guardMeta(r, model)}
+}
+
+func (g *guardWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	g.status = status
+	contentType := g.Header().Get("Content-Type")
+	if status == http.StatusOK {
+		switch {
+		case strings.HasPrefix(contentType, contentTypeEventStream):
+			g.stream = g.h.guards.NewStreamWriter(g.r.Context(), g.ResponseWriter, g.meta)
+		case strings.HasPrefix(contentType, contentTypeJSON):
+			g.buffered = true
+			return
+		}
+	}
+	g.ResponseWriter.WriteHeader(status)
+}
+
+func (g *guardWriter) Write(p []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case g.blocked:
+		return 0, errStreamBlocked
+	case g.buffered:
+		return g.body.Write(p)
+	case g.stream != nil:
+		if _, err := g.stream.Write(p); err != nil {
+			g.terminate(err)
+			return 0, errStreamBlocked
+		}
+		return len(p), nil
+	default:
+		return g.ResponseWriter.Write(p)
+	}
+}
+
+// Unwrap exposes the underlying writer so streamed responses can still be flushed.
+func (g *guardWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// finish filters a held JSON completion and releases text still buffered from a stream.
+func (g *guardWriter) finish() {
+	switch {
+	case g.buffered:
+		g.Header().Del("Content-Length")
+		body, err := g.h.guards.CheckResponse(g.r.Context(), g.body.Bytes(), g.meta)
+		if err != nil {
+			g.h.writeGuardrailError(g.ResponseWriter, g.r, err)
+			return
+		}
+		g.ResponseWriter.WriteHeader(g.status)
+		_, _ = g.ResponseWriter.Write(body)
+	case g.stream != nil && !g.blocked:
+		if err := g.stream.Close(); err != nil {
+			g.terminate(err)
+		}
+	}
+}
+
+// terminate ends a stream with an error event. No [DONE] marker follows, so clients and the
+// response cache treat the stream as failed.
+func (g *guardWriter) terminate(err error) {
+	g.blocked = true
+	var violation *guardrails.Violation
+	if !errors.As(err, &violation) {
+		g.h.logger.ErrorContext(g.r.Context(), "guardrail stream check failed", "err", err)
+		writeSSE(g.ResponseWriter, models.NewErrorResponse(
+			"guardrail check failed",
+			service.ErrorTypeServer,
+			"",
+			"",
+		))
+		_ = http.NewResponseController(g.ResponseWriter).Flush()
+		return
+	}
+	g.h.logger.InfoContext(g.r.Context(), "guardrail blocked llm stream", "filter", violation.Filter)
+	appErr := guardrailAppError(violation)
+	writeSSE(g.ResponseWriter, models.NewErrorResponse(appErr.Message, appErr.ErrorType(), appErr.Code, appErr.Param))
+	_ = http.NewResponseController(g.ResponseWriter).Flush()
+}
@@ -4,12 +4,13 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/hibiken/asynq"
 
 	"github.com/rhajizada/llamero/internal/auth"
 	"github.com/rhajizada/llamero/internal/config"
+	"github.com/rhajizada/llamero/internal/guardrails"
+	"github.com/rhajizada/llamero/internal/profiles"
 	"github.com/rhajizada/llamero/internal/roles"
 	"github.com/rhajizada/llamero/internal/service"
 )
@@ -18,26 +19,34 @@ const (
 	// backendHTTPTimeout controls how long we wait for backend responses. Set to
 	// zero to allow long-running streaming requests to complete.
 	backendHTTPTimeout = 0
-	stateStoreTTL      = 5 * time.Minute
 )
 
 // Handler coordinates OAuth flow endpoints and JWT issuance.
 type Handler struct {
-	cfg    *config.ServerConfig
-	roles  *roles.Store
-	svc    *service.Service
-	client *http.Client
-	state  *auth.StateStore
-	issuer *auth.TokenIssuer
-	tasks  *asynq.Client
-	logger *slog.Logger
+	cfg      *config.ServerConfig
+	roles    *roles.Store
+	profiles *profiles.Store
+	guards   *guardrails.Chain
+	svc      *service.Service
+	client   *http.Client
+	state    auth.StateStore
+	oidc     *auth.OIDCProvider
+	issuer   *auth.TokenIssuer
+	keys     *auth.Keyring
+	cookies  *auth.SessionCookies
+	tasks    *asynq.Client
+	logger   *slog.Logger
 }
 
 // New builds a Handler with the provided dependencies.
 func New(
 	cfg *config.ServerConfig,
 	roleStore *roles.Store,
+	profileStore *profiles.Store,
+	guardChain *guardrails.Chain,
 	svc *service.Service,
+	keys *auth.Keyring,
+	states auth.StateStore,
 	tasks *asynq.Client,
 	logger *slog.Logger,
 ) (*Handler, error) {
@@ -47,9 +56,21 @@ func New(
 	if roleStore == nil {
 		return nil, errors.New("roles store is required")
 	}
+	if profileStore == nil {
+		return nil, errors.New("profiles store is required")
+	}
+	if guardChain == nil {
+		return nil, errors.New("guardrails chain is required")
+	}
 	if svc == nil {
 		return nil, errors.New("service is required")
 	}
+	if keys == nil {
+		return nil, errors.New("keyring is required")
+	}
+	if states == nil {
+		return nil, errors.New("state store is required")
+	}
 	if tasks == nil {
 		return nil, errors.New("task client is required")
 	}
@@ -57,19 +78,25 @@ func New(
 		logger = slog.Default()
 	}
 
-	issuer, err := auth.NewTokenIssuer(cfg.JWT)
+	issuer, err := auth.NewTokenIssuer(cfg.JWT, keys)
 	if err != nil {
 		return nil, err
 	}
 
+	client := &http.Client{Timeout: backendHTTPTimeout}
 	return &Handler{
-		cfg:    cfg,
-		roles:  roleStore,
-		svc:    svc,
-		client: &http.Client{Timeout: backendHTTPTimeout},
-		state:  auth.NewStateStore(stateStoreTTL),
-		issuer: issuer,
-		tasks:  tasks,
-		logger: logger,
+		cfg:      cfg,
+		roles:    roleStore,
+		profiles: profileStore,
+		guards:   guardChain,
+		svc:      svc,
+		client:   client,
+		state:    states,
+		oidc:     auth.NewOIDCProvider(cfg.OAuth, client),
+		issuer:   issuer,
+		keys:     keys,
+		cookies:  auth.NewSessionCookies(cfg.Sessions, cfg.JWT.TTL),
+		tasks:    tasks,
+		logger:   logger,
 	}, nil
 }
@@ -2,20 +2,35 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/service"
 )
 
+// maxBackendErrorBytes caps how much of a backend error body is read for translation.
+const maxBackendErrorBytes = 64 << 10
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(payload)
 }
 
+// writeError renders an OpenAI-style error envelope with the type implied by the status.
 func writeError(w http.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]string{"error": message})
+	writeJSON(w, status, models.NewErrorResponse(message, service.ErrorTypeForStatus(status), "", ""))
+}
+
+// writeAppError renders a service.Error with its type, code and parameter.
+func writeAppError(w http.ResponseWriter, err *service.Error) {
+	writeJSON(w, err.Status, models.NewErrorResponse(err.Message, err.ErrorType(), err.Code, err.Param))
 }
 
 func getString(value any) string {
@@ -100,3 +115,19 @@ func nullableString(value string) *string {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+// writeBackendError translates an error response from an Ollama backend into the error envelope.
+func writeBackendError(w http.ResponseWriter, resp *http.Response) {
+	payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxBackendErrorBytes))
+	writeAppError(w, service.BackendError(resp.StatusCode, ollamanative.ErrorMessage(payload)))
+}
+
+// writeServiceError renders a service.Error, falling back to a 500 with the supplied message.
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	var appErr *service.Error
+	if errors.As(err, &appErr) {
+		writeAppError(w, appErr)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/roles"
+	"github.com/rhajizada/llamero/internal/service"
+	"github.com/rhajizada/llamero/internal/structured"
+	"github.com/rhajizada/llamero/internal/workers"
+)
+
+var _ models.CreateChatJobRequest
+
+// chatJobPayload is the submitted job envelope; the request is stored as sent.
+type chatJobPayload struct {
+	Request     json.RawMessage `json:"request"`
+	CallbackURL string          `json:"callback_url"`
+}
+
+// HandleCreateChatJob godoc
+// @Summary Run a chat completion asynchronously
+// @Description Queues the chat completion on the worker and returns a job to poll. Model profiles
+// @Description apply as for /api/chat/completions and streaming is disabled. When callback_url is
+// @Description set, the finished job is POSTed to it with an X-Llamero-Signature header
+// @Description (t=<unix>,v1=<hex HMAC-SHA256 of "<t>.<body>">). Failed deliveries are retried with
+// @Description backoff and end in the dead state.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateChatJobRequest true "Job payload"
+// @Success 202 {object} models.Job
+// @Header 202 {string} Location "URL to poll for the job status"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/jobs/chat [post].
+func (h *Handler) HandleCreateChatJob(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	body, err := h.readProxyPayload(w, r, roles.RouteGroupLLM)
+	if err != nil {
+		h.writeProxyReadError(w, err)
+		return
+	}
+
+	var payload chatJobPayload
+	if decodeErr := json.Unmarshal(body, &payload); decodeErr != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	var chat ChatCompletionProxyRequest
+	if decodeErr := json.Unmarshal(payload.Request, &chat); decodeErr != nil {
+		writeError(w, http.StatusBadRequest, "request must be a chat completion payload")
+		return
+	}
+	if !h.authorizeJobModel(w, r, payload.Request) {
+		return
+	}
+	if _, err = structured.Parse(chat.ResponseFormat); err != nil {
+		writeResponseFormatError(w, err)
+		return
+	}
+	if strings.TrimSpace(payload.CallbackURL) != "" && h.cfg.Webhooks.Secret == "" {
+		writeError(w, http.StatusBadRequest, "webhooks are not configured")
+		return
+	}
+
+	job, err := h.svc.CreateChatJob(r.Context(), service.CreateJobParams{
+		UserID:      userID,
+		Role:        claims.Role,
+		Model:       chat.Model,
+		Request:     payload.Request,
+		CallbackURL: payload.CallbackURL,
+	})
+	if err != nil {
+		writeServiceError(w, err, "failed to create job")
+		return
+	}
+
+	task, err := workers.NewRunJobTask(job.ID, h.cfg.Jobs.Timeout)
+	if err == nil {
+		_, err = h.tasks.EnqueueContext(r.Context(), task)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "enqueue job", "job_id", job.ID, "err", err)
+		if jobID, parseErr := uuid.Parse(job.ID); parseErr == nil {
+			_ = h.svc.FailJob(r.Context(), jobID, "failed to schedule job")
+		}
+		writeError(w, http.StatusInternalServerError, "failed to schedule job")
+		return
+	}
+
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// HandleGetJob godoc
+// @Summary Get an asynchronous job
+// @Description Returns the job status, and the chat completion once it has succeeded.
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/jobs/{jobID} [get].
+func (h *Handler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	jobID, ok := pathUUID(w, r, "jobID", "invalid job id")
+	if !ok {
+		return
+	}
+	job, err := h.svc.GetJob(r.Context(), userID, jobID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load job")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// authorizeJobModel checks the caller may use the model a chat job resolves to once its profile
+// applies. The worker applies the profile again when the job runs.
+func (h *Handler) authorizeJobModel(w http.ResponseWriter, r *http.Request, request []byte) bool {
+	resolved, _, err := h.applyProfile(r, request, profiles.KindChat)
+	if err != nil {
+		writeProfileError(w, err)
+		return false
+	}
+	var chat ChatCompletionProxyRequest
+	if err = json.Unmarshal(resolved, &chat); err != nil || strings.TrimSpace(chat.Model) == "" {
+		writeError(w, http.StatusBadRequest, "model is required")
+		return false
+	}
+	return authorizeModel(w, r, chat.Model)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/models"
+)
+
+// HandleJWKS godoc
+// @Summary Public token signing keys
+// @Description Lists the public keys Llamero tokens are signed with, including retired keys whose
+// @Description tokens have not expired yet. Tokens name their key in the kid header.
+// @Tags Keys
+// @Produce json
+// @Success 200 {object} models.JSONWebKeySet
+// @Failure 500 {object} models.ErrorResponse
+// @Router /.well-known/jwks.json [get].
+func (h *Handler) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.keys.Keys(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load signing keys", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to load signing keys")
+		return
+	}
+	set := models.JSONWebKeySet{Keys: make([]models.JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		jwk, jwkErr := key.JWK()
+		if jwkErr != nil {
+			h.logger.ErrorContext(r.Context(), "encode signing key", "kid", key.ID, "err", jwkErr)
+			continue
+		}
+		set.Keys = append(set.Keys, models.JSONWebKey(jwk))
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, set)
+}
+
+// HandleListSigningKeys godoc
+// @Summary List token signing keys
+// @Tags Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SigningKey
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/keys [get].
+func (h *Handler) HandleListSigningKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.keys.Keys(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "load signing keys", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to load signing keys")
+		return
+	}
+	out := make([]models.SigningKey, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, signingKeyModel(key))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// HandleRotateSigningKey godoc
+// @Summary Rotate the token signing key
+// @Description Generates a new signing key with the configured method and makes it active. The
+// @Description previous keys stop signing but keep verifying until every token they signed has
+// @Description expired, so existing sessions and personal access tokens stay valid.
+// @Tags Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} models.SigningKey
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/keys/rotate [post].
+func (h *Handler) HandleRotateSigningKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.keys.Rotate(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "rotate signing key", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to rotate signing key")
+		return
+	}
+	h.logger.InfoContext(r.Context(), "rotated signing key", "kid", key.ID)
+	writeJSON(w, http.StatusCreated, signingKeyModel(key))
+}
+
+func signingKeyModel(key *auth.SigningKey) models.SigningKey {
+	return models.SigningKey{
+		ID:          key.ID,
+		Algorithm:   key.Method.Alg(),
+		Source:      key.Source,
+		Active:      key.CanSign(),
+		CreatedAt:   key.CreatedAt,
+		RetiredAt:   key.RetiredAt,
+		VerifyUntil: key.VerifyUntil,
+	}
+}
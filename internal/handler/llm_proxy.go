@@ -9,9 +9,14 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/rhajizada/llamero/internal/guardrails"
 	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/profiles"
 	"github.com/rhajizada/llamero/internal/requestctx"
+	"github.com/rhajizada/llamero/internal/roles"
 	"github.com/rhajizada/llamero/internal/service"
+	"github.com/rhajizada/llamero/internal/structured"
 )
 
 var (
@@ -23,44 +28,66 @@ var (
 	_ models.EmbeddingsResponse
 )
 
-const maxProxyBodyBytes int64 = 5 << 20 // 5 MiB
-
-var errProxyBodyTooLarge = errors.New("request body too large")
-
 // ChatCompletionProxyRequest represents the subset of LLM fields that Llamero inspects.
 type ChatCompletionProxyRequest struct {
-	Model string `json:"model"`
+	Model          string             `json:"model"`
+	Messages       []proxyChatMessage `json:"messages"`
+	Tools          []json.RawMessage  `json:"tools"`
+	ResponseFormat json.RawMessage    `json:"response_format" swaggertype:"object"`
+	N              *int               `json:"n"`
 } // @name ChatCompletionProxyRequest
 
 // EmbeddingsProxyRequest represents the subset of LLM fields that Llamero inspects.
 type EmbeddingsProxyRequest struct {
-	Model string `json:"model"`
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"`
 } // @name EmbeddingsProxyRequest
 
 // CompletionProxyRequest represents the subset of completion fields inspected for routing.
 type CompletionProxyRequest struct {
-	Model string `json:"model"`
+	Model  string `json:"model"`
+	Suffix string `json:"suffix"`
 }
 
 // HandleChatCompletions godoc
 // @Summary Proxy chat completions
+// @Description Requests using tools or image inputs are only routed to backends whose copy of the
+// @Description model supports them; otherwise the request is rejected with a 400. Model profiles
+// @Description may rewrite the model, fill in defaults, prepend a system prompt and clamp parameters.
+// @Description Guardrails may redact prompts and completions or block them with a content_filter error.
+// @Description A json_schema response_format is passed to Ollama as the format schema. When strict is
+// @Description set, non-streamed completions are validated against the schema and regenerated on a
+// @Description mismatch; a completion that still does not match fails with invalid_structured_output.
+// @Description A profile context policy either rejects conversations that overflow the model's context
+// @Description window with context_length_exceeded or drops the oldest non-system messages to fit.
+// @Description Requests with n > 1 are sent as n single-choice requests spread across backends and
+// @Description merged into one response; streamed choices are interleaved as their chunks arrive.
 // @Tags LLM
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.ChatCompletionRequest true "Chat completion payload"
+// @Param Cache-Control header string false "Send no-cache to bypass the response cache"
 // @Success 200 {object} models.ChatCompletionResponse
-// @Failure 400 {object} map[string]string
-// @Failure 413 {object} map[string]string
-// @Failure 502 {object} map[string]string
-// @Failure 503 {object} map[string]string
-// @Router /api/chat/completions [post].
+// @Header 200 {string} X-Llamero-Cache "HIT, MISS or BYPASS when the response cache applies"
+// @Header 200 {integer} X-Llamero-Context-Dropped "Messages dropped to fit the context window"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/chat/completions [post]
+// @Router /v1/chat/completions [post].
 func (h *Handler) HandleChatCompletions(w http.ResponseWriter, r *http.Request) {
-	body, err := h.readProxyPayload(r)
+	body, err := h.readProxyPayload(w, r, roles.RouteGroupLLM)
 	if err != nil {
 		h.writeProxyReadError(w, err)
 		return
 	}
+	body, runtime, err := h.applyProfile(r, body, profiles.KindChat)
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
 
 	var payload ChatCompletionProxyRequest
 	if decodeErr := json.Unmarshal(body, &payload); decodeErr != nil {
@@ -71,25 +98,60 @@ func (h *Handler) HandleChatCompletions(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, "model is required")
 		return
 	}
+	if !authorizeModel(w, r, payload.Model) {
+		return
+	}
+	choices, err := h.chatChoices(payload.N)
+	if err != nil {
+		writeServiceError(w, err, "invalid n")
+		return
+	}
+	format, err := structured.Parse(payload.ResponseFormat)
+	if err != nil {
+		writeResponseFormatError(w, err)
+		return
+	}
+	if body, err = h.guardRequest(r, payload.Model, body); err != nil {
+		h.writeGuardrailError(w, r, err)
+		return
+	}
+	if body, err = h.fitContextWindow(w, r, payload.Model, body, runtime); err != nil {
+		writeContextWindowError(w, err)
+		return
+	}
 
-	h.forwardLLMRequest(w, r, payload.Model, body)
+	h.serveLLM(w, r, llmCall{
+		kind:     profiles.KindChat,
+		model:    payload.Model,
+		body:     body,
+		required: requiredChatCapabilities(payload),
+		runtime:  runtime,
+		format:   format,
+		choices:  choices,
+	})
 }
 
 // HandleEmbeddings godoc
 // @Summary Proxy embeddings
+// @Description Large input arrays are split into batches and spread across every healthy backend
+// @Description serving the model; results are reassembled in the original order. Vectors for string
+// @Description inputs are cached per model digest, so only cache misses reach a backend.
 // @Tags LLM
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.EmbeddingsRequest true "Embeddings payload"
+// @Param Cache-Control header string false "Send no-cache to recompute and refresh cached vectors"
 // @Success 200 {object} models.EmbeddingsResponse
-// @Failure 400 {object} map[string]string
-// @Failure 413 {object} map[string]string
-// @Failure 502 {object} map[string]string
-// @Failure 503 {object} map[string]string
-// @Router /api/embeddings [post].
+// @Header 200 {string} X-Llamero-Cache "HIT, MISS, PARTIAL or BYPASS when vectors are cacheable"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/embeddings [post]
+// @Router /v1/embeddings [post].
 func (h *Handler) HandleEmbeddings(w http.ResponseWriter, r *http.Request) {
-	body, err := h.readProxyPayload(r)
+	body, err := h.readProxyPayload(w, r, roles.RouteGroupEmbeddings)
 	if err != nil {
 		h.writeProxyReadError(w, err)
 		return
@@ -104,29 +166,49 @@ func (h *Handler) HandleEmbeddings(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "model is required")
 		return
 	}
+	if !authorizeModel(w, r, payload.Model) {
+		return
+	}
 
-	h.forwardLLMRequest(w, r, payload.Model, body)
+	if h.serveCachedEmbeddings(w, r, payload, body) {
+		return
+	}
+	if inputs, ok := splitEmbeddingInputs(payload.Input); ok && len(inputs) > h.cfg.Embeddings.BatchSize {
+		h.fanOutEmbeddings(w, r, payload.Model, body, inputs)
+		return
+	}
+	h.forwardLLMRequest(w, r, payload.Model, body, []string{service.CapabilityEmbedding}, nil)
 }
 
 // HandleCompletions godoc
 // @Summary Proxy legacy completions
+// @Description Model profiles may rewrite the model, fill in defaults and clamp parameters.
+// @Description Guardrails may redact prompts and completions or block them with a content_filter error.
 // @Tags LLM
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.CompletionRequest true "Completion payload"
+// @Param Cache-Control header string false "Send no-cache to bypass the response cache"
 // @Success 200 {object} models.CompletionResponse
-// @Failure 400 {object} map[string]string
-// @Failure 413 {object} map[string]string
-// @Failure 502 {object} map[string]string
-// @Failure 503 {object} map[string]string
-// @Router /api/completions [post].
+// @Header 200 {string} X-Llamero-Cache "HIT, MISS or BYPASS when the response cache applies"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/completions [post]
+// @Router /v1/completions [post].
 func (h *Handler) HandleCompletions(w http.ResponseWriter, r *http.Request) {
-	body, err := h.readProxyPayload(r)
+	body, err := h.readProxyPayload(w, r, roles.RouteGroupLLM)
 	if err != nil {
 		h.writeProxyReadError(w, err)
 		return
 	}
+	body, runtime, err := h.applyProfile(r, body, profiles.KindCompletion)
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
 
 	var payload CompletionProxyRequest
 	if decodeErr := json.Unmarshal(body, &payload); decodeErr != nil {
@@ -137,41 +219,101 @@ func (h *Handler) HandleCompletions(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "model is required")
 		return
 	}
+	if !authorizeModel(w, r, payload.Model) {
+		return
+	}
+	if body, err = h.guardRequest(r, payload.Model, body); err != nil {
+		h.writeGuardrailError(w, r, err)
+		return
+	}
 
-	h.forwardLLMRequest(w, r, payload.Model, body)
+	h.serveLLM(w, r, llmCall{
+		kind:     profiles.KindCompletion,
+		model:    payload.Model,
+		body:     body,
+		required: requiredCompletionCapabilities(payload),
+		runtime:  runtime,
+		choices:  1,
+	})
 }
 
-func (h *Handler) readProxyPayload(r *http.Request) ([]byte, error) {
-	defer r.Body.Close()
-	limited := io.LimitReader(r.Body, maxProxyBodyBytes+1)
-	body, err := io.ReadAll(limited)
-	if err != nil {
-		return nil, err
-	}
-	if int64(len(body)) > maxProxyBodyBytes {
-		return nil, errProxyBodyTooLarge
-	}
-	return body, nil
+// llmCall describes a rewritten chat or completion request on its way to a backend.
+type llmCall struct {
+	kind     profiles.Kind
+	model    string
+	body     []byte
+	required []string
+	runtime  profiles.Runtime
+	format   *structured.Format
+	// choices is the number of completions requested with n; more than one fans out.
+	choices int
 }
 
-func (h *Handler) writeProxyReadError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, errProxyBodyTooLarge):
-		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
-	default:
-		writeError(w, http.StatusBadRequest, "unable to read request body")
+// serveLLM forwards a rewritten chat or completion request, going through the response cache when
+// the model's profile and the caller's token both enable it. Structured output is validated before
+// the guardrail post filters see the completion.
+func (h *Handler) serveLLM(w http.ResponseWriter, r *http.Request, call llmCall) {
+	native := ollamanative.For(call.kind, call.runtime, call.format.Ollama())
+	send := func(out http.ResponseWriter) {
+		h.forwardLLMRequest(out, r, call.model, call.body, call.required, native)
+	}
+	if call.choices > 1 {
+		send = func(out http.ResponseWriter) {
+			h.fanOutChoices(out, r, call, native)
+		}
+	}
+	if call.format.Validates() {
+		direct := send
+		send = func(out http.ResponseWriter) {
+			h.forwardStructured(out, r, call.format, direct)
+		}
 	}
+	forward := func(out http.ResponseWriter) {
+		if !h.guards.Active(guardrails.StagePost, call.model) {
+			send(out)
+			return
+		}
+		guarded := h.newGuardWriter(out, r, call.model)
+		send(guarded)
+		guarded.finish()
+	}
+	if entry := h.responseCacheFor(r, call.kind, call.model, call.body, call.runtime); entry != nil {
+		h.serveWithResponseCache(w, r, entry, forward)
+		return
+	}
+	forward(w)
 }
 
-func (h *Handler) forwardLLMRequest(w http.ResponseWriter, r *http.Request, model string, body []byte) {
-	route, err := h.svc.RouteBackend(r.Context(), model)
+func (h *Handler) forwardLLMRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	model string,
+	body []byte,
+	required []string,
+	native *ollamanative.Options,
+) {
+	route, err := h.svc.RouteBackend(r.Context(), model, required...)
 	if err != nil {
 		h.handleRoutingError(w, err)
 		return
 	}
 
 	ctx := requestctx.WithBackendID(r.Context(), route.ID)
-	req := r.WithContext(ctx)
+	h.forwardToRoute(w, r.WithContext(ctx), route, body, native)
+}
+
+// forwardToRoute sends a request to the chosen backend and relays its response.
+func (h *Handler) forwardToRoute(
+	w http.ResponseWriter,
+	req *http.Request,
+	route service.BackendRoute,
+	body []byte,
+	native *ollamanative.Options,
+) {
+	if native != nil {
+		h.forwardNative(w, req, route, body, *native)
+		return
+	}
 
 	resp, err := h.proxyToBackend(req, route, body)
 	if err != nil {
@@ -181,10 +323,14 @@ func (h *Handler) forwardLLMRequest(w http.ResponseWriter, r *http.Request, mode
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusBadRequest {
+		writeBackendError(w, resp)
+		return
+	}
 	copyHeaders(w.Header(), resp.Header)
 	stripHopHeaders(w.Header())
 	w.WriteHeader(resp.StatusCode)
-	if _, copyErr := io.Copy(w, resp.Body); copyErr != nil {
+	if _, copyErr := io.Copy(w, resp.Body); copyErr != nil && !errors.Is(copyErr, errStreamBlocked) {
 		h.logger.ErrorContext(req.Context(), "write proxied body", "err", copyErr)
 	}
 }
@@ -194,6 +340,11 @@ func (h *Handler) handleRoutingError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusServiceUnavailable, "no healthy backends available")
 		return
 	}
+	var capErr *service.CapabilityError
+	if errors.As(err, &capErr) {
+		writeCapabilityError(w, capErr)
+		return
+	}
 	h.logger.Error("route backend", "err", err)
 	writeError(w, http.StatusBadGateway, "failed to select backend")
 }
@@ -3,8 +3,10 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/rhajizada/llamero/internal/middleware"
 	"github.com/rhajizada/llamero/internal/models"
 	"github.com/rhajizada/llamero/internal/service"
 )
@@ -16,47 +18,67 @@ var (
 
 // HandleListModels godoc
 // @Summary List available models
+// @Description Set verbose=true to include model details and per-backend availability.
 // @Tags Models
 // @Produce json
 // @Security BearerAuth
+// @Param verbose query bool false "Include details and backend availability"
 // @Success 200 {object} models.ModelList
-// @Failure 500 {object} map[string]string
-// @Router /api/models [get].
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/models [get]
+// @Router /v1/models [get].
 func (h *Handler) HandleListModels(w http.ResponseWriter, r *http.Request) {
-	result, err := h.svc.ListModels(r.Context())
+	verbose := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("verbose")); raw != "" {
+		parsed, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "verbose must be a boolean")
+			return
+		}
+		verbose = parsed
+	}
+
+	result, err := h.svc.ListModels(r.Context(), verbose)
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to list models")
 		return
 	}
-	writeJSON(w, http.StatusOK, result)
+	writeJSON(w, http.StatusOK, filterModels(r, result))
 }
 
 // HandleGetModel godoc
 // @Summary Get metadata for a single model
+// @Description Includes model details and per-backend availability.
 // @Tags Models
 // @Produce json
 // @Security BearerAuth
 // @Param modelID path string true "Model ID"
 // @Success 200 {object} models.Model
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /api/models/{modelID} [get].
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/models/{modelID} [get]
+// @Router /v1/models/{modelID} [get].
 func (h *Handler) HandleGetModel(w http.ResponseWriter, r *http.Request) {
 	modelID := strings.TrimSpace(r.PathValue("modelID"))
 	if modelID == "" {
 		writeError(w, http.StatusNotFound, "model not found")
 		return
 	}
+	if claims, ok := middleware.ClaimsFromContext(r.Context()); !ok || !usableModel(claims, modelID) {
+		writeError(w, http.StatusNotFound, "model not found")
+		return
+	}
 	model, err := h.svc.GetModel(r.Context(), modelID)
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to load model")
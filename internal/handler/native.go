@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const (
+	nativeLineBufferBytes = 64 << 10
+	maxNativeLineBytes    = 16 << 20
+)
+
+func (h *Handler) forwardNative(
+	w http.ResponseWriter,
+	r *http.Request,
+	route service.BackendRoute,
+	body []byte,
+	native ollamanative.Options,
+) {
+	nativeReq, err := ollamanative.Build(body, native)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	encoded, err := json.Marshal(nativeReq.Payload)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode backend request")
+		return
+	}
+
+	resp, err := h.proxyBackendWithBody(r, route, http.MethodPost, nativeReq.Path, bytes.NewReader(encoded))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "native proxy request failed", "backend_id", route.ID, "err", err)
+		writeError(w, http.StatusBadGateway, "backend request failed")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		writeBackendError(w, resp)
+		return
+	}
+	if !nativeReq.Stream {
+		h.relayNativeCompletion(w, r, resp.Body, nativeReq.Converter)
+		return
+	}
+	h.relayNativeStream(w, r, resp.Body, nativeReq.Converter)
+}
+
+func (h *Handler) relayNativeCompletion(
+	w http.ResponseWriter,
+	r *http.Request,
+	body io.Reader,
+	converter ollamanative.Converter,
+) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "read native response", "err", err)
+		writeError(w, http.StatusBadGateway, "backend request failed")
+		return
+	}
+	converted, err := converter.Completion(payload)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "convert native response", "err", err)
+		writeError(w, http.StatusBadGateway, "invalid backend response")
+		return
+	}
+	writeJSON(w, http.StatusOK, converted)
+}
+
+// relayNativeStream re-encodes Ollama's NDJSON stream as OpenAI server-sent events.
+func (h *Handler) relayNativeStream(
+	w http.ResponseWriter,
+	r *http.Request,
+	body io.Reader,
+	converter ollamanative.Converter,
+) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, nativeLineBufferBytes), maxNativeLineBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if message := ollamanative.ErrorMessage(line); message != "" {
+			appErr := service.BackendError(http.StatusInternalServerError, message)
+			writeSSE(w, models.NewErrorResponse(appErr.Message, appErr.ErrorType(), appErr.Code, appErr.Param))
+			break
+		}
+		payloads, done, err := converter.Chunks(line)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "convert native stream", "err", err)
+			break
+		}
+		for _, payload := range payloads {
+			writeSSE(w, payload)
+		}
+		if done {
+			_, _ = io.WriteString(w, "data: [DONE]\n\n")
+		}
+		_ = controller.Flush()
+		if done {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		h.logger.ErrorContext(r.Context(), "read native stream", "err", err)
+	}
+}
+
+func writeSSE(w io.Writer, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/rhajizada/llamero/internal/auth"
 	"github.com/rhajizada/llamero/internal/middleware"
 	"github.com/rhajizada/llamero/internal/models"
 	"github.com/rhajizada/llamero/internal/repository"
@@ -29,8 +30,13 @@ func (h *Handler) Health(w http.ResponseWriter, _ *http.Request) {
 
 // Login kicks off the OAuth authorization code flow.
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
-	state := h.state.Issue()
-	authURL, err := h.buildAuthorizeURL(state)
+	state, login, err := h.state.Issue(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "issue oauth state", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to start login")
+		return
+	}
+	authURL, err := h.buildAuthorizeURL(r.Context(), state, login)
 	if err != nil {
 		h.logger.ErrorContext(r.Context(), "build auth url", "err", err)
 		writeError(w, http.StatusInternalServerError, "configuration error")
@@ -39,7 +45,8 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
 
-// Callback exchanges the authorization code, fetches user info, and issues a JWT.
+// Callback exchanges the authorization code, verifies the ID token or fetches user info, and
+// issues a JWT.
 func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if errStr := r.FormValue("error"); errStr != "" {
@@ -53,12 +60,18 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing authorization code")
 		return
 	}
-	if ok := h.state.Consume(state); !ok {
+	login, ok, err := h.state.Consume(ctx, state)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "consume oauth state", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to verify state")
+		return
+	}
+	if !ok {
 		writeError(w, http.StatusBadRequest, "invalid state parameter")
 		return
 	}
 
-	tokenResp, err := h.exchangeCode(ctx, code)
+	tokenResp, err := h.exchangeCode(ctx, code, login)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "exchange code", "err", err)
 		writeError(w, http.StatusBadGateway, "token exchange failed")
@@ -69,10 +82,14 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.fetchUserInfo(ctx, tokenResp.AccessToken)
+	user, err := h.resolveUser(ctx, tokenResp, login)
 	if err != nil {
-		h.logger.ErrorContext(ctx, "fetch user info", "err", err)
-		writeError(w, http.StatusBadGateway, "user info request failed")
+		h.logger.ErrorContext(ctx, "resolve user", "err", err)
+		if errors.Is(err, auth.ErrInvalidIDToken) {
+			writeError(w, http.StatusUnauthorized, "id token verification failed")
+		} else {
+			writeError(w, http.StatusBadGateway, "user info request failed")
+		}
 		return
 	}
 
@@ -96,39 +113,63 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 		h.logger.ErrorContext(ctx, "upsert user", "err", err)
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusInternalServerError, "failed to persist user")
 		}
 		return
 	}
+	if upserted.Disabled {
+		writeError(w, http.StatusForbidden, "user account is disabled")
+		return
+	}
 
-	token, err := h.issuer.Issue(upserted.ID, user.Subject, user.Email, role, scopes)
+	tokens, err := h.startSession(ctx, r, upserted)
 	if err != nil {
-		h.logger.ErrorContext(ctx, "issue token", "err", err)
-		writeError(w, http.StatusInternalServerError, "token issuance failed")
+		h.logger.ErrorContext(ctx, "start session", "err", err)
+		writeServiceError(w, err, "token issuance failed")
 		return
 	}
 
-	redirectTarget := h.loginRedirectURL(token)
+	if h.cookies.Enabled() {
+		h.cookies.Set(w, tokens.accessToken, tokens.refreshToken)
+		http.Redirect(w, r, h.cookieLoginRedirectURL(), http.StatusFound)
+		return
+	}
+	redirectTarget := h.loginRedirectURL(tokens)
 	http.Redirect(w, r, redirectTarget, http.StatusFound)
 }
 
-func (h *Handler) loginRedirectURL(token string) string {
-	base := strings.TrimRight(h.cfg.ExternalURL, "/")
-	if base == "" {
-		base = "/"
-	}
-
+func (h *Handler) loginRedirectURL(tokens sessionTokens) string {
 	params := url.Values{}
-	params.Set("token", token)
+	params.Set("token", tokens.accessToken)
+	params.Set("refresh_token", tokens.refreshToken)
 	params.Set("expires_in", strconv.Itoa(int(h.cfg.JWT.TTL.Seconds())))
 
-	return fmt.Sprintf("%s/login#%s", base, params.Encode())
+	return fmt.Sprintf("%s/login#%s", h.externalBaseURL(), params.Encode())
 }
 
-func (h *Handler) buildAuthorizeURL(state string) (string, error) {
-	u, err := url.Parse(h.cfg.OAuth.AuthorizeURL)
+// cookieLoginRedirectURL tells the UI that the session lives in cookies and which cookie carries
+// the CSRF token. No credentials are put in the URL.
+func (h *Handler) cookieLoginRedirectURL() string {
+	params := url.Values{}
+	params.Set("session", "cookie")
+	params.Set("csrf_cookie", h.cookies.CSRFCookieName())
+
+	return fmt.Sprintf("%s/login#%s", h.externalBaseURL(), params.Encode())
+}
+
+// externalBaseURL is the external URL without a trailing slash; empty keeps redirects relative.
+func (h *Handler) externalBaseURL() string {
+	return strings.TrimRight(h.cfg.ExternalURL, "/")
+}
+
+func (h *Handler) buildAuthorizeURL(ctx context.Context, state string, login auth.LoginState) (string, error) {
+	endpoints, err := h.oidc.Endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(endpoints.Authorization)
 	if err != nil {
 		return "", err
 	}
@@ -139,6 +180,13 @@ func (h *Handler) buildAuthorizeURL(state string) (string, error) {
 	q.Set("redirect_uri", h.cfg.OAuth.RedirectURL)
 	q.Set("scope", strings.Join(h.cfg.OAuth.Scopes, " "))
 	q.Set("state", state)
+	if h.oidc.VerifiesIDTokens(endpoints) {
+		q.Set("nonce", login.Nonce)
+	}
+	if h.cfg.OAuth.PKCE {
+		q.Set("code_challenge", login.CodeChallenge())
+		q.Set("code_challenge_method", "S256")
+	}
 	if aud := strings.TrimSpace(h.cfg.JWT.Audience); aud != "" {
 		q.Set("audience", aud)
 	}
@@ -146,18 +194,32 @@ func (h *Handler) buildAuthorizeURL(state string) (string, error) {
 	return u.String(), nil
 }
 
-func (h *Handler) exchangeCode(ctx context.Context, code string) (*tokenResponse, error) {
+func (h *Handler) exchangeCode(ctx context.Context, code string, login auth.LoginState) (*tokenResponse, error) {
+	endpoints, err := h.oidc.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	form := url.Values{}
 	form.Set("grant_type", "authorization_code")
 	form.Set("code", code)
 	form.Set("redirect_uri", h.cfg.OAuth.RedirectURL)
+	if h.cfg.OAuth.PKCE {
+		form.Set("code_verifier", login.CodeVerifier)
+	}
+	// Public clients have no secret and identify themselves in the form instead.
+	if h.cfg.OAuth.PublicClient {
+		form.Set("client_id", h.cfg.OAuth.ClientID)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.OAuth.TokenURL, strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.Token, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.SetBasicAuth(h.cfg.OAuth.ClientID, h.cfg.OAuth.ClientSecret)
+	if !h.cfg.OAuth.PublicClient {
+		req.SetBasicAuth(h.cfg.OAuth.ClientID, h.cfg.OAuth.ClientSecret)
+	}
 
 	resp, err := h.client.Do(req)
 	if err != nil {
@@ -176,8 +238,50 @@ func (h *Handler) exchangeCode(ctx context.Context, code string) (*tokenResponse
 	return &tr, nil
 }
 
-func (h *Handler) fetchUserInfo(ctx context.Context, accessToken string) (*userInfo, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.cfg.OAuth.UserInfoURL, nil)
+// resolveUser builds the identity of the user logging in. When the provider issues ID tokens the
+// token is verified and its claims are authoritative; userinfo fills in an email or groups the
+// token leaves out. Otherwise the identity comes from userinfo alone.
+func (h *Handler) resolveUser(ctx context.Context, tr *tokenResponse, login auth.LoginState) (*userInfo, error) {
+	endpoints, err := h.oidc.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !h.oidc.VerifiesIDTokens(endpoints) {
+		return h.fetchUserInfo(ctx, endpoints.UserInfo, tr.AccessToken)
+	}
+	if tr.IDToken == "" {
+		return nil, errors.New("provider did not return an id token")
+	}
+
+	claims, err := h.oidc.VerifyIDToken(ctx, tr.IDToken, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	info := userInfoFromClaims(claims)
+	if (info.Email != "" && len(info.Groups) > 0) || endpoints.UserInfo == "" {
+		return info.withDefaults()
+	}
+
+	fallback, err := h.fetchUserInfo(ctx, endpoints.UserInfo, tr.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if fallback.Subject != info.Subject {
+		return nil, errors.New("userinfo subject does not match id token")
+	}
+	info.Email = firstNonEmpty(info.Email, fallback.Email)
+	info.Name = firstNonEmpty(info.Name, fallback.Name)
+	if len(info.Groups) == 0 {
+		info.Groups = fallback.Groups
+	}
+	return info.withDefaults()
+}
+
+func (h *Handler) fetchUserInfo(ctx context.Context, endpoint, accessToken string) (*userInfo, error) {
+	if endpoint == "" {
+		return nil, errors.New("provider has no userinfo endpoint")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -197,22 +301,16 @@ func (h *Handler) fetchUserInfo(ctx context.Context, accessToken string) (*userI
 	if decodeErr := json.NewDecoder(resp.Body).Decode(&raw); decodeErr != nil {
 		return nil, decodeErr
 	}
+	return userInfoFromClaims(raw).withDefaults()
+}
 
-	info := &userInfo{
+func userInfoFromClaims(raw map[string]any) *userInfo {
+	return &userInfo{
 		Subject: firstNonEmpty(getString(raw["sub"]), getString(raw["id"]), getString(raw["user_id"])),
 		Email:   firstNonEmpty(getString(raw["email"]), getString(raw["preferred_username"])),
 		Name:    getString(raw["name"]),
 		Groups:  collectStrings(raw["groups"]),
 	}
-
-	if info.Subject == "" {
-		return nil, errors.New("userinfo payload missing subject")
-	}
-	if info.Email == "" {
-		info.Email = info.Subject
-	}
-
-	return info, nil
 }
 
 func (h *Handler) determineRole(info *userInfo) (string, []string, error) {
@@ -234,8 +332,8 @@ func (h *Handler) determineRole(info *userInfo) (string, []string, error) {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} models.User
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /api/profile [get].
 func (h *Handler) Profile(w http.ResponseWriter, r *http.Request) {
 	claims, ok := middleware.ClaimsFromContext(r.Context())
@@ -254,7 +352,7 @@ func (h *Handler) Profile(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusInternalServerError, "failed to load profile")
 		}
@@ -279,3 +377,13 @@ type userInfo struct {
 	Name    string
 	Groups  []string
 }
+
+func (u *userInfo) withDefaults() (*userInfo, error) {
+	if u.Subject == "" {
+		return nil, errors.New("user info missing subject")
+	}
+	if u.Email == "" {
+		u.Email = u.Subject
+	}
+	return u, nil
+}
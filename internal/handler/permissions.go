@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/middleware"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+// permitsResource reports whether the caller's token allows every scope the route required on the
+// resource. Scopes may be narrowed to models or backends, as in "llm:chat:model=llama3*".
+func permitsResource(r *http.Request, res auth.Resource) bool {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	for _, scope := range middleware.RequiredScopesFromContext(r.Context()) {
+		if !claims.PermitsResource(scope, res) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorizeModel writes a 403 when the caller may not use the model.
+func authorizeModel(w http.ResponseWriter, r *http.Request, model string) bool {
+	if permitsResource(r, auth.Resource{Model: model}) {
+		return true
+	}
+	writeAppError(w, &service.Error{
+		Status:  http.StatusForbidden,
+		Param:   "model",
+		Message: "token is not allowed to use model " + model,
+	})
+	return false
+}
+
+// authorizeBackend writes a 403 when the caller may not act on the backend.
+func authorizeBackend(w http.ResponseWriter, r *http.Request, route service.BackendRoute) bool {
+	if permitsResource(r, auth.Resource{Backend: route.ID, Tags: route.Tags}) {
+		return true
+	}
+	writeError(w, http.StatusForbidden, "token is not allowed to manage backend "+route.ID)
+	return false
+}
+
+// usableModel reports whether the caller may list the model and call it for chat or embeddings.
+func usableModel(claims *auth.Claims, model string) bool {
+	res := auth.Resource{Model: model}
+	if !claims.PermitsResource("models:list", res) {
+		return false
+	}
+	return claims.PermitsResource("llm:chat", res) || claims.PermitsResource("llm:embeddings", res)
+}
+
+// filterModels drops the models the caller may not use.
+func filterModels(r *http.Request, list models.ModelList) models.ModelList {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		list.Data = nil
+		return list
+	}
+	visible := make([]models.Model, 0, len(list.Data))
+	for _, model := range list.Data {
+		if usableModel(claims, model.ID) {
+			visible = append(visible, model)
+		}
+	}
+	list.Data = visible
+	return list
+}
+
+// filterBackends drops the backends the caller may not see.
+func filterBackends(r *http.Request, backends []models.Backend) []models.Backend {
+	visible := make([]models.Backend, 0, len(backends))
+	for _, backend := range backends {
+		if permitsResource(r, auth.Resource{Backend: backend.ID, Tags: backend.Tags}) {
+			visible = append(visible, backend)
+		}
+	}
+	return visible
+}
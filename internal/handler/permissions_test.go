@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/rhajizada/llamero/internal/auth"
+)
+
+func TestUsableModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		model  string
+		want   bool
+	}{
+		{"admin", []string{auth.ScopeAll}, "mistral", true},
+		{"chat", []string{"models:list", "llm:chat"}, "mistral", true},
+		{"embeddings", []string{"models:list", "llm:embeddings"}, "nomic-embed-text", true},
+		{"list only", []string{"models:list"}, "mistral", false},
+		{"chat without list", []string{"llm:chat"}, "mistral", false},
+		{"constrained match", []string{"models:list", "llm:chat:model=llama3*"}, "llama3.1:8b", true},
+		{"constrained mismatch", []string{"models:list", "llm:chat:model=llama3*"}, "mistral", false},
+		{"constrained list", []string{"models:list:model=llama3*", "llm:*"}, "mistral", false},
+		{"constrained wildcard", []string{"*:model=llama3*"}, "llama3", true},
+		{"tag constraint on model route", []string{"models:list", "*:tag=gpu"}, "llama3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &auth.Claims{Scopes: tt.scopes}
+			if got := usableModel(claims, tt.model); got != tt.want {
+				t.Fatalf("usableModel(%q, %q) = %v, want %v", tt.scopes, tt.model, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rhajizada/llamero/internal/middleware"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+var errInvalidPayload = errors.New("invalid JSON payload")
+
+// applyProfile rewrites an LLM request body using the profile configured for its model and the
+// caller's role. It returns the rewritten body and the Ollama-native options it carries.
+func (h *Handler) applyProfile(
+	r *http.Request,
+	body []byte,
+	kind profiles.Kind,
+) ([]byte, profiles.Runtime, error) {
+	var req profiles.Request
+	if err := json.Unmarshal(body, &req); err != nil || req == nil {
+		return nil, profiles.Runtime{}, errInvalidPayload
+	}
+
+	role := ""
+	if claims, ok := middleware.ClaimsFromContext(r.Context()); ok {
+		role = claims.Role
+	}
+	runtime, err := h.profiles.Prepare(req, kind, role)
+	if err != nil {
+		return nil, profiles.Runtime{}, err
+	}
+
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		return nil, profiles.Runtime{}, err
+	}
+	return rewritten, runtime, nil
+}
+
+func writeProfileError(w http.ResponseWriter, err error) {
+	var fieldErr *profiles.FieldError
+	if errors.As(err, &fieldErr) {
+		writeAppError(w, &service.Error{
+			Status:  http.StatusBadRequest,
+			Param:   fieldErr.Field,
+			Message: fieldErr.Error(),
+		})
+		return
+	}
+	writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/middleware"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+const (
+	responseCacheHeader = "X-Llamero-Cache"
+	responseCacheHit    = "HIT"
+	responseCacheMiss   = "MISS"
+	responseCacheBypass = "BYPASS"
+
+	contentTypeEventStream = "text/event-stream"
+	sseDoneEvent           = "data: [DONE]"
+)
+
+// responseCacheEntry identifies where a cacheable request's response is stored.
+type responseCacheEntry struct {
+	hash string
+	ttl  time.Duration
+}
+
+// responseCacheFor returns the cache entry for a request when both the model's profile and the
+// caller's token opted in to caching and the backends agree on the model's digest, or nil
+// otherwise.
+func (h *Handler) responseCacheFor(
+	r *http.Request,
+	kind profiles.Kind,
+	model string,
+	body []byte,
+	runtime profiles.Runtime,
+) *responseCacheEntry {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok || !claims.ResponseCache {
+		return nil
+	}
+	profile, ok := h.profiles.Match(model)
+	if !ok {
+		return nil
+	}
+	ttl, enabled := profile.CacheTTL()
+	if !enabled {
+		return nil
+	}
+	if ttl == 0 {
+		ttl = h.cfg.Cache.TTL
+	}
+	digest, ok, err := h.svc.ModelDigest(r.Context(), model)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "resolve model digest", "err", err)
+	}
+	if !ok {
+		return nil
+	}
+	hash, err := responseCacheHash(kind, digest, body, runtime)
+	if err != nil {
+		return nil
+	}
+	return &responseCacheEntry{hash: hash, ttl: ttl}
+}
+
+// responseCacheHash hashes the canonical form of a rewritten request: object keys are sorted at
+// every level and fields that do not influence the output are dropped. The model digest is part of
+// the key, so replacing a model's weights starts a fresh cache.
+func responseCacheHash(kind profiles.Kind, digest string, body []byte, runtime profiles.Runtime) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return "", err
+	}
+	delete(fields, "user")
+
+	canonical, err := json.Marshal(struct {
+		Kind    profiles.Kind  `json:"kind"`
+		Digest  string         `json:"digest"`
+		Request map[string]any `json:"request"`
+		NumCtx  int            `json:"num_ctx"`
+		System  string         `json:"system"`
+	}{Kind: kind, Digest: digest, Request: fields, NumCtx: runtime.NumCtx, System: runtime.System})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// serveWithResponseCache replays a cached response when one exists and otherwise calls forward,
+// storing its response if it completed successfully.
+func (h *Handler) serveWithResponseCache(
+	w http.ResponseWriter,
+	r *http.Request,
+	entry *responseCacheEntry,
+	forward func(http.ResponseWriter),
+) {
+	if bypassResponseCache(r) {
+		w.Header().Set(responseCacheHeader, responseCacheBypass)
+	} else {
+		cached, found, err := h.svc.CachedLLMResponse(r.Context(), entry.hash)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "read response cache", "err", err)
+		}
+		if found {
+			w.Header().Set(responseCacheHeader, responseCacheHit)
+			w.Header().Set("Content-Type", cached.ContentType)
+			w.WriteHeader(cached.Status)
+			if _, writeErr := w.Write(cached.Body); writeErr != nil {
+				h.logger.ErrorContext(r.Context(), "write cached body", "err", writeErr)
+			}
+			return
+		}
+		w.Header().Set(responseCacheHeader, responseCacheMiss)
+	}
+
+	capture := &captureWriter{ResponseWriter: w, status: http.StatusOK, limit: h.cfg.Cache.MaxBytes}
+	forward(capture)
+	if !capture.complete() {
+		return
+	}
+	resp := service.CachedResponse{
+		Status:      capture.status,
+		ContentType: w.Header().Get("Content-Type"),
+		Body:        capture.buf.Bytes(),
+	}
+	if err := h.svc.CacheLLMResponse(r.Context(), entry.hash, resp, entry.ttl); err != nil {
+		h.logger.WarnContext(r.Context(), "write response cache", "err", err)
+	}
+}
+
+func bypassResponseCache(r *http.Request) bool {
+	for directive := range strings.SplitSeq(r.Header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+	return false
+}
+
+// captureWriter tees a response into memory, giving up once it grows past limit.
+type captureWriter struct {
+	http.ResponseWriter
+
+	status   int
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (c *captureWriter) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *captureWriter) Write(p []byte) (int, error) {
+	if !c.overflow {
+		if c.buf.Len()+len(p) > c.limit {
+			c.overflow = true
+			c.buf.Reset()
+		} else {
+			c.buf.Write(p)
+		}
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying writer so streamed responses can still be flushed.
+func (c *captureWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// complete reports whether the captured response is a full, successful reply worth caching.
+func (c *captureWriter) complete() bool {
+	if c.status != http.StatusOK || c.overflow || c.buf.Len() == 0 {
+		return false
+	}
+	if strings.HasPrefix(c.Header().Get("Content-Type"), contentTypeEventStream) {
+		return bytes.Contains(c.buf.Bytes(), []byte(sseDoneEvent))
+	}
+	return true
+}
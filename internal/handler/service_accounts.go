@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+// HandleListServiceAccounts godoc
+// @Summary List service accounts
+// @Tags Service Accounts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.ServiceAccount
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts [get].
+func (h *Handler) HandleListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.svc.ListServiceAccounts(r.Context())
+	if err != nil {
+		writeServiceError(w, err, "failed to list service accounts")
+		return
+	}
+	writeJSON(w, http.StatusOK, accounts)
+}
+
+// HandleCreateServiceAccount godoc
+// @Summary Create a service account
+// @Description Creates a non-human principal for CI jobs and other services. Its scopes cannot
+// @Description exceed the caller's, and the caller owns it unless owner_id says otherwise.
+// @Tags Service Accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body models.CreateServiceAccountRequest true "Service account"
+// @Success 201 {object} models.ServiceAccount
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts [post].
+func (h *Handler) HandleCreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	claims, callerID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	var req models.CreateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return
+	}
+	scopes, ok := serviceAccountScopes(w, claims, req.Scopes)
+	if !ok {
+		return
+	}
+	owner := req.OwnerID
+	if owner == nil {
+		owner = &callerID
+	}
+
+	account, err := h.svc.CreateServiceAccount(r.Context(), service.ServiceAccountParams{
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     owner,
+		Scopes:      scopes,
+	})
+	if err != nil {
+		writeServiceError(w, err, "failed to create service account")
+		return
+	}
+	writeJSON(w, http.StatusCreated, account)
+}
+
+// HandleGetServiceAccount godoc
+// @Summary Get a service account
+// @Tags Service Accounts
+// @Produce json
+// @Security BearerAuth
+// @Param accountID path string true "Service account ID"
+// @Success 200 {object} models.ServiceAccount
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts/{accountID} [get].
+func (h *Handler) HandleGetServiceAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := serviceAccountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.svc.GetServiceAccount(r.Context(), accountID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load service account")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.NewServiceAccountFromRepo(account))
+}
+
+// HandleUpdateServiceAccount godoc
+// @Summary Update a service account
+// @Description Replaces the description, owner and scopes. Tokens holding scopes the account
+// @Description no longer has are revoked.
+// @Tags Service Accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param accountID path string true "Service account ID"
+// @Param payload body models.UpdateServiceAccountRequest true "Service account"
+// @Success 200 {object} models.ServiceAccount
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts/{accountID} [put].
+func (h *Handler) HandleUpdateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	claims, _, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	accountID, ok := serviceAccountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return
+	}
+	scopes, ok := serviceAccountScopes(w, claims, req.Scopes)
+	if !ok {
+		return
+	}
+
+	account, err := h.svc.UpdateServiceAccount(r.Context(), accountID, service.ServiceAccountParams{
+		Description: req.Description,
+		OwnerID:     req.OwnerID,
+		Scopes:      scopes,
+	})
+	if err != nil {
+		writeServiceError(w, err, "failed to update service account")
+		return
+	}
+	writeJSON(w, http.StatusOK, account)
+}
+
+// HandleDeleteServiceAccount godoc
+// @Summary Delete a service account
+// @Description Deletes the service account together with its tokens.
+// @Tags Service Accounts
+// @Security BearerAuth
+// @Param accountID path string true "Service account ID"
+// @Success 204 {string} string ""
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts/{accountID} [delete].
+func (h *Handler) HandleDeleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := serviceAccountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteServiceAccount(r.Context(), accountID); err != nil {
+		writeServiceError(w, err, "failed to delete service account")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListServiceAccountTokens godoc
+// @Summary List a service account's tokens
+// @Tags Service Accounts
+// @Produce json
+// @Security BearerAuth
+// @Param accountID path string true "Service account ID"
+// @Success 200 {array} models.PersonalAccessToken
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts/{accountID}/tokens [get].
+func (h *Handler) HandleListServiceAccountTokens(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := serviceAccountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	if _, err := h.svc.GetServiceAccount(r.Context(), accountID); err != nil {
+		writeServiceError(w, err, "failed to load service account")
+		return
+	}
+	tokens, err := h.svc.ListPersonalAccessTokens(r.Context(), accountID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list tokens")
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// HandleCreateServiceAccountToken godoc
+// @Summary Create a service account token
+// @Description Issues a personal access token for the service account. Scopes default to all of
+// @Description the account's scopes and cannot exceed them or the caller's own scopes. The token
+// @Description is only returned once.
+// @Tags Service Accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param accountID path string true "Service account ID"
+// @Param payload body CreatePersonalAccessTokenRequest true "Token parameters"
+// @Success 201 {object} PersonalAccessTokenResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts/{accountID}/tokens [post].
+func (h *Handler) HandleCreateServiceAccountToken(w http.ResponseWriter, r *http.Request) {
+	claims, _, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	accountID, ok := serviceAccountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req CreatePersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return
+	}
+	account, err := h.svc.GetServiceAccount(r.Context(), accountID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load service account")
+		return
+	}
+	if account.Disabled {
+		writeError(w, http.StatusConflict, "service account is disabled")
+		return
+	}
+
+	scopes := dedupeStrings(req.Scopes)
+	if len(scopes) == 0 {
+		scopes = account.Scopes
+	}
+	if !validScopes(w, scopes) {
+		return
+	}
+	if invalid := missingScopes(scopes, account.Scopes); len(invalid) > 0 {
+		writeError(w, http.StatusForbidden, "requested scopes exceed the service account's scopes")
+		return
+	}
+	if invalid := missingScopes(scopes, claims.Scopes); len(invalid) > 0 {
+		writeError(w, http.StatusForbidden, "requested scopes exceed current permissions")
+		return
+	}
+	expiresAt, ok := patExpiresAt(w, req.ExpiresIn)
+	if !ok {
+		return
+	}
+
+	params := service.CreateTokenParams{
+		UserID:        account.ID,
+		Name:          req.Name,
+		Scopes:        scopes,
+		TokenType:     auth.TokenTypePAT,
+		JTI:           uuid.NewString(),
+		ExpiresAt:     expiresAt,
+		ResponseCache: req.ResponseCache,
+	}
+	tokenMeta, err := h.svc.CreatePersonalAccessToken(r.Context(), params)
+	if err != nil {
+		writeServiceError(w, err, "failed to create token")
+		return
+	}
+	tokenString, err := h.issuer.IssuePAT(
+		r.Context(),
+		account.ID,
+		account.Sub,
+		account.Email,
+		account.Role,
+		scopes,
+		params.JTI,
+		expiresAt,
+		params.ResponseCache,
+	)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to issue token")
+		return
+	}
+	writeJSON(w, http.StatusCreated, PersonalAccessTokenResponse{
+		PersonalAccessToken: tokenMeta,
+		Token:               tokenString,
+	})
+}
+
+// HandleRevokeServiceAccountToken godoc
+// @Summary Revoke a service account token
+// @Tags Service Accounts
+// @Security BearerAuth
+// @Param accountID path string true "Service account ID"
+// @Param tokenID path string true "Token ID"
+// @Success 204 {string} string ""
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/service-accounts/{accountID}/tokens/{tokenID} [delete].
+func (h *Handler) HandleRevokeServiceAccountToken(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := serviceAccountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	tokenID, err := uuid.Parse(strings.TrimSpace(r.PathValue("tokenID")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid token id")
+		return
+	}
+	if err = h.svc.RevokePersonalAccessToken(r.Context(), accountID, tokenID); err != nil {
+		writeServiceError(w, err, "failed to revoke token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceAccountScopes validates the scopes requested for a service account. They must be known
+// and held by the caller, so admins cannot create accounts more powerful than themselves.
+func serviceAccountScopes(w http.ResponseWriter, claims *auth.Claims, requested []string) ([]string, bool) {
+	scopes := dedupeStrings(requested)
+	if len(scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "at least one scope is required")
+		return nil, false
+	}
+	if !validScopes(w, scopes) {
+		return nil, false
+	}
+	if invalid := missingScopes(scopes, claims.Scopes); len(invalid) > 0 {
+		writeError(w, http.StatusForbidden, "requested scopes exceed current permissions")
+		return nil, false
+	}
+	return scopes, true
+}
+
+func serviceAccountIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	accountID, err := uuid.Parse(strings.TrimSpace(r.PathValue("accountID")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid service account id")
+		return uuid.Nil, false
+	}
+	return accountID, true
+}
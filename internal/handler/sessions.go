@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+	"github.com/rhajizada/llamero/internal/service"
+)
+
+// sessionTokens are the credentials handed out when a session starts or is refreshed.
+type sessionTokens struct {
+	accessToken  string
+	refreshToken string
+}
+
+// startSession records a login session for the user and issues its first access and refresh
+// tokens.
+func (h *Handler) startSession(
+	ctx context.Context,
+	r *http.Request,
+	user repository.User,
+) (sessionTokens, error) {
+	jti := uuid.NewString()
+	refreshToken := auth.NewRefreshToken()
+	session, err := h.svc.CreateSession(ctx, service.CreateSessionParams{
+		UserID:       user.ID,
+		JTI:          jti,
+		RefreshToken: refreshToken,
+		UserAgent:    r.UserAgent(),
+		IPAddress:    clientIP(r),
+		ExpiresAt:    time.Now().Add(h.cfg.Sessions.RefreshTTL),
+	})
+	if err != nil {
+		return sessionTokens{}, err
+	}
+	accessToken, err := h.issuer.Issue(ctx, user.ID, user.Sub, user.Email, user.Role, user.Scopes, session.ID, jti)
+	if err != nil {
+		return sessionTokens{}, err
+	}
+	return sessionTokens{accessToken: accessToken, refreshToken: refreshToken}, nil
+}
+
+// HandleRefreshSession godoc
+// @Summary Refresh a login session
+// @Description Exchanges a refresh token for a new access token. The refresh token is single use:
+// @Description the response carries its replacement, and presenting a replaced refresh token again
+// @Description revokes the whole session. Browser sessions kept in cookies send no body; the
+// @Description request must carry the CSRF cookie in X-CSRF-Token, and the new tokens are set as
+// @Description cookies with an empty 204 response.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param payload body models.RefreshSessionRequest false "Refresh token"
+// @Success 200 {object} models.SessionTokenResponse
+// @Success 204 {string} string ""
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/refresh [post].
+func (h *Handler) HandleRefreshSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	refreshToken, fromCookie, ok := h.refreshTokenFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	jti := uuid.NewString()
+	next := auth.NewRefreshToken()
+	session, user, err := h.svc.RefreshSession(ctx, refreshToken, next, jti)
+	if err != nil {
+		if fromCookie {
+			h.cookies.Clear(w)
+		}
+		writeServiceError(w, err, "failed to refresh session")
+		return
+	}
+
+	accessToken, err := h.issuer.Issue(ctx, user.ID, user.Sub, user.Email, user.Role, user.Scopes, session.ID, jti)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "issue token", "err", err)
+		writeError(w, http.StatusInternalServerError, "token issuance failed")
+		return
+	}
+
+	if fromCookie {
+		h.cookies.Set(w, accessToken, next)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.SessionTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.cfg.JWT.TTL.Seconds()),
+		RefreshToken: next,
+	})
+}
+
+// HandleLogout godoc
+// @Summary Log out of a session
+// @Description Revokes the session a refresh token belongs to. Access tokens of the session are
+// @Description rejected from then on. Unknown refresh tokens are ignored. Browser sessions kept in
+// @Description cookies send no body and X-CSRF-Token instead; their cookies are cleared.
+// @Tags Auth
+// @Accept json
+// @Param payload body models.RefreshSessionRequest false "Refresh token"
+// @Success 204 {string} string ""
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/logout [post].
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	refreshToken, fromCookie, ok := h.refreshTokenFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.RevokeSessionByRefreshToken(r.Context(), refreshToken); err != nil {
+		writeServiceError(w, err, "failed to log out")
+		return
+	}
+	if fromCookie {
+		h.cookies.Clear(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refreshTokenFromRequest reads the refresh token from the JSON body or, failing that, from the
+// session cookies. Cookie-borne tokens need a matching CSRF header.
+func (h *Handler) refreshTokenFromRequest(w http.ResponseWriter, r *http.Request) (string, bool, bool) {
+	var req models.RefreshSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return "", false, false
+	}
+	if token := strings.TrimSpace(req.RefreshToken); token != "" {
+		return token, false, true
+	}
+
+	token := h.cookies.RefreshToken(r)
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "refresh_token is required")
+		return "", false, false
+	}
+	if !h.cookies.CheckCSRF(r) {
+		writeError(w, http.StatusForbidden, "missing or invalid CSRF token")
+		return "", false, false
+	}
+	return token, true, true
+}
+
+// HandleListSessions godoc
+// @Summary List active login sessions
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Session
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/profile/sessions [get].
+func (h *Handler) HandleListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	if claims.Type == auth.TokenTypePAT {
+		writeError(w, http.StatusForbidden, "personal access tokens cannot manage sessions")
+		return
+	}
+
+	sessions, err := h.svc.ListSessions(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list sessions")
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == claims.SessionID
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+// HandleRevokeSession godoc
+// @Summary Revoke a login session
+// @Tags Profile
+// @Security BearerAuth
+// @Param sessionID path string true "Session ID"
+// @Success 204 {string} string ""
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/profile/sessions/{sessionID} [delete].
+func (h *Handler) HandleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	if claims.Type == auth.TokenTypePAT {
+		writeError(w, http.StatusForbidden, "personal access tokens cannot manage sessions")
+		return
+	}
+
+	sessionID, err := uuid.Parse(strings.TrimSpace(r.PathValue("sessionID")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+	if err = h.svc.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		writeServiceError(w, err, "failed to revoke session")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clientIP prefers the address nginx reports over the proxy's own.
+func clientIP(r *http.Request) string {
+	return firstNonEmpty(strings.TrimSpace(r.Header.Get("X-Real-IP")), remoteIP(r.RemoteAddr))
+}
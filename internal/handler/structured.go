@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/rhajizada/llamero/internal/service"
+	"github.com/rhajizada/llamero/internal/structured"
+)
+
+const paramResponseFormat = "response_format"
+
+func writeResponseFormatError(w http.ResponseWriter, err error) {
+	writeAppError(w, &service.Error{
+		Status:  http.StatusBadRequest,
+		Param:   paramResponseFormat,
+		Message: err.Error(),
+	})
+}
+
+// forwardStructured sends a strict json_schema request and checks the completion against the
+// schema, asking the backend again while attempts remain. Streamed and failed responses are
+// relayed without validation.
+func (h *Handler) forwardStructured(
+	w http.ResponseWriter,
+	r *http.Request,
+	format *structured.Format,
+	send func(http.ResponseWriter),
+) {
+	var validationErr error
+	for attempt := range max(h.cfg.Structured.MaxAttempts, 1) {
+		held := &heldWriter{ResponseWriter: w, header: http.Header{}}
+		send(held)
+		if !held.held {
+			return
+		}
+		if validationErr = format.Validate(held.body.Bytes()); validationErr == nil {
+			held.release()
+			return
+		}
+		h.logger.WarnContext(r.Context(), "completion does not match response_format schema",
+			"schema", format.Name, "attempt", attempt+1, "err", validationErr)
+	}
+	writeAppError(w, &service.Error{
+		Status:  http.StatusBadGateway,
+		Code:    service.ErrorCodeInvalidStructuredOutput,
+		Param:   paramResponseFormat,
+		Message: "completion does not match the response_format schema: " + validationErr.Error(),
+	})
+}
+
+// heldWriter holds a successful JSON completion until it has been validated. Other responses,
+// including errors and event streams, pass straight through.
+type heldWriter struct {
+	http.ResponseWriter
+
+	header      http.Header
+	status      int
+	wroteHeader bool
+	held        bool
+	body        bytes.Buffer
+}
+
+func (hw *heldWriter) Header() http.Header {
+	return hw.header
+}
+
+func (hw *heldWriter) WriteHeader(status int) {
+	if hw.wroteHeader {
+		return
+	}
+	hw.wroteHeader = true
+	hw.status = status
+	if status == http.StatusOK && strings.HasPrefix(hw.header.Get("Content-Type"), contentTypeJSON) {
+		hw.held = true
+		return
+	}
+	maps.Copy(hw.ResponseWriter.Header(), hw.header)
+	hw.ResponseWriter.WriteHeader(status)
+}
+
+func (hw *heldWriter) Write(p []byte) (int, error) {
+	if !hw.wroteHeader {
+		hw.WriteHeader(http.StatusOK)
+	}
+	if hw.held {
+		return hw.body.Write(p)
+	}
+	return hw.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying writer so streamed responses can still be flushed.
+func (hw *heldWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// release writes the held completion to the underlying writer.
+func (hw *heldWriter) release() {
+	maps.Copy(hw.ResponseWriter.Header(), hw.header)
+	hw.ResponseWriter.WriteHeader(hw.status)
+	_, _ = hw.ResponseWriter.Write(hw.body.Bytes())
+}
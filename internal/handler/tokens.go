@@ -26,6 +26,8 @@ type CreatePersonalAccessTokenRequest struct {
 	Name      string   `json:"name"`
 	Scopes    []string `json:"scopes"`
 	ExpiresIn int64    `json:"expires_in,omitempty"`
+	// ResponseCache opts the token in to cached LLM responses for models whose profile enables caching.
+	ResponseCache bool `json:"response_cache,omitempty"`
 } // @name CreatePersonalAccessTokenRequest
 
 // PersonalAccessTokenResponse documents PAT responses.
@@ -41,8 +43,8 @@ type PersonalAccessTokenResponse struct {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {array} models.PersonalAccessToken
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /api/profile/tokens [get].
 func (h *Handler) HandleListTokens(w http.ResponseWriter, r *http.Request) {
 	claims, userID, ok := h.extractUserContext(w, r)
@@ -58,7 +60,7 @@ func (h *Handler) HandleListTokens(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusInternalServerError, "failed to list tokens")
 		}
@@ -75,10 +77,10 @@ func (h *Handler) HandleListTokens(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param tokenID path string true "Token ID"
 // @Success 200 {object} models.PersonalAccessToken
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /api/profile/tokens/{tokenID} [get].
 func (h *Handler) HandleGetToken(w http.ResponseWriter, r *http.Request) {
 	claims, userID, ok := h.extractUserContext(w, r)
@@ -100,7 +102,7 @@ func (h *Handler) HandleGetToken(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusInternalServerError, "failed to load token")
 		}
@@ -118,10 +120,10 @@ func (h *Handler) HandleGetToken(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param payload body CreatePersonalAccessTokenRequest true "PAT request"
 // @Success 201 {object} PersonalAccessTokenResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /api/profile/tokens [post].
 func (h *Handler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
 	claims, userID, ok := h.extractUserContext(w, r)
@@ -150,39 +152,34 @@ func (h *Handler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "at least one scope is required")
 		return
 	}
+	if !validScopes(w, scopes) {
+		return
+	}
 	if invalid := missingScopes(scopes, claims.Scopes); len(invalid) > 0 {
 		writeError(w, http.StatusForbidden, "requested scopes exceed current permissions")
 		return
 	}
 
-	expiresIn := req.ExpiresIn
-	if expiresIn == 0 {
-		expiresIn = int64(defaultPATTTL.Seconds())
-	}
-	if expiresIn < int64(minPATTTL.Seconds()) {
-		writeError(w, http.StatusBadRequest, "expires_in must be at least 1 hour")
-		return
-	}
-	if expiresIn > int64(maxPATTTL.Seconds()) {
-		writeError(w, http.StatusBadRequest, "expires_in cannot exceed 90 days")
+	expiresAt, ok := patExpiresAt(w, req.ExpiresIn)
+	if !ok {
 		return
 	}
-	expiresAt := time.Now().Add(time.Duration(expiresIn) * time.Second)
 
 	params := service.CreateTokenParams{
-		UserID:    userID,
-		Name:      name,
-		Scopes:    scopes,
-		TokenType: auth.TokenTypePAT,
-		JTI:       uuid.NewString(),
-		ExpiresAt: expiresAt,
+		UserID:        userID,
+		Name:          name,
+		Scopes:        scopes,
+		TokenType:     auth.TokenTypePAT,
+		JTI:           uuid.NewString(),
+		ExpiresAt:     expiresAt,
+		ResponseCache: req.ResponseCache,
 	}
 
 	tokenMeta, err := h.svc.CreatePersonalAccessToken(r.Context(), params)
 	if err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusInternalServerError, "failed to create token")
 		}
@@ -195,6 +192,7 @@ func (h *Handler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tokenString, err := h.issuer.IssuePAT(
+		r.Context(),
 		userID,
 		externalSub,
 		claims.Email,
@@ -202,6 +200,7 @@ func (h *Handler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
 		scopes,
 		params.JTI,
 		expiresAt,
+		params.ResponseCache,
 	)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to issue token")
@@ -221,10 +220,10 @@ func (h *Handler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
 // @Security BearerAuth
 // @Param tokenID path string true "Token ID"
 // @Success 204 {string} string ""
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /api/profile/tokens/{tokenID} [delete].
 func (h *Handler) HandleDeleteToken(w http.ResponseWriter, r *http.Request) {
 	claims, userID, ok := h.extractUserContext(w, r)
@@ -245,7 +244,7 @@ func (h *Handler) HandleDeleteToken(w http.ResponseWriter, r *http.Request) {
 	if err = h.svc.RevokePersonalAccessToken(r.Context(), userID, tokenID); err != nil {
 		var appErr *service.Error
 		if errors.As(err, &appErr) {
-			writeError(w, appErr.Code, appErr.Message)
+			writeAppError(w, appErr)
 		} else {
 			writeError(w, http.StatusInternalServerError, "failed to revoke token")
 		}
@@ -271,17 +270,40 @@ func (h *Handler) extractUserContext(w http.ResponseWriter, r *http.Request) (*a
 	return claims, userID, true
 }
 
-func missingScopes(requested, allowed []string) []string {
-	if len(requested) == 0 {
-		return nil
+// validScopes rejects unknown scopes with a 400.
+func validScopes(w http.ResponseWriter, scopes []string) bool {
+	for _, scope := range scopes {
+		if err := auth.ValidateScope(scope); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return false
+		}
 	}
-	allowedSet := make(map[string]struct{}, len(allowed))
-	for _, scope := range allowed {
-		allowedSet[scope] = struct{}{}
+	return true
+}
+
+// patExpiresAt turns a requested PAT lifetime in seconds into an expiry, applying the default
+// and bounds.
+func patExpiresAt(w http.ResponseWriter, expiresIn int64) (time.Time, bool) {
+	if expiresIn == 0 {
+		expiresIn = int64(defaultPATTTL.Seconds())
+	}
+	if expiresIn < int64(minPATTTL.Seconds()) {
+		writeError(w, http.StatusBadRequest, "expires_in must be at least 1 hour")
+		return time.Time{}, false
 	}
+	if expiresIn > int64(maxPATTTL.Seconds()) {
+		writeError(w, http.StatusBadRequest, "expires_in cannot exceed 90 days")
+		return time.Time{}, false
+	}
+	return time.Now().Add(time.Duration(expiresIn) * time.Second), true
+}
+
+// missingScopes returns the requested scopes that no allowed scope covers. Wildcards count, so
+// "llm:*" allows "llm:chat".
+func missingScopes(requested, allowed []string) []string {
 	var invalid []string
 	for _, scope := range requested {
-		if _, ok := allowedSet[scope]; !ok {
+		if !auth.HasScope(allowed, scope) {
 			invalid = append(invalid, scope)
 		}
 	}
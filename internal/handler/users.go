@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+const (
+	defaultUserListLimit = 20
+	maxUserListLimit     = 100
+)
+
+// HandleListUsers godoc
+// @Summary List users
+// @Description Lists users newest first. The search matches a substring of the email or display
+// @Description name, or the exact IdP subject.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search term"
+// @Param limit query int false "Maximum number of users to return (1-100, default 20)"
+// @Param offset query int false "Number of users to skip"
+// @Success 200 {object} models.UserList
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users [get].
+func (h *Handler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := defaultUserListLimit
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxUserListLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+	offset := 0
+	if raw := strings.TrimSpace(query.Get("offset")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+		offset = int(parsed)
+	}
+
+	users, total, err := h.svc.ListUsers(r.Context(), query.Get("q"), int32(limit), int32(offset))
+	if err != nil {
+		writeServiceError(w, err, "failed to list users")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.NewUserList(users, total, limit, offset))
+}
+
+// HandleGetUser godoc
+// @Summary Get a user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID} [get].
+func (h *Handler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.svc.GetUser(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load user")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// HandleListUserTokens godoc
+// @Summary List a user's personal access tokens
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {array} models.PersonalAccessToken
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID}/tokens [get].
+func (h *Handler) HandleListUserTokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	tokens, err := h.svc.ListPersonalAccessTokens(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "failed to list tokens")
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// HandleSetUserAccess godoc
+// @Summary Override a user's role and scopes
+// @Description Replaces the role and scopes mapped from the user's IdP groups until the override
+// @Description is removed. Without scopes the user gets the role's scopes. The resulting scopes
+// @Description must be held by the caller. Personal access tokens with scopes outside the new set
+// @Description are revoked; sessions pick up the change when they are next refreshed.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param payload body models.UserAccessRequest true "Role and scopes"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID}/access [put].
+func (h *Handler) HandleSetUserAccess(w http.ResponseWriter, r *http.Request) {
+	claims, _, ok := h.extractUserContext(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req models.UserAccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		return
+	}
+
+	role := strings.TrimSpace(req.Role)
+	roleScopes, known := h.roles.Scopes(role)
+	if !known {
+		writeError(w, http.StatusBadRequest, "unknown role "+strconv.Quote(role))
+		return
+	}
+	params := repository.UpdateUserAccessParams{
+		ID:           userID,
+		Role:         role,
+		Scopes:       roleScopes,
+		RoleOverride: &role,
+	}
+	if len(req.Scopes) > 0 {
+		scopes := dedupeStrings(req.Scopes)
+		if !validScopes(w, scopes) {
+			return
+		}
+		params.Scopes = scopes
+		params.ScopesOverride = scopes
+	}
+	if invalid := missingScopes(params.Scopes, claims.Scopes); len(invalid) > 0 {
+		writeError(w, http.StatusForbidden, "requested scopes exceed current permissions")
+		return
+	}
+
+	user, err := h.svc.UpdateUserAccess(r.Context(), params)
+	if err != nil {
+		writeServiceError(w, err, "failed to update user")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// HandleResetUserAccess godoc
+// @Summary Remove a user's role override
+// @Description Restores the role and scopes mapped from the IdP groups seen at the user's last login.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID}/access [delete].
+func (h *Handler) HandleResetUserAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	current, err := h.svc.GetUser(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load user")
+		return
+	}
+
+	role, scopes, mapped := h.roles.Resolve(current.Groups)
+	if !mapped {
+		role, scopes = h.roles.Default()
+	}
+	user, err := h.svc.UpdateUserAccess(r.Context(), repository.UpdateUserAccessParams{
+		ID:     userID,
+		Role:   role,
+		Scopes: scopes,
+	})
+	if err != nil {
+		writeServiceError(w, err, "failed to update user")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// HandleDisableUser godoc
+// @Summary Disable a user
+// @Description Blocks logins, revokes the user's sessions and rejects their personal access tokens.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID}/disable [post].
+func (h *Handler) HandleDisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, true)
+}
+
+// HandleEnableUser godoc
+// @Summary Enable a disabled user
+// @Description Lets the user log in again. Personal access tokens that have not expired work again.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID}/enable [post].
+func (h *Handler) HandleEnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, false)
+}
+
+func (h *Handler) setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	var userID uuid.UUID
+	var ok bool
+	if disabled {
+		userID, ok = h.otherUserIDFromPath(w, r)
+	} else {
+		userID, ok = userIDFromPath(w, r)
+	}
+	if !ok {
+		return
+	}
+	user, err := h.svc.SetUserDisabled(r.Context(), userID, disabled)
+	if err != nil {
+		writeServiceError(w, err, "failed to update user")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// HandleDeleteUser godoc
+// @Summary Delete a user
+// @Description Deletes the user with their personal access tokens, sessions, files and batches. A
+// @Description user who logs in again is recreated from their IdP groups.
+// @Tags Users
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 204 {string} string ""
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/users/{userID} [delete].
+func (h *Handler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.otherUserIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteUser(r.Context(), userID); err != nil {
+		writeServiceError(w, err, "failed to delete user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func userIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(strings.TrimSpace(r.PathValue("userID")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// otherUserIDFromPath is userIDFromPath for actions admins may not take on themselves.
+func (h *Handler) otherUserIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	_, callerID, ok := h.extractUserContext(w, r)
+	if !ok {
+		return uuid.Nil, false
+	}
+	userID, ok := userIDFromPath(w, r)
+	if !ok {
+		return uuid.Nil, false
+	}
+	if userID == callerID {
+		writeError(w, http.StatusBadRequest, "cannot disable or delete your own account")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -8,46 +8,61 @@ import (
 	"strings"
 
 	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/models"
 	"github.com/rhajizada/llamero/internal/service"
 )
 
 const bearerTokenParts = 2
 
-// Authz applies JWT verification and scope enforcement to HTTP handlers.
+// Authz applies JWT verification and scope enforcement to HTTP handlers. Tokens come from the
+// Authorization header or, for browser sessions, from the session cookie.
 type Authz struct {
-	verifier     *auth.TokenVerifier
-	patValidator PATValidator
+	verifier       *auth.TokenVerifier
+	tokenValidator TokenValidator
+	cookies        *auth.SessionCookies
 }
 
-// PATValidator checks whether a PAT is still active.
-type PATValidator interface {
+// TokenValidator checks whether a PAT or login session is still active.
+type TokenValidator interface {
 	ValidatePAT(ctx context.Context, claims *auth.Claims) error
+	ValidateSession(ctx context.Context, claims *auth.Claims) error
 }
 
-// NewAuthz constructs a scope-aware middleware set.
-func NewAuthz(verifier *auth.TokenVerifier, patValidator PATValidator) *Authz {
+// NewAuthz constructs a scope-aware middleware set. Cookies may be nil when browser sessions
+// are not kept in cookies.
+func NewAuthz(verifier *auth.TokenVerifier, tokenValidator TokenValidator, cookies *auth.SessionCookies) *Authz {
 	return &Authz{
-		verifier:     verifier,
-		patValidator: patValidator,
+		verifier:       verifier,
+		tokenValidator: tokenValidator,
+		cookies:        cookies,
 	}
 }
 
-// Require ensures the incoming request bears a token containing every supplied scope.
+// Require ensures the incoming request bears a token granting every supplied scope. Scopes narrowed
+// to models or backends pass here; handlers check the concrete resource against
+// RequiredScopesFromContext once it is known. It panics on scopes missing from auth.KnownScopes,
+// which would otherwise be impossible to grant.
 func (a *Authz) Require(scopes ...string) func(http.Handler) http.Handler {
 	required := dedupe(scopes)
+	for _, scope := range required {
+		if err := auth.ValidateScope(scope); err != nil {
+			panic(err)
+		}
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims, ok := authenticate(w, r, a.verifier, a.patValidator)
+			claims, ok := a.authenticate(w, r)
 			if !ok {
 				return
 			}
 
-			if !claims.HasScopes(required) {
-				writeJSON(w, http.StatusForbidden, map[string]string{"error": "insufficient scope"})
+			if !claims.GrantsScopes(required) {
+				writeError(w, http.StatusForbidden, "insufficient scope")
 				return
 			}
 
 			ctx := context.WithValue(r.Context(), claimsKey{}, claims)
+			ctx = context.WithValue(ctx, requiredScopesKey{}, required)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -59,37 +74,48 @@ func ClaimsFromContext(ctx context.Context) (*auth.Claims, bool) {
 	return claims, ok
 }
 
-type claimsKey struct{}
+// RequiredScopesFromContext returns the scopes the route required.
+func RequiredScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(requiredScopesKey{}).([]string)
+	return scopes
+}
 
-var errPATValidationUnavailable = errors.New("pat validation unavailable")
+type (
+	claimsKey         struct{}
+	requiredScopesKey struct{}
+)
 
-func authenticate(
-	w http.ResponseWriter,
-	r *http.Request,
-	verifier *auth.TokenVerifier,
-	patValidator PATValidator,
-) (*auth.Claims, bool) {
+var errTokenValidationUnavailable = errors.New("token validation unavailable")
+
+func (a *Authz) authenticate(w http.ResponseWriter, r *http.Request) (*auth.Claims, bool) {
 	token := bearerToken(r.Header.Get("Authorization"))
 	if token == "" {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing bearer token"})
-		return nil, false
+		token = a.cookies.AccessToken(r)
+		if token == "" {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return nil, false
+		}
+		if !a.cookies.CheckCSRF(r) {
+			writeError(w, http.StatusForbidden, "missing or invalid CSRF token")
+			return nil, false
+		}
 	}
 
-	claims, err := verifier.Verify(r.Context(), token)
+	claims, err := a.verifier.Verify(r.Context(), token)
 	if err != nil {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		writeError(w, http.StatusUnauthorized, "invalid token")
 		return nil, false
 	}
 
-	if err = validatePAT(r.Context(), claims, patValidator); err != nil {
+	if err = validateToken(r.Context(), claims, a.tokenValidator); err != nil {
 		var appErr *service.Error
 		switch {
 		case errors.As(err, &appErr):
-			writeJSON(w, appErr.Code, map[string]string{"error": appErr.Message})
-		case errors.Is(err, errPATValidationUnavailable):
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			writeAppError(w, appErr)
+		case errors.Is(err, errTokenValidationUnavailable):
+			writeError(w, http.StatusInternalServerError, err.Error())
 		default:
-			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			writeError(w, http.StatusUnauthorized, "invalid token")
 		}
 		return nil, false
 	}
@@ -97,16 +123,19 @@ func authenticate(
 	return claims, true
 }
 
-func validatePAT(ctx context.Context, claims *auth.Claims, patValidator PATValidator) error {
-	if claims.Type != auth.TokenTypePAT {
+func validateToken(ctx context.Context, claims *auth.Claims, tokenValidator TokenValidator) error {
+	if claims.Type != auth.TokenTypePAT && claims.Type != auth.TokenTypeSession {
 		return nil
 	}
 
-	if patValidator == nil {
-		return errPATValidationUnavailable
+	if tokenValidator == nil {
+		return errTokenValidationUnavailable
 	}
 
-	return patValidator.ValidatePAT(ctx, claims)
+	if claims.Type == auth.TokenTypePAT {
+		return tokenValidator.ValidatePAT(ctx, claims)
+	}
+	return tokenValidator.ValidateSession(ctx, claims)
 }
 
 func bearerToken(header string) string {
@@ -140,6 +169,16 @@ func dedupe(values []string) []string {
 	return out
 }
 
+// writeError renders an OpenAI-style error envelope with the type implied by the status.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, models.NewErrorResponse(message, service.ErrorTypeForStatus(status), "", ""))
+}
+
+// writeAppError renders a service.Error with its type, code and parameter.
+func writeAppError(w http.ResponseWriter, err *service.Error) {
+	writeJSON(w, err.Status, models.NewErrorResponse(err.Message, err.ErrorType(), err.Code, err.Param))
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
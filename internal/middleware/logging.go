@@ -23,6 +23,11 @@ func (w *wrappedWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 }
 
+// Unwrap exposes the underlying writer so http.ResponseController can flush streamed responses.
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Logging returns a middleware that emits structured request logs.
 func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
 	if logger == nil {
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+const (
+	fileObject     = "file"
+	batchObject    = "batch"
+	listObject     = "list"
+	batchErrorCode = "batch_failed"
+)
+
+// File describes an uploaded file.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+} // @name File
+
+// FileList is the response envelope for listing files.
+type FileList struct {
+	Object string `json:"object"`
+	Data   []File `json:"data"`
+} // @name FileList
+
+// FileDeleteResponse confirms a file deletion.
+type FileDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+} // @name FileDeleteResponse
+
+// CreateBatchRequest starts a batch over an uploaded JSONL file.
+type CreateBatchRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"          example:"/v1/chat/completions"`
+	CompletionWindow string            `json:"completion_window" example:"24h"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+} // @name CreateBatchRequest
+
+// Batch describes a batch job and its progress.
+type Batch struct {
+	ID               string             `json:"id"`
+	Object           string             `json:"object"`
+	Endpoint         string             `json:"endpoint"`
+	InputFileID      string             `json:"input_file_id"`
+	CompletionWindow string             `json:"completion_window"`
+	Status           string             `json:"status"`
+	OutputFileID     *string            `json:"output_file_id"`
+	ErrorFileID      *string            `json:"error_file_id"`
+	Errors           *BatchErrors       `json:"errors"`
+	RequestCounts    BatchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string  `json:"metadata,omitempty"`
+	CreatedAt        int64              `json:"created_at"`
+	ExpiresAt        int64              `json:"expires_at"`
+	InProgressAt     *int64             `json:"in_progress_at"`
+	FinalizingAt     *int64             `json:"finalizing_at"`
+	CompletedAt      *int64             `json:"completed_at"`
+	FailedAt         *int64             `json:"failed_at"`
+	ExpiredAt        *int64             `json:"expired_at"`
+	CancellingAt     *int64             `json:"cancelling_at"`
+	CancelledAt      *int64             `json:"cancelled_at"`
+} // @name Batch
+
+// BatchRequestCounts reports batch progress.
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+} // @name BatchRequestCounts
+
+// BatchErrors lists errors that stopped a batch.
+type BatchErrors struct {
+	Object string       `json:"object"`
+	Data   []BatchError `json:"data"`
+} // @name BatchErrors
+
+// BatchError describes a single batch-level error.
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+} // @name BatchError
+
+// BatchList is the response envelope for listing batches.
+type BatchList struct {
+	Object string  `json:"object"`
+	Data   []Batch `json:"data"`
+} // @name BatchList
+
+// NewFile builds the API representation of a stored file.
+func NewFile(id uuid.UUID, filename, purpose string, size int64, createdAt time.Time) File {
+	return File{
+		ID:        id.String(),
+		Object:    fileObject,
+		Bytes:     size,
+		CreatedAt: createdAt.Unix(),
+		Filename:  filename,
+		Purpose:   purpose,
+	}
+}
+
+// NewFileList wraps files in a list envelope.
+func NewFileList(files []File) FileList {
+	return FileList{Object: listObject, Data: files}
+}
+
+// NewFileDeleteResponse confirms the deletion of a file.
+func NewFileDeleteResponse(id uuid.UUID) FileDeleteResponse {
+	return FileDeleteResponse{ID: id.String(), Object: fileObject, Deleted: true}
+}
+
+// NewBatchFromRepo converts a stored batch into its API representation.
+func NewBatchFromRepo(b repository.Batch) Batch {
+	out := Batch{
+		ID:               b.ID.String(),
+		Object:           batchObject,
+		Endpoint:         b.Endpoint,
+		InputFileID:      b.InputFileID.String(),
+		CompletionWindow: b.CompletionWindow,
+		Status:           b.Status,
+		OutputFileID:     uuidString(b.OutputFileID),
+		ErrorFileID:      uuidString(b.ErrorFileID),
+		RequestCounts: BatchRequestCounts{
+			Total:     int(b.RequestTotal),
+			Completed: int(b.RequestCompleted),
+			Failed:    int(b.RequestFailed),
+		},
+		CreatedAt:    b.CreatedAt.Unix(),
+		ExpiresAt:    b.ExpiresAt.Unix(),
+		InProgressAt: unixTime(b.InProgressAt),
+		FinalizingAt: unixTime(b.FinalizingAt),
+		CompletedAt:  unixTime(b.CompletedAt),
+		FailedAt:     unixTime(b.FailedAt),
+		ExpiredAt:    unixTime(b.ExpiredAt),
+		CancellingAt: unixTime(b.CancellingAt),
+		CancelledAt:  unixTime(b.CancelledAt),
+	}
+	if len(b.Metadata) > 0 {
+		_ = json.Unmarshal(b.Metadata, &out.Metadata)
+	}
+	if b.ErrorMessage != nil {
+		out.Errors = &BatchErrors{
+			Object: listObject,
+			Data:   []BatchError{{Code: batchErrorCode, Message: *b.ErrorMessage}},
+		}
+	}
+	return out
+}
+
+// NewBatchList wraps batches in a list envelope.
+func NewBatchList(batches []Batch) BatchList {
+	return BatchList{Object: listObject, Data: batches}
+}
+
+func uuidString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	value := id.String()
+	return &value
+}
+
+func unixTime(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	value := t.Unix()
+	return &value
+}
@@ -0,0 +1,33 @@
+package models
+
+// ErrorResponse is the OpenAI-compatible error envelope.
+type ErrorResponse struct {
+	Error ErrorObject `json:"error"`
+} // @name ErrorResponse
+
+// ErrorObject describes a single API error.
+type ErrorObject struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+} // @name ErrorObject
+
+// NewErrorResponse builds an error envelope. Empty code and param values are rendered as null.
+func NewErrorResponse(message, errType, code, param string) ErrorResponse {
+	return ErrorResponse{
+		Error: ErrorObject{
+			Message: message,
+			Type:    errType,
+			Param:   optionalString(param),
+			Code:    optionalString(code),
+		},
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+const jobObject = "job"
+
+// CreateChatJobRequest submits a chat completion to run asynchronously.
+type CreateChatJobRequest struct {
+	Request     ChatCompletionRequest `json:"request"`
+	CallbackURL string                `json:"callback_url,omitempty" example:"https://example.com/hooks/llamero"`
+} // @name CreateChatJobRequest
+
+// Job describes an asynchronous generation job.
+type Job struct {
+	ID         string          `json:"id"`
+	Object     string          `json:"object"`
+	Kind       string          `json:"kind"`
+	Model      string          `json:"model"`
+	Status     string          `json:"status"`
+	CreatedAt  int64           `json:"created_at"`
+	StartedAt  *int64          `json:"started_at"`
+	FinishedAt *int64          `json:"finished_at"`
+	Result     json.RawMessage `json:"result,omitempty"  swaggertype:"object"`
+	Error      *JobError       `json:"error,omitempty"`
+	Webhook    *JobWebhook     `json:"webhook,omitempty"`
+} // @name Job
+
+// JobError reports why a job failed.
+type JobError struct {
+	StatusCode int    `json:"status_code"`
+	Message    string `json:"message"`
+} // @name JobError
+
+// JobWebhook reports delivery of the completion webhook.
+type JobWebhook struct {
+	URL         string  `json:"url"`
+	Status      *string `json:"status"`
+	Attempts    int     `json:"attempts"`
+	LastError   *string `json:"last_error,omitempty"`
+	DeliveredAt *int64  `json:"delivered_at,omitempty"`
+} // @name JobWebhook
+
+// NewJobFromRepo converts a stored job into its API representation.
+func NewJobFromRepo(j repository.Job) Job {
+	out := Job{
+		ID:         j.ID.String(),
+		Object:     jobObject,
+		Kind:       j.Kind,
+		Model:      j.Model,
+		Status:     j.Status,
+		CreatedAt:  j.CreatedAt.Unix(),
+		StartedAt:  unixTime(j.StartedAt),
+		FinishedAt: unixTime(j.FinishedAt),
+	}
+	if j.ErrorMessage != nil {
+		out.Error = &JobError{Message: *j.ErrorMessage}
+		if j.StatusCode != nil {
+			out.Error.StatusCode = int(*j.StatusCode)
+		}
+	} else if len(j.Result) > 0 {
+		out.Result = j.Result
+	}
+	if j.CallbackURL != nil {
+		out.Webhook = &JobWebhook{
+			URL:         *j.CallbackURL,
+			Status:      j.WebhookStatus,
+			Attempts:    int(j.WebhookAttempts),
+			LastError:   j.WebhookLastError,
+			DeliveredAt: unixTime(j.WebhookDeliveredAt),
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// JSONWebKey is a public token signing key (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty" example:"sig"`
+	Alg string `json:"alg,omitempty" example:"EdDSA"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+} // @name JSONWebKey
+
+// JSONWebKeySet lists the keys Llamero tokens may be signed with.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+} // @name JSONWebKeySet
+
+// SigningKey describes a token signing key. Keys from files have no creation time; retired keys
+// verify tokens until verify_until.
+type SigningKey struct {
+	ID          string     `json:"kid"`
+	Algorithm   string     `json:"algorithm" example:"EdDSA"`
+	Source      string     `json:"source" enums:"config,database"`
+	Active      bool       `json:"active"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+	VerifyUntil *time.Time `json:"verify_until,omitempty"`
+} // @name SigningKey
@@ -5,6 +5,7 @@ type ChatCompletionRequest struct {
 	Model            string              `json:"model"`
 	Messages         []ChatMessage       `json:"messages"`
 	Stream           bool                `json:"stream,omitempty"`
+	N                *int                `json:"n,omitempty"`
 	Temperature      *float32            `json:"temperature,omitempty"`
 	TopP             *float32            `json:"top_p,omitempty"`
 	MaxTokens        *int                `json:"max_tokens,omitempty"`
@@ -15,6 +16,9 @@ type ChatCompletionRequest struct {
 	ToolChoice       any                 `json:"tool_choice,omitempty"`
 	ResponseFormat   *ResponseFormatSpec `json:"response_format,omitempty"`
 	User             string              `json:"user,omitempty"`
+	// KeepAlive and NumCtx are Llamero extensions forwarded to Ollama's native API.
+	KeepAlive any  `json:"keep_alive,omitempty" swaggertype:"string" example:"10m"`
+	NumCtx    *int `json:"num_ctx,omitempty"`
 } // @name ChatCompletionRequest
 
 // ChatMessage represents a message in a chat conversation.
@@ -53,11 +57,21 @@ type ToolCallFunction struct {
 	Arguments string `json:"arguments"`
 } // @name ToolCallFunction
 
-// ResponseFormatSpec controls structured output.
+// ResponseFormatSpec controls structured output. Type is text, json_object or json_schema.
 type ResponseFormatSpec struct {
-	Type string `json:"type"`
+	Type       string          `json:"type"                  enums:"text,json_object,json_schema"`
+	JSONSchema *JSONSchemaSpec `json:"json_schema,omitempty"`
 } // @name ResponseFormatSpec
 
+// JSONSchemaSpec describes the JSON schema a json_schema response must follow. Strict completions
+// that are not streamed are validated against the schema.
+type JSONSchemaSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      any    `json:"schema"                swaggertype:"object"`
+	Strict      *bool  `json:"strict,omitempty"`
+} // @name JSONSchemaSpec
+
 // ChatCompletionResponse represents the full response to a chat completion request.
 type ChatCompletionResponse struct {
 	ID                string                 `json:"id"`
@@ -117,6 +131,9 @@ type CompletionRequest struct {
 	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
 	BestOf           *int     `json:"best_of,omitempty"`
 	User             string   `json:"user,omitempty"`
+	// KeepAlive and NumCtx are Llamero extensions forwarded to Ollama's native API.
+	KeepAlive any  `json:"keep_alive,omitempty" swaggertype:"string" example:"10m"`
+	NumCtx    *int `json:"num_ctx,omitempty"`
 } // @name CompletionRequest
 
 // CompletionResponse represents a response from the /api/chat/completions endpoint.
@@ -2,12 +2,36 @@ package models
 
 // Model represents an LLM model resource.
 type Model struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	OwnedBy string `json:"owned_by"`
+	ID       string         `json:"id"`
+	Object   string         `json:"object"`
+	Created  int64          `json:"created"`
+	OwnedBy  string         `json:"owned_by"`
+	Details  *ModelMetadata `json:"details,omitempty"`  // Set for verbose listings and single-model lookups.
+	Backends []ModelBackend `json:"backends,omitempty"` // Set for verbose listings and single-model lookups.
 } // @name Model
 
+// ModelMetadata aggregates model details reported by every backend hosting the model.
+type ModelMetadata struct {
+	Format            string   `json:"format,omitempty"`
+	Family            string   `json:"family,omitempty"`
+	Families          []string `json:"families,omitempty"`
+	ParameterSize     string   `json:"parameter_size,omitempty"`
+	QuantizationLevel string   `json:"quantization_level,omitempty"`
+	ContextLength     int      `json:"context_length,omitempty"`
+	Capabilities      []string `json:"capabilities,omitempty"`
+} // @name ModelMetadata
+
+// ModelBackend describes the availability of a model on a single backend.
+type ModelBackend struct {
+	BackendID     string   `json:"backend_id"`
+	Healthy       bool     `json:"healthy"`
+	Installed     bool     `json:"installed"`
+	Loaded        bool     `json:"loaded"`
+	Digest        string   `json:"digest,omitempty"`
+	ContextLength int      `json:"context_length,omitempty"`
+	Capabilities  []string `json:"capabilities,omitempty"`
+} // @name ModelBackend
+
 // ModelList is the response envelope for listing models.
 type ModelList struct {
 	Object string  `json:"object"`
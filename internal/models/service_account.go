@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// ServiceAccount is a non-human principal that authenticates with personal access tokens. The
+// owner is the person responsible for it; deleting the owner leaves the account and its tokens
+// in place.
+type ServiceAccount struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	OwnerID     *uuid.UUID `json:"owner_id"`
+	Scopes      []string   `json:"scopes"`
+	Disabled    bool       `json:"disabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+} // @name ServiceAccount
+
+// NewServiceAccountFromRepo converts the user record backing a service account.
+func NewServiceAccountFromRepo(u repository.User) ServiceAccount {
+	return ServiceAccount{
+		ID:          u.ID,
+		Name:        u.Sub,
+		Description: u.Description,
+		OwnerID:     u.OwnerID,
+		Scopes:      u.Scopes,
+		Disabled:    u.Disabled,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+// CreateServiceAccountRequest defines a new service account. The owner defaults to the caller.
+type CreateServiceAccountRequest struct {
+	Name        string     `json:"name" example:"ci-pipeline"`
+	Description string     `json:"description,omitempty"`
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty"`
+	Scopes      []string   `json:"scopes"`
+} // @name CreateServiceAccountRequest
+
+// UpdateServiceAccountRequest replaces a service account's description, owner and scopes.
+// Tokens holding scopes the account no longer has are revoked.
+type UpdateServiceAccountRequest struct {
+	Description string     `json:"description,omitempty"`
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty"`
+	Scopes      []string   `json:"scopes"`
+} // @name UpdateServiceAccountRequest
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// Session describes an active login session. Current marks the session of the calling token.
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	UserAgent  *string    `json:"user_agent"`
+	IPAddress  *string    `json:"ip_address"`
+	Current    bool       `json:"current"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+} // @name Session
+
+// NewSessionFromRepo converts a session record without its refresh token hashes.
+func NewSessionFromRepo(s repository.Session) Session {
+	return Session{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		ExpiresAt:  s.ExpiresAt,
+		LastUsedAt: s.LastUsedAt,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+// RefreshSessionRequest exchanges a refresh token for a new access token.
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token"`
+} // @name RefreshSessionRequest
+
+// SessionTokenResponse carries a new access token and the refresh token that replaces the one
+// that was used.
+type SessionTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+} // @name SessionTokenResponse
@@ -9,3 +9,25 @@ type User = repository.User // @name User
 func NewUserFromRepo(u repository.User) User {
 	return u
 }
+
+// UserList is a page of users.
+type UserList struct {
+	Object string `json:"object"`
+	Data   []User `json:"data"`
+	// Total counts every user matching the search, across all pages.
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+} // @name UserList
+
+// NewUserList wraps a page of users in a list envelope.
+func NewUserList(users []User, total int64, limit, offset int) UserList {
+	return UserList{Object: listObject, Data: users, Total: total, Limit: limit, Offset: offset}
+}
+
+// UserAccessRequest overrides the role and scopes a user gets from their IdP groups. With only a
+// role, the user gets that role's scopes.
+type UserAccessRequest struct {
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes,omitempty"`
+} // @name UserAccessRequest
@@ -0,0 +1,4 @@
+// Package ollamanative translates OpenAI-compatible requests that carry Ollama-native options
+// (keep_alive, num_ctx, a completion system prompt, a structured output format) into native
+// /api/chat and /api/generate calls and converts the native responses back.
+package ollamanative
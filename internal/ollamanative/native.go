@@ -0,0 +1,195 @@
+package ollamanative
+
+import (
+	"crypto/rand"
+	"encoding/json"
+
+	"github.com/ollama/ollama/api"
+	"github.com/ollama/ollama/openai"
+
+	"github.com/rhajizada/llamero/internal/profiles"
+)
+
+const (
+	ChatPath     = "/api/chat"
+	GeneratePath = "/api/generate"
+
+	optionNumCtx = "num_ctx"
+
+	chatCompletionIDPrefix = "chatcmpl-"
+	completionIDPrefix     = "cmpl-"
+)
+
+// Options routes a request through Ollama's native API because it carries options the
+// OpenAI-compatible layer ignores.
+type Options struct {
+	Kind    profiles.Kind
+	Runtime profiles.Runtime
+	// Format is passed as the native format field: a JSON schema or "json".
+	Format json.RawMessage
+}
+
+// Request is a native Ollama request built from an OpenAI-compatible body.
+type Request struct {
+	Path      string
+	Payload   any
+	Stream    bool
+	Converter Converter
+}
+
+// Converter turns native Ollama responses back into OpenAI-compatible payloads.
+type Converter interface {
+	// Chunks converts a single NDJSON stream line. done reports the final line.
+	Chunks(line []byte) (payloads []any, done bool, err error)
+	// Completion converts a non-streaming response body.
+	Completion(body []byte) (any, error)
+}
+
+// For returns the native routing options for a request, or nil when the OpenAI-compatible
+// endpoint can serve it as is. Requests asking for structured output always go native so the
+// backend receives the format directly.
+func For(kind profiles.Kind, runtime profiles.Runtime, format json.RawMessage) *Options {
+	if !runtime.Native() && len(format) == 0 {
+		return nil
+	}
+	return &Options{Kind: kind, Runtime: runtime, Format: format}
+}
+
+// Build converts an OpenAI-compatible request body into its native equivalent.
+func Build(body []byte, opts Options) (Request, error) {
+	var keepAlive *api.Duration
+	if opts.Runtime.KeepAlive != nil {
+		keepAlive = &api.Duration{Duration: *opts.Runtime.KeepAlive}
+	}
+
+	if opts.Kind == profiles.KindCompletion {
+		var req openai.CompletionRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return Request{}, err
+		}
+		generate, err := openai.FromCompleteRequest(req)
+		if err != nil {
+			return Request{}, err
+		}
+		generate.System = opts.Runtime.System
+		generate.KeepAlive = keepAlive
+		if len(opts.Format) > 0 {
+			generate.Format = opts.Format
+		}
+		setNumCtx(generate.Options, opts.Runtime.NumCtx)
+		converter := &completionConverter{
+			id:           completionIDPrefix + rand.Text(),
+			includeUsage: req.StreamOptions != nil && req.StreamOptions.IncludeUsage,
+		}
+		return Request{Path: GeneratePath, Payload: generate, Stream: req.Stream, Converter: converter}, nil
+	}
+
+	var req openai.ChatCompletionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return Request{}, err
+	}
+	chat, err := openai.FromChatRequest(req)
+	if err != nil {
+		return Request{}, err
+	}
+	chat.KeepAlive = keepAlive
+	if len(opts.Format) > 0 {
+		chat.Format = opts.Format
+	}
+	setNumCtx(chat.Options, opts.Runtime.NumCtx)
+	converter := &chatConverter{
+		id:           chatCompletionIDPrefix + rand.Text(),
+		includeUsage: req.StreamOptions != nil && req.StreamOptions.IncludeUsage,
+	}
+	return Request{Path: ChatPath, Payload: chat, Stream: req.Stream, Converter: converter}, nil
+}
+
+// ErrorMessage extracts the message from an Ollama error body, or returns an empty string. Both
+// the native {"error": "..."} shape and the OpenAI error object are understood.
+func ErrorMessage(payload []byte) string {
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil || len(body.Error) == 0 {
+		return ""
+	}
+	var message string
+	if err := json.Unmarshal(body.Error, &message); err == nil {
+		return message
+	}
+	var object struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body.Error, &object); err != nil {
+		return ""
+	}
+	return object.Message
+}
+
+func setNumCtx(options map[string]any, numCtx int) {
+	if numCtx > 0 && options != nil {
+		options[optionNumCtx] = numCtx
+	}
+}
+
+type chatConverter struct {
+	id           string
+	includeUsage bool
+	toolCallSent bool
+}
+
+func (c *chatConverter) Chunks(line []byte) ([]any, bool, error) {
+	var resp api.ChatResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, false, err
+	}
+	chunk := openai.ToChunk(c.id, resp, c.toolCallSent)
+	if len(chunk.Choices) > 0 && len(chunk.Choices[0].Delta.ToolCalls) > 0 {
+		c.toolCallSent = true
+	}
+	payloads := []any{chunk}
+	if resp.Done && c.includeUsage {
+		usage := openai.ToUsage(resp)
+		chunk.Usage = &usage
+		chunk.Choices = []openai.ChunkChoice{}
+		payloads = append(payloads, chunk)
+	}
+	return payloads, resp.Done, nil
+}
+
+func (c *chatConverter) Completion(body []byte) (any, error) {
+	var resp api.ChatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return openai.ToChatCompletion(c.id, resp), nil
+}
+
+type completionConverter struct {
+	id           string
+	includeUsage bool
+}
+
+func (c *completionConverter) Chunks(line []byte) ([]any, bool, error) {
+	var resp api.GenerateResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, false, err
+	}
+	chunk := openai.ToCompleteChunk(c.id, resp)
+	payloads := []any{chunk}
+	if resp.Done && c.includeUsage {
+		usage := openai.ToUsageGenerate(resp)
+		chunk.Usage = &usage
+		chunk.Choices = []openai.CompleteChunkChoice{}
+		payloads = append(payloads, chunk)
+	}
+	return payloads, resp.Done, nil
+}
+
+func (c *completionConverter) Completion(body []byte) (any, error) {
+	var resp api.GenerateResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return openai.ToCompletion(c.id, resp), nil
+}
@@ -0,0 +1,3 @@
+// Package profiles applies server-side request policy (defaults, system prompts and clamps) to
+// LLM requests for configured models and aliases.
+package profiles
@@ -0,0 +1,342 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/contextwindow"
+)
+
+const (
+	fieldModel       = "model"
+	fieldMessages    = "messages"
+	fieldTemperature = "temperature"
+	fieldTopP        = "top_p"
+	fieldMaxTokens   = "max_tokens"
+	fieldStop        = "stop"
+	fieldKeepAlive   = "keep_alive"
+	fieldNumCtx      = "num_ctx"
+
+	messageRoleSystem = "system"
+)
+
+// Kind identifies the shape of the request being rewritten.
+type Kind int
+
+const (
+	// KindChat is an OpenAI-compatible chat completion request.
+	KindChat Kind = iota
+	// KindCompletion is an OpenAI-compatible legacy completion request.
+	KindCompletion
+)
+
+// Profile describes request policy for a backend model and the aliases that resolve to it.
+type Profile struct {
+	Name         string   `yaml:"name"`
+	Model        string   `yaml:"model"`
+	Aliases      []string `yaml:"aliases"`
+	SystemPrompt string   `yaml:"system_prompt"`
+	Defaults     Defaults `yaml:"defaults"`
+	Clamp        Clamp    `yaml:"clamp"`
+	Cache        Cache    `yaml:"cache"`
+	Context      Context  `yaml:"context"`
+
+	keepAlive     *time.Duration
+	cacheTTL      time.Duration
+	contextPolicy *contextwindow.Policy
+}
+
+// Context keeps chat conversations within the model's context window, which is num_ctx when the
+// request or profile sets it and the model's context length otherwise.
+type Context struct {
+	// Policy is reject or trim; empty leaves oversized conversations to the backend.
+	Policy string `yaml:"policy"`
+	// Reserve keeps tokens free for the completion when the request sets no max_tokens.
+	Reserve int `yaml:"reserve"`
+	// Estimator names the token estimator: chars (default) or words.
+	Estimator string `yaml:"estimator"`
+}
+
+// Cache enables the response cache for tokens that opt in to it.
+type Cache struct {
+	Enabled bool   `yaml:"enabled"`
+	TTL     string `yaml:"ttl"`
+}
+
+// Defaults are filled in when a request omits the corresponding field.
+type Defaults struct {
+	Temperature *float64 `yaml:"temperature"`
+	MaxTokens   *int     `yaml:"max_tokens"`
+	Stop        []string `yaml:"stop"`
+	KeepAlive   string   `yaml:"keep_alive"`
+	NumCtx      *int     `yaml:"num_ctx"`
+}
+
+// Range bounds a numeric parameter. A nil end leaves that side unbounded.
+type Range struct {
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+// Clamp bounds request parameters. Out-of-range values are clamped rather than rejected.
+type Clamp struct {
+	Temperature Range `yaml:"temperature"`
+	TopP        Range `yaml:"top_p"`
+	MaxTokens   *int  `yaml:"max_tokens"`
+	NumCtx      *int  `yaml:"num_ctx"`
+	// RoleMaxTokens caps max_tokens for callers with the given role.
+	RoleMaxTokens map[string]int `yaml:"role_max_tokens"`
+}
+
+// Request holds the top-level fields of an LLM request body. Fields that are not rewritten are
+// forwarded verbatim.
+type Request map[string]json.RawMessage
+
+// FieldError reports a request field that could not be interpreted.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Runtime carries Ollama-native options resolved for a request. Ollama's OpenAI-compatible
+// endpoints ignore them, so callers must use the native API when Native reports true.
+type Runtime struct {
+	KeepAlive *time.Duration
+	NumCtx    int
+	// System is the mandatory system prompt for legacy completions, which have no messages.
+	System string
+}
+
+// Native reports whether the request needs Ollama's native API to honour its options.
+func (r Runtime) Native() bool {
+	return r.KeepAlive != nil || r.NumCtx > 0 || r.System != ""
+}
+
+// Prepare applies the profile matching the request model, if any, and extracts the Ollama-native
+// options (keep_alive, num_ctx) carried by the request. The request is rewritten in place.
+func (s *Store) Prepare(req Request, kind Kind, role string) (Runtime, error) {
+	var model string
+	if _, err := req.decode(fieldModel, &model); err != nil {
+		return Runtime{}, err
+	}
+
+	profile, ok := s.Match(model)
+	if ok {
+		if err := profile.apply(req, kind, role); err != nil {
+			return Runtime{}, err
+		}
+	}
+
+	runtime, err := extractRuntime(req)
+	if err != nil {
+		return Runtime{}, err
+	}
+	if ok && kind == KindCompletion {
+		runtime.System = profile.SystemPrompt
+	}
+	return runtime, nil
+}
+
+// ContextPolicy returns the context window policy for chat requests, if the profile has one.
+func (p *Profile) ContextPolicy() (contextwindow.Policy, bool) {
+	if p.contextPolicy == nil {
+		return contextwindow.Policy{}, false
+	}
+	return *p.contextPolicy, true
+}
+
+// CacheTTL reports whether responses for the profile may be cached and for how long. A zero TTL
+// means the server default applies.
+func (p *Profile) CacheTTL() (time.Duration, bool) {
+	return p.cacheTTL, p.Cache.Enabled
+}
+
+func (p *Profile) apply(req Request, kind Kind, role string) error {
+	if err := req.set(fieldModel, p.Model); err != nil {
+		return err
+	}
+	if kind == KindChat && p.SystemPrompt != "" {
+		if err := prependSystemMessage(req, p.SystemPrompt); err != nil {
+			return err
+		}
+	}
+	if err := p.applyDefaults(req); err != nil {
+		return err
+	}
+	return p.applyClamps(req, role)
+}
+
+func (p *Profile) applyDefaults(req Request) error {
+	defaults := map[string]any{}
+	if p.Defaults.Temperature != nil {
+		defaults[fieldTemperature] = *p.Defaults.Temperature
+	}
+	if p.Defaults.MaxTokens != nil {
+		defaults[fieldMaxTokens] = *p.Defaults.MaxTokens
+	}
+	if len(p.Defaults.Stop) > 0 {
+		defaults[fieldStop] = p.Defaults.Stop
+	}
+	if p.keepAlive != nil {
+		defaults[fieldKeepAlive] = p.keepAlive.String()
+	}
+	if p.Defaults.NumCtx != nil {
+		defaults[fieldNumCtx] = *p.Defaults.NumCtx
+	}
+	for field, value := range defaults {
+		if req.present(field) {
+			continue
+		}
+		if err := req.set(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Profile) applyClamps(req Request, role string) error {
+	for field, bounds := range map[string]Range{fieldTemperature: p.Clamp.Temperature, fieldTopP: p.Clamp.TopP} {
+		if err := clampFloat(req, field, bounds); err != nil {
+			return err
+		}
+	}
+
+	maxTokens := 0
+	if p.Clamp.MaxTokens != nil {
+		maxTokens = *p.Clamp.MaxTokens
+	}
+	if roleCap, ok := p.Clamp.RoleMaxTokens[role]; ok && (maxTokens == 0 || roleCap < maxTokens) {
+		maxTokens = roleCap
+	}
+	// A capped request without max_tokens would otherwise generate until the context is full.
+	if err := clampInt(req, fieldMaxTokens, maxTokens, true); err != nil {
+		return err
+	}
+
+	if p.Clamp.NumCtx != nil {
+		return clampInt(req, fieldNumCtx, *p.Clamp.NumCtx, false)
+	}
+	return nil
+}
+
+func clampFloat(req Request, field string, bounds Range) error {
+	if bounds.Min == nil && bounds.Max == nil {
+		return nil
+	}
+	var value float64
+	found, err := req.decode(field, &value)
+	if err != nil || !found {
+		return err
+	}
+	clamped := value
+	if bounds.Min != nil {
+		clamped = max(clamped, *bounds.Min)
+	}
+	if bounds.Max != nil {
+		clamped = min(clamped, *bounds.Max)
+	}
+	if clamped == value {
+		return nil
+	}
+	return req.set(field, clamped)
+}
+
+func clampInt(req Request, field string, limit int, fillMissing bool) error {
+	if limit <= 0 {
+		return nil
+	}
+	var value int
+	found, err := req.decode(field, &value)
+	if err != nil {
+		return err
+	}
+	if (found && value > limit) || (!found && fillMissing) {
+		return req.set(field, limit)
+	}
+	return nil
+}
+
+func prependSystemMessage(req Request, prompt string) error {
+	var messages []json.RawMessage
+	if _, err := req.decode(fieldMessages, &messages); err != nil {
+		return err
+	}
+	system, err := json.Marshal(map[string]string{"role": messageRoleSystem, "content": prompt})
+	if err != nil {
+		return err
+	}
+	return req.set(fieldMessages, append([]json.RawMessage{system}, messages...))
+}
+
+// extractRuntime removes the Ollama-native fields from the request and returns their values.
+func extractRuntime(req Request) (Runtime, error) {
+	var runtime Runtime
+	if _, err := req.decode(fieldNumCtx, &runtime.NumCtx); err != nil {
+		return Runtime{}, err
+	}
+	if runtime.NumCtx < 0 {
+		return Runtime{}, &FieldError{Field: fieldNumCtx, Err: errors.New("must be positive")}
+	}
+	if raw, ok := req[fieldKeepAlive]; ok && !isNull(raw) {
+		keepAlive, err := parseKeepAlive(raw)
+		if err != nil {
+			return Runtime{}, &FieldError{Field: fieldKeepAlive, Err: err}
+		}
+		runtime.KeepAlive = &keepAlive
+	}
+	delete(req, fieldNumCtx)
+	delete(req, fieldKeepAlive)
+	return runtime, nil
+}
+
+// parseKeepAlive accepts the same forms as Ollama: a duration string or a number of seconds.
+func parseKeepAlive(raw json.RawMessage) (time.Duration, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return time.ParseDuration(text)
+	}
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err != nil {
+		return 0, errors.New("must be a duration string or a number of seconds")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (r Request) present(field string) bool {
+	raw, ok := r[field]
+	return ok && !isNull(raw)
+}
+
+func (r Request) decode(field string, dest any) (bool, error) {
+	if !r.present(field) {
+		return false, nil
+	}
+	if err := json.Unmarshal(r[field], dest); err != nil {
+		return false, &FieldError{Field: field, Err: err}
+	}
+	return true, nil
+}
+
+func (r Request) set(field string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return &FieldError{Field: field, Err: err}
+	}
+	r[field] = raw
+	return nil
+}
+
+func isNull(raw json.RawMessage) bool {
+	return strings.TrimSpace(string(raw)) == "null"
+}
@@ -0,0 +1,149 @@
+package profiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rhajizada/llamero/internal/contextwindow"
+)
+
+// DefaultPath defines where the server looks for model profiles if no override is supplied.
+const DefaultPath = "config/profiles.yaml"
+
+// Store resolves request models to their profiles.
+type Store struct {
+	byModel map[string]*Profile
+}
+
+type document struct {
+	Profiles []Profile `yaml:"profiles"`
+}
+
+// Load reads the YAML document from disk and builds a Store.
+func Load(path string) (*Store, error) {
+	if strings.TrimSpace(path) == "" {
+		path = DefaultPath
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("read profiles file: %w", err)
+	}
+
+	var doc document
+	if err = yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse profiles file: %w", err)
+	}
+
+	byModel := make(map[string]*Profile)
+	for i := range doc.Profiles {
+		profile := &doc.Profiles[i]
+		if err = profile.normalize(); err != nil {
+			return nil, err
+		}
+		for _, name := range append([]string{profile.Model}, profile.Aliases...) {
+			if existing, ok := byModel[name]; ok {
+				return nil, fmt.Errorf("profiles %q and %q both claim model %q", existing.Name, profile.Name, name)
+			}
+			byModel[name] = profile
+		}
+	}
+
+	return &Store{byModel: byModel}, nil
+}
+
+// Match returns the profile configured for a model name or alias.
+func (s *Store) Match(model string) (*Profile, bool) {
+	profile, ok := s.byModel[strings.TrimSpace(model)]
+	return profile, ok
+}
+
+func (p *Profile) normalize() error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Model = strings.TrimSpace(p.Model)
+	if p.Model == "" {
+		return fmt.Errorf("profile %q must set model", p.Name)
+	}
+	if p.Name == "" {
+		p.Name = p.Model
+	}
+
+	aliases := make([]string, 0, len(p.Aliases))
+	for _, alias := range p.Aliases {
+		alias = strings.TrimSpace(alias)
+		if alias != "" && alias != p.Model {
+			aliases = append(aliases, alias)
+		}
+	}
+	p.Aliases = aliases
+
+	if p.Defaults.KeepAlive != "" {
+		keepAlive, err := time.ParseDuration(p.Defaults.KeepAlive)
+		if err != nil {
+			return fmt.Errorf("profile %q has invalid keep_alive: %w", p.Name, err)
+		}
+		p.keepAlive = &keepAlive
+	}
+	if p.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(p.Cache.TTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("profile %q has invalid cache ttl %q", p.Name, p.Cache.TTL)
+		}
+		p.cacheTTL = ttl
+	}
+	if err := p.normalizeContext(); err != nil {
+		return err
+	}
+	if p.Defaults.MaxTokens != nil && *p.Defaults.MaxTokens <= 0 {
+		return fmt.Errorf("profile %q default max_tokens must be positive", p.Name)
+	}
+	if p.Defaults.NumCtx != nil && *p.Defaults.NumCtx <= 0 {
+		return fmt.Errorf("profile %q default num_ctx must be positive", p.Name)
+	}
+	return p.Clamp.validate(p.Name)
+}
+
+func (p *Profile) normalizeContext() error {
+	policy := strings.TrimSpace(p.Context.Policy)
+	switch policy {
+	case "":
+		return nil
+	case contextwindow.PolicyReject, contextwindow.PolicyTrim:
+	default:
+		return fmt.Errorf("profile %q has unknown context policy %q", p.Name, p.Context.Policy)
+	}
+	if p.Context.Reserve < 0 {
+		return fmt.Errorf("profile %q context reserve must not be negative", p.Name)
+	}
+	estimator, err := contextwindow.NewEstimator(p.Context.Estimator)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	p.contextPolicy = &contextwindow.Policy{Mode: policy, Estimator: estimator}
+	return nil
+}
+
+func (c Clamp) validate(profile string) error {
+	for field, bounds := range map[string]Range{fieldTemperature: c.Temperature, fieldTopP: c.TopP} {
+		if bounds.Min != nil && bounds.Max != nil && *bounds.Min > *bounds.Max {
+			return fmt.Errorf("profile %q clamp for %s has min greater than max", profile, field)
+		}
+	}
+	if c.MaxTokens != nil && *c.MaxTokens <= 0 {
+		return fmt.Errorf("profile %q max_tokens clamp must be positive", profile)
+	}
+	if c.NumCtx != nil && *c.NumCtx <= 0 {
+		return fmt.Errorf("profile %q num_ctx clamp must be positive", profile)
+	}
+	for role, limit := range c.RoleMaxTokens {
+		if limit <= 0 {
+			return fmt.Errorf("profile %q max_tokens cap for role %q must be positive", profile, role)
+		}
+	}
+	return nil
+}
@@ -32,11 +32,22 @@ type BackendStatus struct {
 	UpdatedAt    time.Time        `json:"updated_at"`
 }
 
-// ModelInfo stores metadata about a single model.
+// ModelInfo stores metadata about a single model installed or loaded on a backend.
 type ModelInfo struct {
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	OwnedBy   string    `json:"owned_by"`
+	Name              string    `json:"name"`
+	CreatedAt         time.Time `json:"created_at"`
+	OwnedBy           string    `json:"owned_by"`
+	Digest            string    `json:"digest,omitempty"`
+	Size              int64     `json:"size,omitempty"`
+	Format            string    `json:"format,omitempty"`
+	Family            string    `json:"family,omitempty"`
+	Families          []string  `json:"families,omitempty"`
+	ParameterSize     string    `json:"parameter_size,omitempty"`
+	QuantizationLevel string    `json:"quantization_level,omitempty"`
+	ContextLength     int       `json:"context_length,omitempty"`
+	Capabilities      []string  `json:"capabilities,omitempty"`
+	Installed         bool      `json:"installed,omitempty"`
+	Loaded            bool      `json:"loaded,omitempty"`
 }
 
 // SaveBackend stores backend metadata and health score.
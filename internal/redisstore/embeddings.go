@@ -0,0 +1,88 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	embeddingCacheKey  = "embcache:%s:%s:%s"
+	embeddingScanCount = 500
+	globSpecialChars   = `*?[]\`
+)
+
+// EmbeddingCacheKey builds the key of a cached vector for a model digest and input hash.
+func EmbeddingCacheKey(model, digest, hash string) string {
+	return fmt.Sprintf(embeddingCacheKey, model, digest, hash)
+}
+
+// GetEmbeddings loads cached vectors for the supplied keys. Misses are returned as nil entries.
+func (s *Store) GetEmbeddings(ctx context.Context, keys []string) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if raw, ok := value.(string); ok {
+			out[i] = json.RawMessage(raw)
+		}
+	}
+	return out, nil
+}
+
+// SaveEmbeddings stores vectors keyed by their cache keys.
+func (s *Store) SaveEmbeddings(ctx context.Context, vectors map[string]json.RawMessage, ttl time.Duration) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	pipe := s.client.Pipeline()
+	for key, vector := range vectors {
+		pipe.Set(ctx, key, []byte(vector), ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DeleteEmbeddings removes every cached vector computed by a model digest.
+func (s *Store) DeleteEmbeddings(ctx context.Context, model, digest string) error {
+	pattern := EmbeddingCacheKey(escapeGlob(model), escapeGlob(digest), "*")
+	iter := s.client.Scan(ctx, 0, pattern, embeddingScanCount).Iterator()
+	var batch []string
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) >= embeddingScanCount {
+			if err := s.client.Unlink(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.client.Unlink(ctx, batch...).Err()
+}
+
+func escapeGlob(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(globSpecialChars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	modelShowKey = "model:show:%s"
+	modelShowTTL = 7 * 24 * time.Hour
+)
+
+// ModelShowInfo caches the parts of Ollama's /api/show response that are expensive to fetch.
+// Entries are keyed by model digest, so they never go stale; the TTL only reclaims space.
+type ModelShowInfo struct {
+	Family            string    `json:"family,omitempty"`
+	Families          []string  `json:"families,omitempty"`
+	ParameterSize     string    `json:"parameter_size,omitempty"`
+	QuantizationLevel string    `json:"quantization_level,omitempty"`
+	ContextLength     int       `json:"context_length,omitempty"`
+	Capabilities      []string  `json:"capabilities,omitempty"`
+	FetchedAt         time.Time `json:"fetched_at"`
+}
+
+// GetModelShow loads cached show data for a model digest.
+func (s *Store) GetModelShow(ctx context.Context, digest string) (ModelShowInfo, bool, error) {
+	raw, err := s.client.Get(ctx, fmt.Sprintf(modelShowKey, digest)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ModelShowInfo{}, false, nil
+	}
+	if err != nil {
+		return ModelShowInfo{}, false, err
+	}
+	var info ModelShowInfo
+	if err = json.Unmarshal(raw, &info); err != nil {
+		return ModelShowInfo{}, false, err
+	}
+	return info, true, nil
+}
+
+// SaveModelShow caches show data for a model digest.
+func (s *Store) SaveModelShow(ctx context.Context, digest string, info ModelShowInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, fmt.Sprintf(modelShowKey, digest), data, modelShowTTL).Err()
+}
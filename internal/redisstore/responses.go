@@ -0,0 +1,46 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const responseCacheKey = "llm:cache:%s"
+
+// CachedResponse is a stored LLM response. Streaming responses keep their raw SSE body.
+type CachedResponse struct {
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	StoredAt    time.Time `json:"stored_at"`
+}
+
+// GetResponse loads a cached LLM response by request hash.
+func (s *Store) GetResponse(ctx context.Context, hash string) (CachedResponse, bool, error) {
+	raw, err := s.client.Get(ctx, fmt.Sprintf(responseCacheKey, hash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return CachedResponse{}, false, nil
+	}
+	if err != nil {
+		return CachedResponse{}, false, err
+	}
+	var resp CachedResponse
+	if err = json.Unmarshal(raw, &resp); err != nil {
+		return CachedResponse{}, false, err
+	}
+	return resp, true, nil
+}
+
+// SaveResponse stores an LLM response under its request hash.
+func (s *Store) SaveResponse(ctx context.Context, hash string, resp CachedResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, fmt.Sprintf(responseCacheKey, hash), data, ttl).Err()
+}
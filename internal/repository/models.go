@@ -10,30 +10,136 @@ import (
 	"github.com/google/uuid"
 )
 
+type Batch struct {
+	ID               uuid.UUID  `json:"id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Role             string     `json:"role"`
+	Endpoint         string     `json:"endpoint"`
+	InputFileID      uuid.UUID  `json:"input_file_id"`
+	OutputFileID     *uuid.UUID `json:"output_file_id"`
+	ErrorFileID      *uuid.UUID `json:"error_file_id"`
+	CompletionWindow string     `json:"completion_window"`
+	Status           string     `json:"status"`
+	ErrorMessage     *string    `json:"error_message"`
+	Metadata         []byte     `json:"metadata"`
+	RequestTotal     int32      `json:"request_total"`
+	RequestCompleted int32      `json:"request_completed"`
+	RequestFailed    int32      `json:"request_failed"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	InProgressAt     *time.Time `json:"in_progress_at"`
+	FinalizingAt     *time.Time `json:"finalizing_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	FailedAt         *time.Time `json:"failed_at"`
+	ExpiredAt        *time.Time `json:"expired_at"`
+	CancellingAt     *time.Time `json:"cancelling_at"`
+	CancelledAt      *time.Time `json:"cancelled_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type BatchResult struct {
+	BatchID      uuid.UUID `json:"batch_id"`
+	Line         int32     `json:"line"`
+	CustomID     string    `json:"custom_id"`
+	StatusCode   int32     `json:"status_code"`
+	Body         []byte    `json:"body"`
+	ErrorMessage *string   `json:"error_message"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type File struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Filename  string    `json:"filename"`
+	Purpose   string    `json:"purpose"`
+	Bytes     int64     `json:"bytes"`
+	Content   []byte    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Job struct {
+	ID                 uuid.UUID  `json:"id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	Role               string     `json:"role"`
+	Kind               string     `json:"kind"`
+	Model              string     `json:"model"`
+	Request            []byte     `json:"request"`
+	Status             string     `json:"status"`
+	StatusCode         *int32     `json:"status_code"`
+	Result             []byte     `json:"result"`
+	ErrorMessage       *string    `json:"error_message"`
+	CallbackURL        *string    `json:"callback_url"`
+	WebhookStatus      *string    `json:"webhook_status"`
+	WebhookAttempts    int32      `json:"webhook_attempts"`
+	WebhookLastError   *string    `json:"webhook_last_error"`
+	WebhookDeliveredAt *time.Time `json:"webhook_delivered_at"`
+	StartedAt          *time.Time `json:"started_at"`
+	FinishedAt         *time.Time `json:"finished_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type Session struct {
+	ID               uuid.UUID  `json:"id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Jti              string     `json:"jti"`
+	RefreshTokenHash string     `json:"refresh_token_hash"`
+	UserAgent        *string    `json:"user_agent"`
+	IPAddress        *string    `json:"ip_address"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	Revoked          bool       `json:"revoked"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type SessionRetiredRefreshToken struct {
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	SessionID        uuid.UUID `json:"session_id"`
+	RetiredAt        time.Time `json:"retired_at"`
+}
+
+type SigningKey struct {
+	Kid         string     `json:"kid"`
+	Algorithm   string     `json:"algorithm"`
+	PublicKey   string     `json:"public_key"`
+	PrivateKey  *string    `json:"private_key"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RetiredAt   *time.Time `json:"retired_at"`
+	VerifyUntil *time.Time `json:"verify_until"`
+}
+
 type Token struct {
-	ID         uuid.UUID  `json:"id"`
-	UserID     uuid.UUID  `json:"user_id"`
-	Name       string     `json:"name"`
-	Scopes     []string   `json:"scopes"`
-	TokenType  string     `json:"token_type"`
-	Jti        string     `json:"jti"`
-	ExpiresAt  time.Time  `json:"expires_at"`
-	Revoked    bool       `json:"revoked"`
-	LastUsedAt *time.Time `json:"last_used_at"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Name          string     `json:"name"`
+	Scopes        []string   `json:"scopes"`
+	TokenType     string     `json:"token_type"`
+	Jti           string     `json:"jti"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	Revoked       bool       `json:"revoked"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	ResponseCache bool       `json:"response_cache"`
 }
 
 type User struct {
-	ID          uuid.UUID  `json:"id"`
-	Sub         string     `json:"sub"`
-	Provider    string     `json:"provider"`
-	Email       string     `json:"email"`
-	DisplayName *string    `json:"display_name"`
-	Role        string     `json:"role"`
-	Scopes      []string   `json:"scopes"`
-	Groups      []string   `json:"groups"`
-	LastLoginAt *time.Time `json:"last_login_at"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID             uuid.UUID  `json:"id"`
+	Sub            string     `json:"sub"`
+	Provider       string     `json:"provider"`
+	Email          string     `json:"email"`
+	DisplayName    *string    `json:"display_name"`
+	Role           string     `json:"role"`
+	Scopes         []string   `json:"scopes"`
+	Groups         []string   `json:"groups"`
+	LastLoginAt    *time.Time `json:"last_login_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Disabled       bool       `json:"disabled"`
+	RoleOverride   *string    `json:"role_override"`
+	ScopesOverride []string   `json:"scopes_override"`
+	Kind           string     `json:"kind"`
+	Description    *string    `json:"description"`
+	OwnerID        *uuid.UUID `json:"owner_id"`
 }
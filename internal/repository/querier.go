@@ -6,21 +6,67 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	CancelBatch(ctx context.Context, arg CancelBatchParams) (Batch, error)
+	CountUsers(ctx context.Context, search *string) (int64, error)
+	CreateBatch(ctx context.Context, arg CreateBatchParams) (Batch, error)
+	CreateFile(ctx context.Context, arg CreateFileParams) (CreateFileRow, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (User, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) error
 	CreateToken(ctx context.Context, arg CreateTokenParams) (Token, error)
-	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteBatchResults(ctx context.Context, batchID uuid.UUID) error
+	DeleteFile(ctx context.Context, arg DeleteFileParams) (int64, error)
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	FinishBatch(ctx context.Context, arg FinishBatchParams) error
+	FinishJob(ctx context.Context, arg FinishJobParams) (Job, error)
+	GetBatchByID(ctx context.Context, arg GetBatchByIDParams) (Batch, error)
+	GetBatchForRun(ctx context.Context, id uuid.UUID) (Batch, error)
+	GetBatchStatus(ctx context.Context, id uuid.UUID) (string, error)
+	GetFileByID(ctx context.Context, arg GetFileByIDParams) (GetFileByIDRow, error)
+	GetFileContent(ctx context.Context, arg GetFileContentParams) ([]byte, error)
+	GetJobByID(ctx context.Context, arg GetJobByIDParams) (Job, error)
+	GetJobForRun(ctx context.Context, id uuid.UUID) (Job, error)
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (User, error)
+	GetSessionByID(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (Session, error)
+	GetSessionByRetiredRefreshToken(ctx context.Context, refreshTokenHash string) (Session, error)
 	GetTokenByID(ctx context.Context, arg GetTokenByIDParams) (Token, error)
 	GetTokenByJTI(ctx context.Context, jti string) (Token, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByProviderSub(ctx context.Context, arg GetUserByProviderSubParams) (User, error)
+	LatestPersonalAccessTokenExpiry(ctx context.Context) (time.Time, error)
+	ListActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	ListBatchResultLines(ctx context.Context, batchID uuid.UUID) ([]int32, error)
+	ListBatchResults(ctx context.Context, batchID uuid.UUID) ([]BatchResult, error)
+	ListBatchesByUser(ctx context.Context, arg ListBatchesByUserParams) ([]Batch, error)
+	ListFilesByUser(ctx context.Context, userID uuid.UUID) ([]ListFilesByUserRow, error)
+	ListServiceAccounts(ctx context.Context) ([]User, error)
+	ListSigningKeys(ctx context.Context) ([]SigningKey, error)
 	ListTokensByUser(ctx context.Context, userID uuid.UUID) ([]Token, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	MarkBatchFinalizing(ctx context.Context, id uuid.UUID) error
 	MarkTokenUsed(ctx context.Context, id uuid.UUID) error
+	RecordBatchResult(ctx context.Context, arg RecordBatchResultParams) error
+	RecordJobWebhookAttempt(ctx context.Context, arg RecordJobWebhookAttemptParams) error
+	RetireSessionRefreshToken(ctx context.Context, arg RetireSessionRefreshTokenParams) error
+	RetireSigningKey(ctx context.Context, arg RetireSigningKeyParams) error
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (Session, error)
 	RevokeToken(ctx context.Context, arg RevokeTokenParams) (Token, error)
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (Session, error)
+	SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) (User, error)
+	StartBatch(ctx context.Context, id uuid.UUID) error
+	StartJob(ctx context.Context, id uuid.UUID) error
+	UpdateServiceAccount(ctx context.Context, arg UpdateServiceAccountParams) (User, error)
+	UpdateUserAccess(ctx context.Context, arg UpdateUserAccessParams) (User, error)
 	UpsertUser(ctx context.Context, arg UpsertUserParams) (User, error)
 }
 
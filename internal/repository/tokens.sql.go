@@ -13,18 +13,19 @@ import (
 )
 
 const createToken = `-- name: CreateToken :one
-INSERT INTO tokens (user_id, name, scopes, token_type, jti, expires_at)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+INSERT INTO tokens (user_id, name, scopes, token_type, jti, expires_at, response_cache)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 `
 
 type CreateTokenParams struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Name      string    `json:"name"`
-	Scopes    []string  `json:"scopes"`
-	TokenType string    `json:"token_type"`
-	Jti       string    `json:"jti"`
-	ExpiresAt time.Time `json:"expires_at"`
+	UserID        uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	Scopes        []string  `json:"scopes"`
+	TokenType     string    `json:"token_type"`
+	Jti           string    `json:"jti"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	ResponseCache bool      `json:"response_cache"`
 }
 
 func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (Token, error) {
@@ -35,6 +36,7 @@ func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (Token
 		arg.TokenType,
 		arg.Jti,
 		arg.ExpiresAt,
+		arg.ResponseCache,
 	)
 	var i Token
 	err := row.Scan(
@@ -49,12 +51,13 @@ func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (Token
 		&i.LastUsedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResponseCache,
 	)
 	return i, err
 }
 
 const getTokenByID = `-- name: GetTokenByID :one
-SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 FROM tokens
 WHERE id = $1
   AND user_id = $2
@@ -80,12 +83,13 @@ func (q *Queries) GetTokenByID(ctx context.Context, arg GetTokenByIDParams) (Tok
 		&i.LastUsedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResponseCache,
 	)
 	return i, err
 }
 
 const getTokenByJTI = `-- name: GetTokenByJTI :one
-SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 FROM tokens
 WHERE jti = $1
 `
@@ -105,12 +109,13 @@ func (q *Queries) GetTokenByJTI(ctx context.Context, jti string) (Token, error)
 		&i.LastUsedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResponseCache,
 	)
 	return i, err
 }
 
 const listTokensByUser = `-- name: ListTokensByUser :many
-SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+SELECT id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 FROM tokens
 WHERE user_id = $1
   AND COALESCE(revoked, FALSE) = FALSE
@@ -138,6 +143,7 @@ func (q *Queries) ListTokensByUser(ctx context.Context, userID uuid.UUID) ([]Tok
 			&i.LastUsedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ResponseCache,
 		); err != nil {
 			return nil, err
 		}
@@ -167,7 +173,7 @@ SET revoked = TRUE,
     updated_at = now()
 WHERE id = $1
   AND user_id = $2
-RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at
+RETURNING id, user_id, name, scopes, token_type, jti, expires_at, revoked, last_used_at, created_at, updated_at, response_cache
 `
 
 type RevokeTokenParams struct {
@@ -190,6 +196,7 @@ func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) (Token
 		&i.LastUsedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ResponseCache,
 	)
 	return i, err
 }
@@ -12,17 +12,38 @@ import (
 	"github.com/google/uuid"
 )
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+WHERE kind = 'user'
+  AND (
+    $1::text IS NULL
+    OR email ILIKE '%' || $1::text || '%'
+    OR display_name ILIKE '%' || $1::text || '%'
+    OR sub = $1::text
+  )
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+func (q *Queries) CountUsers(ctx context.Context, search *string) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1 AND kind = 'user'
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at FROM users WHERE id = $1
+SELECT id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at, disabled, role_override, scopes_override, kind, description, owner_id FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -40,12 +61,18 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Disabled,
+		&i.RoleOverride,
+		&i.ScopesOverride,
+		&i.Kind,
+		&i.Description,
+		&i.OwnerID,
 	)
 	return i, err
 }
 
 const getUserByProviderSub = `-- name: GetUserByProviderSub :one
-SELECT id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at FROM users WHERE provider = $1 AND sub = $2
+SELECT id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at, disabled, role_override, scopes_override, kind, description, owner_id FROM users WHERE provider = $1 AND sub = $2
 `
 
 type GetUserByProviderSubParams struct {
@@ -68,21 +95,37 @@ func (q *Queries) GetUserByProviderSub(ctx context.Context, arg GetUserByProvide
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Disabled,
+		&i.RoleOverride,
+		&i.ScopesOverride,
+		&i.Kind,
+		&i.Description,
+		&i.OwnerID,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2
+SELECT id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at, disabled, role_override, scopes_override, kind, description, owner_id FROM users
+WHERE kind = 'user'
+  AND (
+    $1::text IS NULL
+    OR email ILIKE '%' || $1::text || '%'
+    OR display_name ILIKE '%' || $1::text || '%'
+    OR sub = $1::text
+  )
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
 `
 
 type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Search    *string `json:"search"`
+	RowOffset int32   `json:"row_offset"`
+	RowLimit  int32   `json:"row_limit"`
 }
 
 func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
-	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listUsers, arg.Search, arg.RowOffset, arg.RowLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +145,12 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.LastLoginAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Disabled,
+			&i.RoleOverride,
+			&i.ScopesOverride,
+			&i.Kind,
+			&i.Description,
+			&i.OwnerID,
 		); err != nil {
 			return nil, err
 		}
@@ -113,6 +162,96 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const setUserDisabled = `-- name: SetUserDisabled :one
+UPDATE users
+SET disabled = $2,
+    updated_at = now()
+WHERE id = $1
+  AND kind = 'user'
+RETURNING id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at, disabled, role_override, scopes_override, kind, description, owner_id
+`
+
+type SetUserDisabledParams struct {
+	ID       uuid.UUID `json:"id"`
+	Disabled bool      `json:"disabled"`
+}
+
+func (q *Queries) SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserDisabled, arg.ID, arg.Disabled)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Sub,
+		&i.Provider,
+		&i.Email,
+		&i.DisplayName,
+		&i.Role,
+		&i.Scopes,
+		&i.Groups,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Disabled,
+		&i.RoleOverride,
+		&i.ScopesOverride,
+		&i.Kind,
+		&i.Description,
+		&i.OwnerID,
+	)
+	return i, err
+}
+
+const updateUserAccess = `-- name: UpdateUserAccess :one
+UPDATE users
+SET role = $1,
+    scopes = $2,
+    role_override = $3,
+    scopes_override = $4,
+    updated_at = now()
+WHERE id = $5
+  AND kind = 'user'
+RETURNING id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at, disabled, role_override, scopes_override, kind, description, owner_id
+`
+
+type UpdateUserAccessParams struct {
+	Role           string    `json:"role"`
+	Scopes         []string  `json:"scopes"`
+	RoleOverride   *string   `json:"role_override"`
+	ScopesOverride []string  `json:"scopes_override"`
+	ID             uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserAccess(ctx context.Context, arg UpdateUserAccessParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserAccess,
+		arg.Role,
+		arg.Scopes,
+		arg.RoleOverride,
+		arg.ScopesOverride,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Sub,
+		&i.Provider,
+		&i.Email,
+		&i.DisplayName,
+		&i.Role,
+		&i.Scopes,
+		&i.Groups,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Disabled,
+		&i.RoleOverride,
+		&i.ScopesOverride,
+		&i.Kind,
+		&i.Description,
+		&i.OwnerID,
+	)
+	return i, err
+}
+
 const upsertUser = `-- name: UpsertUser :one
 INSERT INTO users (sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now(), now())
@@ -120,12 +259,12 @@ ON CONFLICT (provider, sub)
 DO UPDATE SET
     email = EXCLUDED.email,
     display_name = EXCLUDED.display_name,
-    role = EXCLUDED.role,
-    scopes = EXCLUDED.scopes,
+    role = COALESCE(users.role_override, EXCLUDED.role),
+    scopes = COALESCE(users.scopes_override, EXCLUDED.scopes),
     groups = EXCLUDED.groups,
-    last_login_at = EXCLUDED.last_login_at,
+    last_login_at = CASE WHEN users.disabled THEN users.last_login_at ELSE EXCLUDED.last_login_at END,
     updated_at = now()
-RETURNING id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at
+RETURNING id, sub, provider, email, display_name, role, scopes, groups, last_login_at, created_at, updated_at, disabled, role_override, scopes_override, kind, description, owner_id
 `
 
 type UpsertUserParams struct {
@@ -163,6 +302,12 @@ func (q *Queries) UpsertUser(ctx context.Context, arg UpsertUserParams) (User, e
 		&i.LastLoginAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Disabled,
+		&i.RoleOverride,
+		&i.ScopesOverride,
+		&i.Kind,
+		&i.Description,
+		&i.OwnerID,
 	)
 	return i, err
 }
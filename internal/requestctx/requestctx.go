@@ -41,6 +41,15 @@ func WithBackendID(ctx context.Context, backendID string) context.Context {
 	return ctx
 }
 
+// SetBackendID records the backend identifier in the metadata Ensure attached to ctx. Unlike
+// WithBackendID it does not derive a context, so handlers that only learn the backends after
+// fanning out can still report them; without Ensure it does nothing.
+func SetBackendID(ctx context.Context, backendID string) {
+	if data := dataFrom(ctx); data != nil && backendID != "" {
+		data.BackendID = backendID
+	}
+}
+
 // BackendID retrieves the backend identifier stored in the context, if present.
 func BackendID(ctx context.Context) (string, bool) {
 	if data := dataFrom(ctx); data != nil && data.BackendID != "" {
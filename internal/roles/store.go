@@ -7,15 +7,25 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/rhajizada/llamero/internal/auth"
 )
 
 // DefaultPath defines where the server looks for role mappings if no override is supplied.
 const DefaultPath = "config/roles.yaml"
 
+// Route groups that accept per-role request body limits.
+const (
+	RouteGroupLLM        = "llm"
+	RouteGroupEmbeddings = "embeddings"
+	RouteGroupAdmin      = "admin"
+)
+
 // Store provides lookup helpers for role mappings and scopes.
 type Store struct {
 	defaultRole string
 	roleScopes  map[string][]string
+	roleLimits  map[string]map[string]int64
 	groupIndex  map[string]string
 }
 
@@ -28,6 +38,9 @@ type document struct {
 type RoleEntry struct {
 	Name   string   `yaml:"name"`
 	Scopes []string `yaml:"scopes"`
+	// BodyLimits optionally overrides request body limits (in bytes) per route group.
+	// Keys are RouteGroupLLM, RouteGroupEmbeddings or RouteGroupAdmin.
+	BodyLimits map[string]int64 `yaml:"body_limits"`
 }
 
 // Load reads the YAML document from disk and builds a Store.
@@ -54,6 +67,7 @@ func Load(path string, groupMap map[string][]string) (*Store, error) {
 	}
 
 	roleScopes := make(map[string][]string, len(doc.Roles))
+	roleLimits := make(map[string]map[string]int64, len(doc.Roles))
 	groupIndex := make(map[string]string)
 
 	for _, entry := range doc.Roles {
@@ -64,7 +78,23 @@ func Load(path string, groupMap map[string][]string) (*Store, error) {
 		if len(entry.Scopes) == 0 {
 			return nil, fmt.Errorf("role %q must define at least one scope", name)
 		}
+		for _, scope := range entry.Scopes {
+			if err = auth.ValidateScope(strings.TrimSpace(scope)); err != nil {
+				return nil, fmt.Errorf("role %q: %w", name, err)
+			}
+		}
 		roleScopes[name] = dedupe(entry.Scopes)
+		for group, limit := range entry.BodyLimits {
+			if group != RouteGroupLLM && group != RouteGroupEmbeddings && group != RouteGroupAdmin {
+				return nil, fmt.Errorf("role %q sets a body limit for unknown route group %q", name, group)
+			}
+			if limit <= 0 {
+				return nil, fmt.Errorf("role %q body limit for %q must be positive", name, group)
+			}
+		}
+		if len(entry.BodyLimits) > 0 {
+			roleLimits[name] = entry.BodyLimits
+		}
 	}
 
 	if _, ok := roleScopes[doc.DefaultRole]; !ok {
@@ -87,6 +117,7 @@ func Load(path string, groupMap map[string][]string) (*Store, error) {
 	return &Store{
 		defaultRole: doc.DefaultRole,
 		roleScopes:  roleScopes,
+		roleLimits:  roleLimits,
 		groupIndex:  groupIndex,
 	}, nil
 }
@@ -112,6 +143,18 @@ func (s *Store) Default() (string, []string) {
 	return s.defaultRole, s.roleScopes[s.defaultRole]
 }
 
+// Scopes returns the scopes of a role.
+func (s *Store) Scopes(role string) ([]string, bool) {
+	scopes, ok := s.roleScopes[role]
+	return scopes, ok
+}
+
+// BodyLimit returns the role-specific request body limit for a route group, if one is set.
+func (s *Store) BodyLimit(role, group string) (int64, bool) {
+	limit, ok := s.roleLimits[role][group]
+	return limit, ok
+}
+
 func dedupe(values []string) []string {
 	seen := make(map[string]struct{}, len(values))
 	var out []string
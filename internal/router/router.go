@@ -2,6 +2,7 @@ package router
 
 import (
 	"net/http"
+	"strings"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 
@@ -27,6 +28,11 @@ func New(h *handler.Handler, authz *middleware.Authz) *Router {
 	r.Handle("/healthz", http.HandlerFunc(h.Health))
 	r.Handle("/auth/login", http.HandlerFunc(h.Login))
 	r.Handle("/auth/callback", http.HandlerFunc(h.Callback))
+	r.Handle("POST /auth/refresh", http.HandlerFunc(h.HandleRefreshSession))
+	r.Handle("POST /auth/logout", http.HandlerFunc(h.HandleLogout))
+	r.Handle("GET /.well-known/jwks.json", http.HandlerFunc(h.HandleJWKS))
+	r.Handle("GET /api/keys", http.HandlerFunc(h.HandleListSigningKeys), authz.Require("keys:list"))
+	r.Handle("POST /api/keys/rotate", http.HandlerFunc(h.HandleRotateSigningKey), authz.Require("keys:rotate"))
 	r.Handle("/api/profile", http.HandlerFunc(h.Profile), authz.Require("profile:get"))
 	r.Handle("GET /api/profile/tokens", http.HandlerFunc(h.HandleListTokens), authz.Require("profile:get"))
 	r.Handle("POST /api/profile/tokens", http.HandlerFunc(h.HandleCreateToken), authz.Require("profile:get"))
@@ -36,6 +42,64 @@ func New(h *handler.Handler, authz *middleware.Authz) *Router {
 		http.HandlerFunc(h.HandleDeleteToken),
 		authz.Require("profile:get"),
 	)
+	r.Handle("GET /api/profile/sessions", http.HandlerFunc(h.HandleListSessions), authz.Require("profile:get"))
+	r.Handle(
+		"DELETE /api/profile/sessions/{sessionID}",
+		http.HandlerFunc(h.HandleRevokeSession),
+		authz.Require("profile:get"),
+	)
+	r.Handle("GET /api/users", http.HandlerFunc(h.HandleListUsers), authz.Require("users:list"))
+	r.Handle("GET /api/users/{userID}", http.HandlerFunc(h.HandleGetUser), authz.Require("users:list"))
+	r.Handle("GET /api/users/{userID}/tokens", http.HandlerFunc(h.HandleListUserTokens), authz.Require("users:list"))
+	r.Handle("PUT /api/users/{userID}/access", http.HandlerFunc(h.HandleSetUserAccess), authz.Require("users:manage"))
+	r.Handle(
+		"DELETE /api/users/{userID}/access",
+		http.HandlerFunc(h.HandleResetUserAccess),
+		authz.Require("users:manage"),
+	)
+	r.Handle("POST /api/users/{userID}/disable", http.HandlerFunc(h.HandleDisableUser), authz.Require("users:manage"))
+	r.Handle("POST /api/users/{userID}/enable", http.HandlerFunc(h.HandleEnableUser), authz.Require("users:manage"))
+	r.Handle("DELETE /api/users/{userID}", http.HandlerFunc(h.HandleDeleteUser), authz.Require("users:manage"))
+	r.Handle(
+		"GET /api/service-accounts",
+		http.HandlerFunc(h.HandleListServiceAccounts),
+		authz.Require("service-accounts:list"),
+	)
+	r.Handle(
+		"POST /api/service-accounts",
+		http.HandlerFunc(h.HandleCreateServiceAccount),
+		authz.Require("service-accounts:manage"),
+	)
+	r.Handle(
+		"GET /api/service-accounts/{accountID}",
+		http.HandlerFunc(h.HandleGetServiceAccount),
+		authz.Require("service-accounts:list"),
+	)
+	r.Handle(
+		"PUT /api/service-accounts/{accountID}",
+		http.HandlerFunc(h.HandleUpdateServiceAccount),
+		authz.Require("service-accounts:manage"),
+	)
+	r.Handle(
+		"DELETE /api/service-accounts/{accountID}",
+		http.HandlerFunc(h.HandleDeleteServiceAccount),
+		authz.Require("service-accounts:manage"),
+	)
+	r.Handle(
+		"GET /api/service-accounts/{accountID}/tokens",
+		http.HandlerFunc(h.HandleListServiceAccountTokens),
+		authz.Require("service-accounts:list"),
+	)
+	r.Handle(
+		"POST /api/service-accounts/{accountID}/tokens",
+		http.HandlerFunc(h.HandleCreateServiceAccountToken),
+		authz.Require("service-accounts:manage"),
+	)
+	r.Handle(
+		"DELETE /api/service-accounts/{accountID}/tokens/{tokenID}",
+		http.HandlerFunc(h.HandleRevokeServiceAccountToken),
+		authz.Require("service-accounts:manage"),
+	)
 	r.Handle("/api/backends", http.HandlerFunc(h.HandleListBackends), authz.Require("backends:list"))
 	r.Handle(
 		"GET /api/backends/{backendID}/ps",
@@ -82,11 +146,10 @@ func New(h *handler.Handler, authz *middleware.Authz) *Router {
 		http.HandlerFunc(h.HandleBackendVersion),
 		authz.Require("backends:list"),
 	)
-	r.Handle("/api/models", http.HandlerFunc(h.HandleListModels), authz.Require("models:list"))
-	r.Handle("GET /api/models/{modelID}", http.HandlerFunc(h.HandleGetModel), authz.Require("models:list"))
-	r.Handle("/api/chat/completions", http.HandlerFunc(h.HandleChatCompletions), authz.Require("llm:chat"))
-	r.Handle("/api/completions", http.HandlerFunc(h.HandleCompletions), authz.Require("llm:chat"))
-	r.Handle("/api/embeddings", http.HandlerFunc(h.HandleEmbeddings), authz.Require("llm:embeddings"))
+	r.registerOpenAIRoutes(h, authz, "/api")
+	r.registerOpenAIRoutes(h, authz, "/v1")
+	r.Handle("POST /api/jobs/chat", http.HandlerFunc(h.HandleCreateChatJob), authz.Require("llm:chat"))
+	r.Handle("GET /api/jobs/{jobID}", http.HandlerFunc(h.HandleGetJob), authz.Require("llm:chat"))
 	return r
 }
 
@@ -103,3 +166,32 @@ func (r *Router) Handle(path string, handler http.Handler, wrappers ...func(http
 	}
 	r.mux.Handle(path, wrapped)
 }
+
+// registerOpenAIRoutes mounts the OpenAI-compatible model, LLM, file and batch routes under the
+// prefix. The same handlers and scopes serve /api and /v1.
+func (r *Router) registerOpenAIRoutes(h *handler.Handler, authz *middleware.Authz, prefix string) {
+	route := func(pattern string, handler http.HandlerFunc, scope string) {
+		method, path, found := strings.Cut(pattern, " ")
+		if found {
+			path = method + " " + prefix + path
+		} else {
+			path = prefix + pattern
+		}
+		r.Handle(path, handler, authz.Require(scope))
+	}
+
+	route("/models", h.HandleListModels, "models:list")
+	route("GET /models/{modelID}", h.HandleGetModel, "models:list")
+	route("/chat/completions", h.HandleChatCompletions, "llm:chat")
+	route("/completions", h.HandleCompletions, "llm:chat")
+	route("/embeddings", h.HandleEmbeddings, "llm:embeddings")
+	route("POST /files", h.HandleUploadFile, "llm:batch")
+	route("GET /files", h.HandleListFiles, "llm:batch")
+	route("GET /files/{fileID}", h.HandleGetFile, "llm:batch")
+	route("DELETE /files/{fileID}", h.HandleDeleteFile, "llm:batch")
+	route("GET /files/{fileID}/content", h.HandleGetFileContent, "llm:batch")
+	route("POST /batches", h.HandleCreateBatch, "llm:batch")
+	route("GET /batches", h.HandleListBatches, "llm:batch")
+	route("GET /batches/{batchID}", h.HandleGetBatch, "llm:batch")
+	route("POST /batches/{batchID}/cancel", h.HandleCancelBatch, "llm:batch")
+}
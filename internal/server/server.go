@@ -11,8 +11,10 @@ import (
 
 	"github.com/rhajizada/llamero/internal/auth"
 	"github.com/rhajizada/llamero/internal/config"
+	"github.com/rhajizada/llamero/internal/guardrails"
 	"github.com/rhajizada/llamero/internal/handler"
 	"github.com/rhajizada/llamero/internal/middleware"
+	"github.com/rhajizada/llamero/internal/profiles"
 	"github.com/rhajizada/llamero/internal/roles"
 	"github.com/rhajizada/llamero/internal/router"
 	"github.com/rhajizada/llamero/internal/service"
@@ -36,7 +38,10 @@ type Server struct {
 func New(
 	cfg *config.ServerConfig,
 	roleStore *roles.Store,
+	profileStore *profiles.Store,
+	guardChain *guardrails.Chain,
 	svc *service.Service,
+	states auth.StateStore,
 	tasks *asynq.Client,
 	logger *slog.Logger,
 ) (*Server, error) {
@@ -46,9 +51,18 @@ func New(
 	if roleStore == nil {
 		return nil, errors.New("role store is required")
 	}
+	if profileStore == nil {
+		return nil, errors.New("profile store is required")
+	}
+	if guardChain == nil {
+		return nil, errors.New("guardrails chain is required")
+	}
 	if svc == nil {
 		return nil, errors.New("service is required")
 	}
+	if states == nil {
+		return nil, errors.New("state store is required")
+	}
 	if tasks == nil {
 		return nil, errors.New("task client is required")
 	}
@@ -56,15 +70,19 @@ func New(
 		logger = slog.Default()
 	}
 
-	h, err := handler.New(cfg, roleStore, svc, tasks, logger)
+	keys, err := auth.NewKeyring(cfg.JWT, svc)
+	if err != nil {
+		return nil, err
+	}
+	h, err := handler.New(cfg, roleStore, profileStore, guardChain, svc, keys, states, tasks, logger)
 	if err != nil {
 		return nil, err
 	}
-	verifier, err := auth.NewTokenVerifier(cfg.JWT)
+	verifier, err := auth.NewTokenVerifier(cfg.JWT, keys)
 	if err != nil {
 		return nil, err
 	}
-	authz := middleware.NewAuthz(verifier, svc)
+	authz := middleware.NewAuthz(verifier, svc, auth.NewSessionCookies(cfg.Sessions, cfg.JWT.TTL))
 	r := router.New(h, authz)
 	handlerWithLogging := middleware.Logging(logger)(r)
 
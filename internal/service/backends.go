@@ -93,18 +93,20 @@ func (s *Service) RegisterBackends(ctx context.Context, defs []config.BackendDef
 	return nil
 }
 
-// SyncBackends refreshes health and model metadata for every backend.
+// SyncBackends refreshes health and model metadata for every backend. A failing backend does not
+// stop the others from syncing; all failures are returned together.
 func (s *Service) SyncBackends(ctx context.Context) error {
 	backends, err := s.store.ListBackends(ctx)
 	if err != nil {
 		return err
 	}
+	var errs []error
 	for _, backend := range backends {
 		if err = s.syncBackend(ctx, backend); err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // SyncBackendByID refreshes health and model metadata for a specific backend.
@@ -126,10 +128,30 @@ func (s *Service) SyncBackendByID(ctx context.Context, backendID string) error {
 // ErrNoHealthyBackends indicates that no registered backend can serve a request.
 var ErrNoHealthyBackends = errors.New("no healthy backends available")
 
+// Model capabilities reported by Ollama's /api/show.
+const (
+	CapabilityCompletion = "completion"
+	CapabilityTools      = "tools"
+	CapabilityInsert     = "insert"
+	CapabilityVision     = "vision"
+	CapabilityEmbedding  = "embedding"
+)
+
+// CapabilityError reports that no backend's copy of a model supports a requested feature.
+type CapabilityError struct {
+	Model      string
+	Capability string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("model %q does not support %s", e.Model, e.Capability)
+}
+
 // BackendRoute contains the minimum backend data required for proxying.
 type BackendRoute struct {
 	ID      string
 	Address string
+	Tags    []string
 }
 
 // LookupBackendRoute fetches backend connection details by identifier.
@@ -137,7 +159,7 @@ func (s *Service) LookupBackendRoute(ctx context.Context, backendID string) (Bac
 	backendID = strings.TrimSpace(backendID)
 	if backendID == "" {
 		return BackendRoute{}, &Error{
-			Code:    http.StatusNotFound,
+			Status:  http.StatusNotFound,
 			Message: "backend not found",
 		}
 	}
@@ -151,24 +173,26 @@ func (s *Service) LookupBackendRoute(ctx context.Context, backendID string) (Bac
 		}
 		if strings.TrimSpace(status.Address) == "" {
 			return BackendRoute{}, &Error{
-				Code:    http.StatusBadGateway,
+				Status:  http.StatusBadGateway,
 				Message: "backend missing address",
 			}
 		}
 		return BackendRoute{
 			ID:      status.ID,
 			Address: status.Address,
+			Tags:    append([]string(nil), status.Tags...),
 		}, nil
 	}
 	return BackendRoute{}, &Error{
-		Code:    http.StatusNotFound,
+		Status:  http.StatusNotFound,
 		Message: "backend not found",
 	}
 }
 
-// RouteBackend selects a healthy backend for a given model.
-func (s *Service) RouteBackend(ctx context.Context, model string) (BackendRoute, error) {
-	status, err := s.selectBackend(ctx, model)
+// RouteBackend selects a healthy backend for a given model. Required capabilities (for example
+// "tools" or "vision") restrict routing to backends whose copy of the model supports them.
+func (s *Service) RouteBackend(ctx context.Context, model string, required ...string) (BackendRoute, error) {
+	status, err := s.selectBackend(ctx, model, required)
 	if err != nil {
 		return BackendRoute{}, err
 	}
@@ -178,6 +202,25 @@ func (s *Service) RouteBackend(ctx context.Context, model string) (BackendRoute,
 	}, nil
 }
 
+// RouteBackends returns every healthy backend able to serve the model, best candidates first.
+// Backends with the model loaded come before those that only have it installed. When no backend
+// reports the model, every healthy backend is returned so the request can still be attempted.
+// Required capabilities filter candidates the same way as RouteBackend.
+func (s *Service) RouteBackends(ctx context.Context, model string, required ...string) ([]BackendRoute, error) {
+	candidates, err := s.candidateBackends(ctx, model, required)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]BackendRoute, 0, len(candidates))
+	for _, status := range candidates {
+		routes = append(routes, BackendRoute{
+			ID:      status.ID,
+			Address: status.Address,
+		})
+	}
+	return routes, nil
+}
+
 // ListBackends returns all backend statuses from Redis.
 func (s *Service) ListBackends(ctx context.Context) ([]models.Backend, error) {
 	statuses, err := s.store.ListBackends(ctx)
@@ -200,11 +243,53 @@ func (s *Service) ListBackends(ctx context.Context) ([]models.Backend, error) {
 	return backends, nil
 }
 
-func (s *Service) selectBackend(ctx context.Context, model string) (redisstore.BackendStatus, error) {
+// ModelDigest returns the digest of a model when every healthy backend serving it reports the
+// same one. Responses and vectors are only cached when the digest is unambiguous, so re-pulling or
+// replacing a model never serves answers computed by the old weights.
+func (s *Service) ModelDigest(ctx context.Context, model string) (string, bool, error) {
 	statuses, err := s.store.ListBackends(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	digest := ""
+	for _, status := range statuses {
+		if !status.Healthy {
+			continue
+		}
+		for _, meta := range status.ModelMeta {
+			if meta.Name != model {
+				continue
+			}
+			if meta.Digest == "" || (digest != "" && meta.Digest != digest) {
+				return "", false, nil
+			}
+			digest = meta.Digest
+		}
+	}
+	return digest, digest != "", nil
+}
+
+func (s *Service) selectBackend(
+	ctx context.Context,
+	model string,
+	required []string,
+) (redisstore.BackendStatus, error) {
+	candidates, err := s.candidateBackends(ctx, model, required)
 	if err != nil {
 		return redisstore.BackendStatus{}, err
 	}
+	return candidates[0], nil
+}
+
+func (s *Service) candidateBackends(
+	ctx context.Context,
+	model string,
+	required []string,
+) ([]redisstore.BackendStatus, error) {
+	statuses, err := s.store.ListBackends(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var (
 		healthy    []redisstore.BackendStatus
@@ -230,18 +315,65 @@ func (s *Service) selectBackend(ctx context.Context, model string) (redisstore.B
 	}
 
 	if len(healthy) == 0 {
-		return redisstore.BackendStatus{}, ErrNoHealthyBackends
+		return nil, ErrNoHealthyBackends
+	}
+	if model == "" || len(loadedHits)+len(modelHits) == 0 {
+		return healthy, nil
+	}
+	return filterByCapabilities(append(loadedHits, modelHits...), model, required)
+}
+
+// filterByCapabilities keeps backends whose copy of the model supports every required
+// capability. Backends without capability data are kept, since the sync worker may not have
+// inspected the model yet.
+func filterByCapabilities(
+	candidates []redisstore.BackendStatus,
+	model string,
+	required []string,
+) ([]redisstore.BackendStatus, error) {
+	if len(required) == 0 {
+		return candidates, nil
+	}
+	var (
+		eligible []redisstore.BackendStatus
+		missing  string
+	)
+	for _, status := range candidates {
+		capabilities := modelCapabilities(status, model)
+		if len(capabilities) == 0 {
+			eligible = append(eligible, status)
+			continue
+		}
+		if lacking := firstMissing(capabilities, required); lacking != "" {
+			if missing == "" {
+				missing = lacking
+			}
+			continue
+		}
+		eligible = append(eligible, status)
 	}
-	if model == "" {
-		return healthy[0], nil
+	if len(eligible) == 0 {
+		return nil, &CapabilityError{Model: model, Capability: missing}
 	}
-	if len(loadedHits) > 0 {
-		return loadedHits[0], nil
+	return eligible, nil
+}
+
+func modelCapabilities(status redisstore.BackendStatus, model string) []string {
+	for _, meta := range status.ModelMeta {
+		if meta.Name == model {
+			return meta.Capabilities
+		}
 	}
-	if len(modelHits) > 0 {
-		return modelHits[0], nil
+	return nil
+}
+
+func firstMissing(available, required []string) string {
+	for _, capability := range required {
+		if !contains(available, capability) {
+			return capability
+		}
 	}
-	return healthy[0], nil
+	return ""
 }
 
 func (s *Service) pingBackend(ctx context.Context, baseURL string) ([]redisstore.ModelInfo, []string, []string, error) {
@@ -250,7 +382,12 @@ func (s *Service) pingBackend(ctx context.Context, baseURL string) ([]redisstore
 		return nil, nil, nil, err
 	}
 	client := api.NewClient(parsed, http.DefaultClient)
-	return fetchInstalledModels(ctx, client)
+	meta, available, loaded, err := fetchInstalledModels(ctx, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s.attachShowDetails(ctx, client, meta)
+	return meta, available, loaded, nil
 }
 
 func fetchInstalledModels(ctx context.Context, client *api.Client) ([]redisstore.ModelInfo, []string, []string, error) {
@@ -312,9 +449,17 @@ func addListModels(registry map[string]redisstore.ModelInfo, models []api.ListMo
 			created = time.Now()
 		}
 		registry[name] = redisstore.ModelInfo{
-			Name:      name,
-			CreatedAt: created,
-			OwnedBy:   inferOwner(model.RemoteHost),
+			Name:              name,
+			CreatedAt:         created,
+			OwnedBy:           inferOwner(model.RemoteHost),
+			Digest:            model.Digest,
+			Size:              model.Size,
+			Format:            model.Details.Format,
+			Family:            model.Details.Family,
+			Families:          append([]string(nil), model.Details.Families...),
+			ParameterSize:     model.Details.ParameterSize,
+			QuantizationLevel: model.Details.QuantizationLevel,
+			Installed:         true,
 		}
 	}
 }
@@ -325,7 +470,9 @@ func addProcessModels(registry map[string]redisstore.ModelInfo, models []api.Pro
 		if name == "" {
 			continue
 		}
-		if _, exists := registry[name]; exists {
+		if existing, exists := registry[name]; exists {
+			existing.Loaded = true
+			registry[name] = existing
 			continue
 		}
 		created := model.ExpiresAt
@@ -333,9 +480,17 @@ func addProcessModels(registry map[string]redisstore.ModelInfo, models []api.Pro
 			created = time.Now()
 		}
 		registry[name] = redisstore.ModelInfo{
-			Name:      name,
-			CreatedAt: created,
-			OwnedBy:   defaultModelOwner,
+			Name:              name,
+			CreatedAt:         created,
+			OwnedBy:           defaultModelOwner,
+			Digest:            model.Digest,
+			Size:              model.Size,
+			Format:            model.Details.Format,
+			Family:            model.Details.Family,
+			Families:          append([]string(nil), model.Details.Families...),
+			ParameterSize:     model.Details.ParameterSize,
+			QuantizationLevel: model.Details.QuantizationLevel,
+			Loaded:            true,
 		}
 	}
 }
@@ -372,11 +527,21 @@ func (s *Service) syncBackend(ctx context.Context, backend redisstore.BackendSta
 	modelMeta, available, loaded, err := s.pingBackend(ctx, backend.Address)
 	backend.Healthy = err == nil
 	backend.LatencyMS = time.Since(start).Milliseconds()
+	previous := backend.ModelMeta
 	if err == nil {
 		backend.Models = available
 		backend.LoadedModels = loaded
 		backend.ModelMeta = modelMeta
 	}
 	backend.UpdatedAt = time.Now()
-	return s.store.SaveBackend(ctx, backend, 0)
+	if saveErr := s.store.SaveBackend(ctx, backend, 0); saveErr != nil {
+		return saveErr
+	}
+	if err != nil {
+		return nil
+	}
+	if invalidateErr := s.invalidateEmbeddings(ctx, previous, modelMeta); invalidateErr != nil {
+		return fmt.Errorf("invalidate embedding cache for backend %q: %w", backend.ID, invalidateErr)
+	}
+	return nil
 }
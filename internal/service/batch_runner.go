@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rhajizada/llamero/internal/config"
+	"github.com/rhajizada/llamero/internal/guardrails"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+const (
+	batchRequestIDLabel = "batch_req_"
+	batchErrorBackend   = "backend_error"
+	batchErrorRequest   = "invalid_request"
+)
+
+// BatchRunner executes batches inside the worker. Requests are spread across healthy backends by
+// the regular routing layer, with a fixed number in flight per batch so interactive traffic keeps
+// priority. Progress is stored per request, so an interrupted run resumes where it stopped.
+type BatchRunner struct {
+	svc    *Service
+	caller *llmCaller
+	cfg    config.BatchConfig
+}
+
+// batchOutputLine mirrors a line of an OpenAI batch output or error file.
+type batchOutputLine struct {
+	ID       string               `json:"id"`
+	CustomID string               `json:"custom_id"`
+	Response *batchOutputResponse `json:"response"`
+	Error    *batchOutputError    `json:"error"`
+}
+
+type batchOutputResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
+
+type batchOutputError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewBatchRunner creates a runner bound to the service, model profiles and guardrails.
+func NewBatchRunner(
+	svc *Service,
+	profileStore *profiles.Store,
+	guardChain *guardrails.Chain,
+	cfg config.BatchConfig,
+) *BatchRunner {
+	return &BatchRunner{
+		svc:    svc,
+		caller: newLLMCaller(svc, profileStore, guardChain, cfg.RequestTimeout, cfg.MaxAttempts),
+		cfg:    cfg,
+	}
+}
+
+// Run executes the pending requests of a batch and writes its output files. It returns an error
+// only when the run should be retried; requests that fail are recorded in the error file.
+func (r *BatchRunner) Run(ctx context.Context, batchID uuid.UUID) error {
+	batch, err := r.svc.repo.GetBatchForRun(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	switch batch.Status {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusExpired, BatchStatusCancelled:
+		return nil
+	case BatchStatusCancelling:
+		return r.finish(ctx, batch, BatchStatusCancelled)
+	}
+	if time.Now().After(batch.ExpiresAt) {
+		return r.finish(ctx, batch, BatchStatusExpired)
+	}
+
+	if err = r.svc.repo.StartBatch(ctx, batch.ID); err != nil {
+		return err
+	}
+	content, err := r.svc.GetFileContent(ctx, batch.UserID, batch.InputFileID)
+	if err != nil {
+		return err
+	}
+	lines, err := parseBatchLines(content)
+	if err != nil {
+		return r.svc.FailBatch(ctx, batch.ID, err.Error())
+	}
+	done, err := r.svc.repo.ListBatchResultLines(ctx, batch.ID)
+	if err != nil {
+		return err
+	}
+
+	status, err := r.dispatch(ctx, batch, lines, done)
+	if err != nil {
+		return err
+	}
+	return r.finish(ctx, batch, status)
+}
+
+// dispatch runs every request without a stored result and returns the status the batch should
+// finish with.
+func (r *BatchRunner) dispatch(
+	ctx context.Context,
+	batch repository.Batch,
+	lines []batchLine,
+	done []int32,
+) (string, error) {
+	completed := make(map[int32]struct{}, len(done))
+	for _, line := range done {
+		completed[line] = struct{}{}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		runErrs error
+	)
+	record := func(err error) {
+		mu.Lock()
+		runErrs = errors.Join(runErrs, err)
+		mu.Unlock()
+	}
+	sem := make(chan struct{}, max(r.cfg.Concurrency, 1))
+	final := BatchStatusCompleted
+	for i, line := range lines {
+		index := int32(i)
+		if _, ok := completed[index]; ok {
+			continue
+		}
+		stop, err := r.stopStatus(ctx, batch)
+		if err != nil || stop != "" {
+			record(err)
+			final = stop
+			break
+		}
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			if execErr := r.execute(ctx, batch, index, line); execErr != nil {
+				record(execErr)
+			}
+		})
+	}
+	wg.Wait()
+
+	if runErrs != nil {
+		return "", runErrs
+	}
+	return final, nil
+}
+
+// stopStatus reports whether the batch was cancelled or ran out of time.
+func (r *BatchRunner) stopStatus(ctx context.Context, batch repository.Batch) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if time.Now().After(batch.ExpiresAt) {
+		return BatchStatusExpired, nil
+	}
+	status, err := r.svc.repo.GetBatchStatus(ctx, batch.ID)
+	if err != nil {
+		return "", err
+	}
+	if status == BatchStatusCancelling {
+		return BatchStatusCancelled, nil
+	}
+	return "", nil
+}
+
+// execute runs a single request and records its outcome. Requests interrupted by a worker
+// shutdown are not recorded so they run again when the batch resumes.
+func (r *BatchRunner) execute(ctx context.Context, batch repository.Batch, index int32, line batchLine) error {
+	outcome := r.caller.call(ctx, batch.Endpoint, batch.Role, line.Body)
+	if ctx.Err() != nil {
+		return nil
+	}
+	var errMsg *string
+	if outcome.errMsg != "" {
+		errMsg = &outcome.errMsg
+	}
+	return r.svc.repo.RecordBatchResult(ctx, repository.RecordBatchResultParams{
+		BatchID:      batch.ID,
+		Line:         index,
+		CustomID:     line.CustomID,
+		StatusCode:   int32(outcome.status),
+		Body:         outcome.body,
+		ErrorMessage: errMsg,
+	})
+}
+
+// finish writes the output and error files and moves the batch into its final status.
+func (r *BatchRunner) finish(ctx context.Context, batch repository.Batch, status string) error {
+	if err := r.svc.repo.MarkBatchFinalizing(ctx, batch.ID); err != nil {
+		return err
+	}
+	results, err := r.svc.repo.ListBatchResults(ctx, batch.ID)
+	if err != nil {
+		return err
+	}
+	output, failures, err := encodeBatchResults(results)
+	if err != nil {
+		return err
+	}
+
+	params := repository.FinishBatchParams{Status: status, ID: batch.ID}
+	if params.OutputFileID, err = r.writeOutput(ctx, batch, "output", output); err != nil {
+		return err
+	}
+	if params.ErrorFileID, err = r.writeOutput(ctx, batch, "error", failures); err != nil {
+		return err
+	}
+	if err = r.svc.repo.FinishBatch(ctx, params); err != nil {
+		return err
+	}
+	return r.svc.repo.DeleteBatchResults(ctx, batch.ID)
+}
+
+func (r *BatchRunner) writeOutput(
+	ctx context.Context,
+	batch repository.Batch,
+	suffix string,
+	content []byte,
+) (*uuid.UUID, error) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	filename := fmt.Sprintf("batch_%s_%s.jsonl", batch.ID, suffix)
+	file, err := r.svc.createFile(ctx, batch.UserID, filename, FilePurposeBatchOutput, content)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(file.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// encodeBatchResults splits stored results into output (2xx) and error JSONL files.
+func encodeBatchResults(results []repository.BatchResult) ([]byte, []byte, error) {
+	var output, failures bytes.Buffer
+	for _, result := range results {
+		line := batchOutputLine{
+			ID:       batchRequestIDLabel + uuid.NewString(),
+			CustomID: result.CustomID,
+		}
+		if len(result.Body) > 0 {
+			line.Response = &batchOutputResponse{
+				StatusCode: int(result.StatusCode),
+				Body:       rawJSON(result.Body),
+			}
+		}
+		target := &output
+		if result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusMultipleChoices {
+			target = &failures
+			if result.ErrorMessage != nil {
+				line.Error = &batchOutputError{Code: batchErrorCode(result), Message: *result.ErrorMessage}
+			}
+		}
+		encoded, err := json.Marshal(line)
+		if err != nil {
+			return nil, nil, err
+		}
+		target.Write(encoded)
+		target.WriteByte('\n')
+	}
+	return output.Bytes(), failures.Bytes(), nil
+}
+
+func batchErrorCode(result repository.BatchResult) string {
+	if result.StatusCode == http.StatusBadRequest {
+		return batchErrorRequest
+	}
+	return batchErrorBackend
+}
+
+// rawJSON returns the body unchanged when it is valid JSON and as a JSON string otherwise.
+func rawJSON(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return body
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// Batch statuses mirror the OpenAI Batch API lifecycle.
+const (
+	BatchStatusValidating = "validating"
+	BatchStatusInProgress = "in_progress"
+	BatchStatusFinalizing = "finalizing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusFailed     = "failed"
+	BatchStatusExpired    = "expired"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+)
+
+// Batch endpoints accepted in input files and batch requests.
+const (
+	BatchEndpointChatCompletions = "/v1/chat/completions"
+	BatchEndpointCompletions     = "/v1/completions"
+	BatchEndpointEmbeddings      = "/v1/embeddings"
+)
+
+const (
+	// BatchCompletionWindow is the only completion window currently supported.
+	BatchCompletionWindow = "24h"
+	batchCompletionWindow = 24 * time.Hour
+
+	maxBatchMetadataPairs    = 16
+	maxBatchMetadataKeyLen   = 64
+	maxBatchMetadataValueLen = 512
+
+	batchScopeChat       = "llm:chat"
+	batchScopeEmbeddings = "llm:embeddings"
+)
+
+// CreateBatchParams captures the data required to start a batch.
+type CreateBatchParams struct {
+	UserID           uuid.UUID
+	Role             string
+	InputFileID      uuid.UUID
+	Endpoint         string
+	CompletionWindow string
+	Metadata         map[string]string
+	MaxRequests      int
+}
+
+// batchLine is a single request of a batch input file.
+type batchLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// BatchEndpointScope returns the scope a caller needs to run a batch against the endpoint.
+func BatchEndpointScope(endpoint string) (string, error) {
+	switch normalizeBatchEndpoint(endpoint) {
+	case BatchEndpointChatCompletions, BatchEndpointCompletions:
+		return batchScopeChat, nil
+	case BatchEndpointEmbeddings:
+		return batchScopeEmbeddings, nil
+	default:
+		return "", &Error{
+			Status:  http.StatusBadRequest,
+			Message: "unsupported batch endpoint",
+		}
+	}
+}
+
+// CreateBatch validates the input file against the endpoint and records a new batch. The caller
+// is responsible for enqueueing the batch for execution.
+func (s *Service) CreateBatch(ctx context.Context, params CreateBatchParams) (models.Batch, error) {
+	endpoint := normalizeBatchEndpoint(params.Endpoint)
+	if _, err := BatchEndpointScope(endpoint); err != nil {
+		return models.Batch{}, err
+	}
+	if params.CompletionWindow != BatchCompletionWindow {
+		return models.Batch{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "completion_window must be " + BatchCompletionWindow,
+		}
+	}
+	metadata, err := encodeBatchMetadata(params.Metadata)
+	if err != nil {
+		return models.Batch{}, err
+	}
+
+	file, err := s.GetFile(ctx, params.UserID, params.InputFileID)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	if file.Purpose != FilePurposeBatch {
+		return models.Batch{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "input file must have purpose " + FilePurposeBatch,
+		}
+	}
+	content, err := s.GetFileContent(ctx, params.UserID, params.InputFileID)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	lines, err := parseBatchLines(content)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	if params.MaxRequests > 0 && len(lines) > params.MaxRequests {
+		return models.Batch{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("batch exceeds the limit of %d requests", params.MaxRequests),
+		}
+	}
+	for i, line := range lines {
+		if line.URL != endpoint {
+			return models.Batch{}, &Error{
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("request %d: url %q does not match batch endpoint %q", i+1, line.URL, endpoint),
+			}
+		}
+	}
+
+	record, err := s.repo.CreateBatch(ctx, repository.CreateBatchParams{
+		UserID:           params.UserID,
+		Role:             params.Role,
+		Endpoint:         endpoint,
+		InputFileID:      params.InputFileID,
+		CompletionWindow: params.CompletionWindow,
+		Metadata:         metadata,
+		RequestTotal:     int32(len(lines)),
+		ExpiresAt:        time.Now().Add(batchCompletionWindow),
+	})
+	if err != nil {
+		return models.Batch{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to create batch",
+			Err:     err,
+		}
+	}
+	return models.NewBatchFromRepo(record), nil
+}
+
+// ListBatches returns the most recent batches for a user.
+func (s *Service) ListBatches(ctx context.Context, userID uuid.UUID, limit int32) ([]models.Batch, error) {
+	records, err := s.repo.ListBatchesByUser(ctx, repository.ListBatchesByUserParams{
+		UserID: userID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to list batches",
+			Err:     err,
+		}
+	}
+	out := make([]models.Batch, 0, len(records))
+	for _, rec := range records {
+		out = append(out, models.NewBatchFromRepo(rec))
+	}
+	return out, nil
+}
+
+// GetBatch returns a batch owned by the user.
+func (s *Service) GetBatch(ctx context.Context, userID, batchID uuid.UUID) (models.Batch, error) {
+	record, err := s.repo.GetBatchByID(ctx, repository.GetBatchByIDParams{
+		ID:     batchID,
+		UserID: userID,
+	})
+	if err != nil {
+		return models.Batch{}, batchLookupError(err)
+	}
+	return models.NewBatchFromRepo(record), nil
+}
+
+// CancelBatch asks the worker to stop a running batch. Requests already completed are kept and
+// written to the output file.
+func (s *Service) CancelBatch(ctx context.Context, userID, batchID uuid.UUID) (models.Batch, error) {
+	record, err := s.repo.CancelBatch(ctx, repository.CancelBatchParams{
+		ID:     batchID,
+		UserID: userID,
+	})
+	if err == nil {
+		return models.NewBatchFromRepo(record), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.Batch{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to cancel batch",
+			Err:     err,
+		}
+	}
+
+	current, err := s.GetBatch(ctx, userID, batchID)
+	if err != nil {
+		return models.Batch{}, err
+	}
+	if current.Status == BatchStatusCancelling || current.Status == BatchStatusCancelled {
+		return current, nil
+	}
+	return models.Batch{}, &Error{
+		Status:  http.StatusConflict,
+		Message: fmt.Sprintf("batch cannot be cancelled while %s", current.Status),
+	}
+}
+
+// FailBatch marks a batch as failed with the supplied reason.
+func (s *Service) FailBatch(ctx context.Context, batchID uuid.UUID, reason string) error {
+	return s.repo.FinishBatch(ctx, repository.FinishBatchParams{
+		Status:       BatchStatusFailed,
+		ErrorMessage: &reason,
+		ID:           batchID,
+	})
+}
+
+// parseBatchLines decodes and validates a JSONL batch input file.
+func parseBatchLines(content []byte) ([]batchLine, error) {
+	var lines []batchLine
+	seen := make(map[string]struct{})
+	for i, raw := range bytes.Split(content, []byte("\n")) {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		line, err := parseBatchLine(raw)
+		if err != nil {
+			return nil, &Error{
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("line %d: %s", i+1, err.Error()),
+			}
+		}
+		if _, dup := seen[line.CustomID]; dup {
+			return nil, &Error{
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("line %d: duplicate custom_id %q", i+1, line.CustomID),
+			}
+		}
+		seen[line.CustomID] = struct{}{}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "file contains no requests",
+		}
+	}
+	return lines, nil
+}
+
+func parseBatchLine(raw []byte) (batchLine, error) {
+	var line batchLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return batchLine{}, errors.New("invalid JSON")
+	}
+	if strings.TrimSpace(line.CustomID) == "" {
+		return batchLine{}, errors.New("custom_id is required")
+	}
+	if !strings.EqualFold(line.Method, http.MethodPost) {
+		return batchLine{}, errors.New("method must be POST")
+	}
+	line.URL = normalizeBatchEndpoint(line.URL)
+	if _, err := BatchEndpointScope(line.URL); err != nil {
+		return batchLine{}, fmt.Errorf("unsupported url %q", line.URL)
+	}
+	var body struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(line.Body, &body); err != nil {
+		return batchLine{}, errors.New("body must be a JSON object")
+	}
+	if strings.TrimSpace(body.Model) == "" {
+		return batchLine{}, errors.New("body.model is required")
+	}
+	return line, nil
+}
+
+// normalizeBatchEndpoint maps /api/... request URLs onto their /v1/... equivalents.
+func normalizeBatchEndpoint(endpoint string) string {
+	endpoint = strings.TrimSpace(endpoint)
+	if after, ok := strings.CutPrefix(endpoint, "/api/"); ok {
+		return "/v1/" + after
+	}
+	return endpoint
+}
+
+func encodeBatchMetadata(metadata map[string]string) ([]byte, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if len(metadata) > maxBatchMetadataPairs {
+		return nil, &Error{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("metadata supports at most %d pairs", maxBatchMetadataPairs),
+		}
+	}
+	for key, value := range metadata {
+		if len(key) > maxBatchMetadataKeyLen || len(value) > maxBatchMetadataValueLen {
+			return nil, &Error{
+				Status: http.StatusBadRequest,
+				Message: fmt.Sprintf(
+					"metadata keys are limited to %d characters and values to %d",
+					maxBatchMetadataKeyLen,
+					maxBatchMetadataValueLen,
+				),
+			}
+		}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "invalid metadata",
+			Err:     err,
+		}
+	}
+	return encoded, nil
+}
+
+func batchLookupError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{
+			Status:  http.StatusNotFound,
+			Message: "batch not found",
+			Err:     err,
+		}
+	}
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Message: "failed to load batch",
+		Err:     err,
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/redisstore"
+)
+
+// CachedEmbeddings loads cached vectors for input hashes computed by a model digest. Misses are
+// returned as nil entries.
+func (s *Service) CachedEmbeddings(
+	ctx context.Context,
+	model, digest string,
+	hashes []string,
+) ([]json.RawMessage, error) {
+	keys := make([]string, len(hashes))
+	for i, hash := range hashes {
+		keys[i] = redisstore.EmbeddingCacheKey(model, digest, hash)
+	}
+	return s.store.GetEmbeddings(ctx, keys)
+}
+
+// CacheEmbeddings stores vectors keyed by input hash for a model digest.
+func (s *Service) CacheEmbeddings(
+	ctx context.Context,
+	model, digest string,
+	vectors map[string]json.RawMessage,
+	ttl time.Duration,
+) error {
+	keyed := make(map[string]json.RawMessage, len(vectors))
+	for hash, vector := range vectors {
+		keyed[redisstore.EmbeddingCacheKey(model, digest, hash)] = vector
+	}
+	return s.store.SaveEmbeddings(ctx, keyed, ttl)
+}
+
+// invalidateEmbeddings drops cached vectors for models whose digest changed or that were removed
+// from a backend.
+func (s *Service) invalidateEmbeddings(ctx context.Context, previous, current []redisstore.ModelInfo) error {
+	digests := make(map[string]string, len(current))
+	for _, meta := range current {
+		digests[meta.Name] = meta.Digest
+	}
+	var errs []error
+	for _, meta := range previous {
+		if meta.Digest == "" || digests[meta.Name] == meta.Digest {
+			continue
+		}
+		errs = append(errs, s.store.DeleteEmbeddings(ctx, meta.Name, meta.Digest))
+	}
+	return errors.Join(errs...)
+}
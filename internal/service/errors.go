@@ -1,10 +1,39 @@
 package service
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
 
-// Error wraps an HTTP status code and message for handlers to translate into responses.
+// Error types follow the OpenAI error object.
+const (
+	ErrorTypeInvalidRequest = "invalid_request_error"
+	ErrorTypeAuthentication = "authentication_error"
+	ErrorTypePermission     = "permission_error"
+	ErrorTypeNotFound       = "not_found_error"
+	ErrorTypeRateLimit      = "rate_limit_error"
+	ErrorTypeServer         = "server_error"
+)
+
+// Error codes clients can match on.
+const (
+	ErrorCodeModelNotFound           = "model_not_found"
+	ErrorCodeContextLengthExceeded   = "context_length_exceeded"
+	ErrorCodeBackendBusy             = "backend_busy"
+	ErrorCodeUnsupportedCapability   = "unsupported_capability"
+	ErrorCodeRequestTooLarge         = "request_too_large"
+	ErrorCodeContentFilter           = "content_filter"
+	ErrorCodeInvalidStructuredOutput = "invalid_structured_output"
+)
+
+// Error wraps an HTTP status, an OpenAI error type, code and parameter, and a message for handlers
+// to translate into responses. Type is derived from Status when empty.
 type Error struct {
-	Code    int
+	Status  int
+	Type    string
+	Code    string
+	Param   string
 	Message string
 	Err     error
 }
@@ -20,3 +49,72 @@ func (e *Error) Error() string {
 func (e *Error) Unwrap() error {
 	return e.Err
 }
+
+// ErrorType returns the error type, falling back to the one implied by the status.
+func (e *Error) ErrorType() string {
+	if e.Type != "" {
+		return e.Type
+	}
+	return ErrorTypeForStatus(e.Status)
+}
+
+// ErrorTypeForStatus maps an HTTP status onto an OpenAI error type.
+func ErrorTypeForStatus(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrorTypeAuthentication
+	case status == http.StatusForbidden:
+		return ErrorTypePermission
+	case status == http.StatusNotFound:
+		return ErrorTypeNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrorTypeRateLimit
+	case status >= http.StatusInternalServerError:
+		return ErrorTypeServer
+	default:
+		return ErrorTypeInvalidRequest
+	}
+}
+
+// BackendError translates an error reported by an Ollama backend. Missing models, context window
+// overflows and overloaded backends get stable codes; other failures keep the backend's status.
+func BackendError(status int, message string) *Error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "model") && strings.Contains(lower, "not found"):
+		return &Error{
+			Status:  http.StatusNotFound,
+			Code:    ErrorCodeModelNotFound,
+			Param:   "model",
+			Message: message,
+		}
+	case strings.Contains(lower, "context length"),
+		strings.Contains(lower, "context window"),
+		strings.Contains(lower, "prompt too long"):
+		return &Error{
+			Status:  http.StatusBadRequest,
+			Code:    ErrorCodeContextLengthExceeded,
+			Param:   "messages",
+			Message: message,
+		}
+	case status == http.StatusServiceUnavailable,
+		status == http.StatusTooManyRequests,
+		strings.Contains(lower, "server busy"):
+		if status != http.StatusTooManyRequests {
+			status = http.StatusServiceUnavailable
+		}
+		return &Error{
+			Status:  status,
+			Code:    ErrorCodeBackendBusy,
+			Message: message,
+		}
+	}
+	if status < http.StatusBadRequest {
+		status = http.StatusBadGateway
+	}
+	return &Error{Status: status, Message: message}
+}
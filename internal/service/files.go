@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+const (
+	// FilePurposeBatch marks JSONL files uploaded as batch input.
+	FilePurposeBatch = "batch"
+	// FilePurposeBatchOutput marks result and error files written by batches.
+	FilePurposeBatchOutput = "batch_output"
+
+	pgForeignKeyViolation = "23503"
+)
+
+// UploadFileParams captures an uploaded file.
+type UploadFileParams struct {
+	UserID   uuid.UUID
+	Filename string
+	Purpose  string
+	Content  []byte
+}
+
+// UploadFile stores a file for the supplied user.
+func (s *Service) UploadFile(ctx context.Context, params UploadFileParams) (models.File, error) {
+	if params.UserID == uuid.Nil {
+		return models.File{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "user id is required",
+		}
+	}
+	filename := strings.TrimSpace(params.Filename)
+	if filename == "" {
+		return models.File{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "filename is required",
+		}
+	}
+	if params.Purpose != FilePurposeBatch {
+		return models.File{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "purpose must be " + FilePurposeBatch,
+		}
+	}
+	if len(params.Content) == 0 {
+		return models.File{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "file is empty",
+		}
+	}
+	if _, err := parseBatchLines(params.Content); err != nil {
+		return models.File{}, err
+	}
+	return s.createFile(ctx, params.UserID, filename, params.Purpose, params.Content)
+}
+
+// ListFiles returns file metadata for a user ordered by creation time.
+func (s *Service) ListFiles(ctx context.Context, userID uuid.UUID) ([]models.File, error) {
+	records, err := s.repo.ListFilesByUser(ctx, userID)
+	if err != nil {
+		return nil, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to list files",
+			Err:     err,
+		}
+	}
+	out := make([]models.File, 0, len(records))
+	for _, rec := range records {
+		out = append(out, models.NewFile(rec.ID, rec.Filename, rec.Purpose, rec.Bytes, rec.CreatedAt))
+	}
+	return out, nil
+}
+
+// GetFile returns metadata for a file owned by the user.
+func (s *Service) GetFile(ctx context.Context, userID, fileID uuid.UUID) (models.File, error) {
+	rec, err := s.repo.GetFileByID(ctx, repository.GetFileByIDParams{
+		ID:     fileID,
+		UserID: userID,
+	})
+	if err != nil {
+		return models.File{}, fileLookupError(err)
+	}
+	return models.NewFile(rec.ID, rec.Filename, rec.Purpose, rec.Bytes, rec.CreatedAt), nil
+}
+
+// GetFileContent returns the raw contents of a file owned by the user.
+func (s *Service) GetFileContent(ctx context.Context, userID, fileID uuid.UUID) ([]byte, error) {
+	content, err := s.repo.GetFileContent(ctx, repository.GetFileContentParams{
+		ID:     fileID,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, fileLookupError(err)
+	}
+	return content, nil
+}
+
+// DeleteFile removes a file owned by the user. Files still referenced as batch input cannot be
+// deleted.
+func (s *Service) DeleteFile(ctx context.Context, userID, fileID uuid.UUID) error {
+	rows, err := s.repo.DeleteFile(ctx, repository.DeleteFileParams{
+		ID:     fileID,
+		UserID: userID,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return &Error{
+				Status:  http.StatusConflict,
+				Message: "file is used by a batch",
+				Err:     err,
+			}
+		}
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to delete file",
+			Err:     err,
+		}
+	}
+	if rows == 0 {
+		return &Error{
+			Status:  http.StatusNotFound,
+			Message: "file not found",
+		}
+	}
+	return nil
+}
+
+func (s *Service) createFile(
+	ctx context.Context,
+	userID uuid.UUID,
+	filename, purpose string,
+	content []byte,
+) (models.File, error) {
+	rec, err := s.repo.CreateFile(ctx, repository.CreateFileParams{
+		UserID:   userID,
+		Filename: filename,
+		Purpose:  purpose,
+		Bytes:    int64(len(content)),
+		Content:  content,
+	})
+	if err != nil {
+		return models.File{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to store file",
+			Err:     err,
+		}
+	}
+	return models.NewFile(rec.ID, rec.Filename, rec.Purpose, rec.Bytes, rec.CreatedAt), nil
+}
+
+func fileLookupError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{
+			Status:  http.StatusNotFound,
+			Message: "file not found",
+			Err:     err,
+		}
+	}
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Message: "failed to load file",
+		Err:     err,
+	}
+}
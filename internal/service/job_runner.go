@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rhajizada/llamero/internal/config"
+	"github.com/rhajizada/llamero/internal/guardrails"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// Webhook request headers.
+const (
+	WebhookSignatureHeader = "X-Llamero-Signature"
+	WebhookEventHeader     = "X-Llamero-Event"
+	WebhookJobHeader       = "X-Llamero-Job-Id"
+
+	webhookEventPrefix    = "job."
+	webhookErrorBodyBytes = 512
+)
+
+// JobRunner executes asynchronous jobs in the worker and delivers their completion webhooks.
+type JobRunner struct {
+	svc      *Service
+	caller   *llmCaller
+	client   *http.Client
+	webhooks config.WebhookConfig
+}
+
+// NewJobRunner creates a runner bound to the service, model profiles and guardrails.
+func NewJobRunner(
+	svc *Service,
+	profileStore *profiles.Store,
+	guardChain *guardrails.Chain,
+	jobs config.JobsConfig,
+	webhooks config.WebhookConfig,
+) *JobRunner {
+	return &JobRunner{
+		svc:      svc,
+		caller:   newLLMCaller(svc, profileStore, guardChain, jobs.Timeout, jobs.MaxAttempts),
+		client:   newWebhookClient(webhooks),
+		webhooks: webhooks,
+	}
+}
+
+// Run executes a queued job and stores its result. It reports whether a completion webhook is
+// pending delivery.
+func (r *JobRunner) Run(ctx context.Context, jobID uuid.UUID) (bool, error) {
+	job, err := r.svc.repo.GetJobForRun(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if job.Status == JobStatusSucceeded || job.Status == JobStatusFailed {
+		return webhookPending(job), nil
+	}
+
+	if err = r.svc.repo.StartJob(ctx, job.ID); err != nil {
+		return false, err
+	}
+	outcome := r.caller.call(ctx, BatchEndpointChatCompletions, job.Role, job.Request)
+	if err = ctx.Err(); err != nil {
+		return false, err
+	}
+
+	status := int32(outcome.status)
+	params := repository.FinishJobParams{
+		Status:     JobStatusSucceeded,
+		StatusCode: &status,
+		Result:     outcome.body,
+		ID:         job.ID,
+	}
+	if outcome.status < http.StatusOK || outcome.status >= http.StatusMultipleChoices {
+		message := outcomeErrorMessage(outcome)
+		params.Status = JobStatusFailed
+		params.ErrorMessage = &message
+	}
+	finished, err := r.svc.repo.FinishJob(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return webhookPending(finished), nil
+}
+
+// DeliverWebhook posts the finished job to its callback URL, signed with HMAC-SHA256. final marks
+// the last attempt: when it fails the webhook is moved to the dead state.
+func (r *JobRunner) DeliverWebhook(ctx context.Context, jobID uuid.UUID, final bool) error {
+	job, err := r.svc.repo.GetJobForRun(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if !webhookPending(job) {
+		return nil
+	}
+
+	deliveryErr := r.post(ctx, job)
+	params := repository.RecordJobWebhookAttemptParams{
+		WebhookStatus: WebhookStatusDelivered,
+		ID:            job.ID,
+	}
+	if deliveryErr != nil {
+		message := deliveryErr.Error()
+		params.WebhookLastError = &message
+		params.WebhookStatus = WebhookStatusPending
+		if final {
+			params.WebhookStatus = WebhookStatusDead
+		}
+	}
+	if err = r.svc.repo.RecordJobWebhookAttempt(ctx, params); err != nil {
+		return errors.Join(deliveryErr, err)
+	}
+	return deliveryErr
+}
+
+func (r *JobRunner) post(ctx context.Context, job repository.Job) error {
+	payload, err := json.Marshal(models.NewJobFromRepo(job))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *job.CallbackURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, webhookEventPrefix+job.Status)
+	req.Header.Set(WebhookJobHeader, job.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(r.webhooks.Secret, time.Now(), payload))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodyBytes))
+		return fmt.Errorf("callback returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// SignWebhook returns the signature header value for a webhook body: the Unix timestamp and the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the shared secret.
+func SignWebhook(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// errWebhookAddressBlocked reports a callback that resolved to a non-public address.
+var errWebhookAddressBlocked = errors.New("callback address is not publicly routable")
+
+// newWebhookClient builds the client used for callbacks. Unless private networks are allowed, every
+// dial is checked against the resolved address, so redirects and DNS rebinding cannot reach
+// loopback, private, link-local or unspecified addresses inside the deployment.
+func newWebhookClient(webhooks config.WebhookConfig) *http.Client {
+	dialer := &net.Dialer{}
+	if !webhooks.AllowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errWebhookAddressBlocked, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: webhooks.Timeout, Transport: transport}
+}
+
+// publicAddress reports whether a callback may be delivered to addr.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+func webhookPending(job repository.Job) bool {
+	return job.CallbackURL != nil && job.WebhookStatus != nil && *job.WebhookStatus == WebhookStatusPending
+}
+
+// outcomeErrorMessage extracts a readable error from a failed backend response.
+func outcomeErrorMessage(outcome llmOutcome) string {
+	if outcome.errMsg != "" {
+		return outcome.errMsg
+	}
+	if message := ollamanative.ErrorMessage(outcome.body); message != "" {
+		return message
+	}
+	return http.StatusText(outcome.status)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// Job kinds and statuses.
+const (
+	JobKindChat = "chat"
+
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Webhook delivery states. A webhook that exhausts its attempts is dead.
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusDead      = "dead"
+)
+
+// CreateJobParams captures the data required to queue a job.
+type CreateJobParams struct {
+	UserID      uuid.UUID
+	Role        string
+	Model       string
+	Request     []byte
+	CallbackURL string
+}
+
+// CreateChatJob records a chat completion job. The caller is responsible for enqueueing it.
+func (s *Service) CreateChatJob(ctx context.Context, params CreateJobParams) (models.Job, error) {
+	if strings.TrimSpace(params.Model) == "" {
+		return models.Job{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "model is required",
+		}
+	}
+	callback, err := normalizeCallbackURL(params.CallbackURL)
+	if err != nil {
+		return models.Job{}, err
+	}
+	record, err := s.repo.CreateJob(ctx, repository.CreateJobParams{
+		UserID:      params.UserID,
+		Role:        params.Role,
+		Kind:        JobKindChat,
+		Model:       params.Model,
+		Request:     params.Request,
+		CallbackURL: callback,
+	})
+	if err != nil {
+		return models.Job{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to create job",
+			Err:     err,
+		}
+	}
+	return models.NewJobFromRepo(record), nil
+}
+
+// GetJob returns a job owned by the user.
+func (s *Service) GetJob(ctx context.Context, userID, jobID uuid.UUID) (models.Job, error) {
+	record, err := s.repo.GetJobByID(ctx, repository.GetJobByIDParams{
+		ID:     jobID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Job{}, &Error{
+				Status:  http.StatusNotFound,
+				Message: "job not found",
+				Err:     err,
+			}
+		}
+		return models.Job{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to load job",
+			Err:     err,
+		}
+	}
+	return models.NewJobFromRepo(record), nil
+}
+
+// FailJob marks a job as failed with the supplied reason.
+func (s *Service) FailJob(ctx context.Context, jobID uuid.UUID, reason string) error {
+	_, err := s.repo.FinishJob(ctx, repository.FinishJobParams{
+		Status:       JobStatusFailed,
+		ErrorMessage: &reason,
+		ID:           jobID,
+	})
+	return err
+}
+
+func normalizeCallbackURL(raw string) (*string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return nil, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "callback_url must be an absolute http or https URL",
+		}
+	}
+	value := parsed.String()
+	return &value, nil
+}
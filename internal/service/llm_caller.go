@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/guardrails"
+	"github.com/rhajizada/llamero/internal/ollamanative"
+	"github.com/rhajizada/llamero/internal/profiles"
+	"github.com/rhajizada/llamero/internal/structured"
+)
+
+const llmRetryDelay = time.Second
+
+// llmCaller sends OpenAI-compatible requests from background work to routed backends. Model
+// profiles apply with the role of the user who submitted the work, as they do in the HTTP handlers.
+type llmCaller struct {
+	svc         *Service
+	profiles    *profiles.Store
+	guards      *guardrails.Chain
+	client      *http.Client
+	maxAttempts int
+}
+
+// llmOutcome is the result of a single background request.
+type llmOutcome struct {
+	status int
+	body   []byte
+	errMsg string
+}
+
+func newLLMCaller(
+	svc *Service,
+	profileStore *profiles.Store,
+	guardChain *guardrails.Chain,
+	timeout time.Duration,
+	maxAttempts int,
+) *llmCaller {
+	return &llmCaller{
+		svc:         svc,
+		profiles:    profileStore,
+		guards:      guardChain,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+	}
+}
+
+// call applies the model profile for the owner's role and sends the request, retrying transient
+// failures on another backend. Chat and completion requests also pass through the guardrails.
+func (c *llmCaller) call(ctx context.Context, endpoint, role string, raw json.RawMessage) llmOutcome {
+	var req profiles.Request
+	if err := json.Unmarshal(raw, &req); err != nil || req == nil {
+		return requestOutcome("body must be a JSON object")
+	}
+	var (
+		runtime  profiles.Runtime
+		format   *structured.Format
+		required []string
+	)
+	kind, profiled := llmProfileKind(endpoint)
+	if profiled {
+		var err error
+		if runtime, err = c.profiles.Prepare(req, kind, role); err != nil {
+			return requestOutcome(err.Error())
+		}
+		if format, err = responseFormat(req, kind); err != nil {
+			return requestOutcome(err.Error())
+		}
+		req["stream"] = json.RawMessage("false")
+	} else {
+		required = []string{CapabilityEmbedding}
+	}
+	var model string
+	if value, ok := req["model"]; ok {
+		_ = json.Unmarshal(value, &model)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return requestOutcome("body must be a JSON object")
+	}
+	meta := guardrails.Meta{Model: model, Role: role}
+	if profiled {
+		if body, err = c.guards.CheckRequest(ctx, body, meta); err != nil {
+			return guardrailOutcome(err)
+		}
+	}
+
+	native := ollamanative.For(kind, runtime, format.Ollama())
+	var outcome llmOutcome
+	for attempt := range max(c.maxAttempts, 1) {
+		if attempt > 0 && !sleepContext(ctx, time.Duration(attempt)*llmRetryDelay) {
+			break
+		}
+		outcome = structuredOutcome(c.send(ctx, endpoint, model, body, native, required), format)
+		if !retryableOutcome(outcome) {
+			break
+		}
+	}
+	if profiled {
+		return c.guardOutcome(ctx, outcome, meta)
+	}
+	return outcome
+}
+
+// guardOutcome applies the guardrail post filters to a successful completion.
+func (c *llmCaller) guardOutcome(ctx context.Context, outcome llmOutcome, meta guardrails.Meta) llmOutcome {
+	if outcome.status != http.StatusOK {
+		return outcome
+	}
+	body, err := c.guards.CheckResponse(ctx, outcome.body, meta)
+	if err != nil {
+		return guardrailOutcome(err)
+	}
+	outcome.body = body
+	return outcome
+}
+
+func (c *llmCaller) send(
+	ctx context.Context,
+	endpoint, model string,
+	body []byte,
+	native *ollamanative.Options,
+	required []string,
+) llmOutcome {
+	route, err := c.svc.RouteBackend(ctx, model, required...)
+	if err != nil {
+		var capErr *CapabilityError
+		if errors.As(err, &capErr) {
+			return requestOutcome(capErr.Error())
+		}
+		return backendOutcome(http.StatusServiceUnavailable, err)
+	}
+	target := strings.TrimRight(route.Address, "/")
+
+	if native == nil {
+		status, payload, postErr := c.post(ctx, target+endpoint, body)
+		if postErr != nil {
+			return backendOutcome(http.StatusBadGateway, postErr)
+		}
+		return llmOutcome{status: status, body: payload}
+	}
+
+	nativeReq, err := ollamanative.Build(body, *native)
+	if err != nil {
+		return requestOutcome(err.Error())
+	}
+	encoded, err := json.Marshal(nativeReq.Payload)
+	if err != nil {
+		return requestOutcome(err.Error())
+	}
+	status, payload, err := c.post(ctx, target+nativeReq.Path, encoded)
+	if err != nil {
+		return backendOutcome(http.StatusBadGateway, err)
+	}
+	if status != http.StatusOK {
+		message := ollamanative.ErrorMessage(payload)
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		return llmOutcome{status: status, errMsg: message}
+	}
+	converted, err := nativeReq.Converter.Completion(payload)
+	if err != nil {
+		return backendOutcome(http.StatusBadGateway, err)
+	}
+	if payload, err = json.Marshal(converted); err != nil {
+		return backendOutcome(http.StatusBadGateway, err)
+	}
+	return llmOutcome{status: http.StatusOK, body: payload}
+}
+
+// structuredOutcome checks a strict json_schema completion against its schema. A mismatch is
+// reported as a backend failure so the request is retried.
+func structuredOutcome(outcome llmOutcome, format *structured.Format) llmOutcome {
+	if outcome.status != http.StatusOK || !format.Validates() {
+		return outcome
+	}
+	if err := format.Validate(outcome.body); err != nil {
+		return llmOutcome{
+			status: http.StatusBadGateway,
+			errMsg: "completion does not match the response_format schema: " + err.Error(),
+		}
+	}
+	return outcome
+}
+
+func (c *llmCaller) post(ctx context.Context, target string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, payload, nil
+}
+
+// llmProfileKind maps an endpoint to the profile kind applied to its requests. Embedding requests
+// are not profiled.
+func llmProfileKind(endpoint string) (profiles.Kind, bool) {
+	switch endpoint {
+	case BatchEndpointChatCompletions:
+		return profiles.KindChat, true
+	case BatchEndpointCompletions:
+		return profiles.KindCompletion, true
+	default:
+		return profiles.KindChat, false
+	}
+}
+
+// responseFormat parses the structured output a chat request asks for.
+func responseFormat(req profiles.Request, kind profiles.Kind) (*structured.Format, error) {
+	if kind != profiles.KindChat {
+		return nil, nil
+	}
+	return structured.Parse(req["response_format"])
+}
+
+func guardrailOutcome(err error) llmOutcome {
+	var violation *guardrails.Violation
+	if errors.As(err, &violation) {
+		return requestOutcome(violation.Error())
+	}
+	return backendOutcome(http.StatusBadGateway, err)
+}
+
+func requestOutcome(message string) llmOutcome {
+	return llmOutcome{status: http.StatusBadRequest, errMsg: message}
+}
+
+func backendOutcome(status int, err error) llmOutcome {
+	return llmOutcome{status: status, errMsg: err.Error()}
+}
+
+func retryableOutcome(outcome llmOutcome) bool {
+	return outcome.status == http.StatusTooManyRequests || outcome.status >= http.StatusInternalServerError
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ollama/ollama/api"
+
+	"github.com/rhajizada/llamero/internal/redisstore"
+)
+
+const (
+	showArchitectureKey     = "general.architecture"
+	showContextLengthSuffix = ".context_length"
+)
+
+// attachShowDetails enriches model metadata with capabilities and context length from Ollama's
+// /api/show. Results are cached by digest, so each model build is only inspected once.
+func (s *Service) attachShowDetails(ctx context.Context, client *api.Client, meta []redisstore.ModelInfo) {
+	for i := range meta {
+		info, ok := s.modelShowInfo(ctx, client, meta[i])
+		if !ok {
+			continue
+		}
+		applyShowInfo(&meta[i], info)
+	}
+}
+
+func (s *Service) modelShowInfo(
+	ctx context.Context,
+	client *api.Client,
+	model redisstore.ModelInfo,
+) (redisstore.ModelShowInfo, bool) {
+	if model.Digest != "" {
+		cached, found, err := s.store.GetModelShow(ctx, model.Digest)
+		if err == nil && found {
+			return cached, true
+		}
+	}
+
+	showCtx, cancel := context.WithTimeout(ctx, backendRequestTimeout)
+	defer cancel()
+	resp, err := client.Show(showCtx, &api.ShowRequest{Model: model.Name})
+	if err != nil {
+		return redisstore.ModelShowInfo{}, false
+	}
+
+	info := redisstore.ModelShowInfo{
+		Family:            resp.Details.Family,
+		Families:          append([]string(nil), resp.Details.Families...),
+		ParameterSize:     resp.Details.ParameterSize,
+		QuantizationLevel: resp.Details.QuantizationLevel,
+		ContextLength:     contextLengthFromModelInfo(resp.ModelInfo),
+		FetchedAt:         time.Now(),
+	}
+	for _, capability := range resp.Capabilities {
+		info.Capabilities = append(info.Capabilities, capability.String())
+	}
+	if model.Digest != "" {
+		// Caching is best effort; the next sync simply asks the backend again.
+		_ = s.store.SaveModelShow(ctx, model.Digest, info)
+	}
+	return info, true
+}
+
+func applyShowInfo(model *redisstore.ModelInfo, info redisstore.ModelShowInfo) {
+	model.Family = firstModelName(model.Family, info.Family)
+	model.ParameterSize = firstModelName(model.ParameterSize, info.ParameterSize)
+	model.QuantizationLevel = firstModelName(model.QuantizationLevel, info.QuantizationLevel)
+	if len(model.Families) == 0 {
+		model.Families = append([]string(nil), info.Families...)
+	}
+	model.ContextLength = info.ContextLength
+	model.Capabilities = append([]string(nil), info.Capabilities...)
+}
+
+// contextLengthFromModelInfo reads "<architecture>.context_length" from GGUF model info.
+func contextLengthFromModelInfo(info map[string]any) int {
+	if len(info) == 0 {
+		return 0
+	}
+	if arch, ok := info[showArchitectureKey].(string); ok && arch != "" {
+		if value, found := numericValue(info[arch+showContextLengthSuffix]); found {
+			return value
+		}
+	}
+	for key, raw := range info {
+		if !strings.HasSuffix(key, showContextLengthSuffix) {
+			continue
+		}
+		if value, found := numericValue(raw); found {
+			return value
+		}
+	}
+	return 0
+}
+
+func numericValue(raw any) (int, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
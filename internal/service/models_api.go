@@ -3,21 +3,28 @@ package service
 import (
 	"context"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/redisstore"
 )
 
-// ListModels returns LLM-compatible model metadata aggregated from backends.
-func (s *Service) ListModels(ctx context.Context) (models.ModelList, error) {
+// ListModels returns LLM-compatible model metadata aggregated from backends. Verbose listings
+// also include model details and per-backend availability.
+func (s *Service) ListModels(ctx context.Context, verbose bool) (models.ModelList, error) {
 	modelMap, err := s.collectModels(ctx)
 	if err != nil {
 		return models.ModelList{}, err
 	}
 	list := models.ModelList{Object: "list"}
 	for _, model := range modelMap {
+		if !verbose {
+			model.Details = nil
+			model.Backends = nil
+		}
 		list.Data = append(list.Data, model)
 	}
 	sort.Slice(list.Data, func(i, j int) bool {
@@ -26,11 +33,11 @@ func (s *Service) ListModels(ctx context.Context) (models.ModelList, error) {
 	return list, nil
 }
 
-// GetModel returns metadata for a single model.
+// GetModel returns metadata, details and per-backend availability for a single model.
 func (s *Service) GetModel(ctx context.Context, id string) (models.Model, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return models.Model{}, &Error{Code: http.StatusBadRequest, Message: "model id is required"}
+		return models.Model{}, &Error{Status: http.StatusBadRequest, Message: "model id is required"}
 	}
 	modelMap, err := s.collectModels(ctx)
 	if err != nil {
@@ -39,24 +46,47 @@ func (s *Service) GetModel(ctx context.Context, id string) (models.Model, error)
 	if model, ok := modelMap[id]; ok {
 		return model, nil
 	}
-	return models.Model{}, &Error{Code: http.StatusNotFound, Message: "model not found"}
+	return models.Model{}, &Error{
+		Status:  http.StatusNotFound,
+		Code:    ErrorCodeModelNotFound,
+		Param:   "model",
+		Message: "model not found",
+	}
+}
+
+// ModelContextLength returns the smallest context length reported for a model across backends,
+// or zero when no backend reports one.
+func (s *Service) ModelContextLength(ctx context.Context, model string) (int, error) {
+	statuses, err := s.store.ListBackends(ctx)
+	if err != nil {
+		return 0, err
+	}
+	length := 0
+	for _, status := range statuses {
+		for _, meta := range status.ModelMeta {
+			if meta.Name != model || meta.ContextLength <= 0 {
+				continue
+			}
+			if length == 0 || meta.ContextLength < length {
+				length = meta.ContextLength
+			}
+		}
+	}
+	return length, nil
 }
 
 func (s *Service) collectModels(ctx context.Context) (map[string]models.Model, error) {
 	statuses, err := s.store.ListBackends(ctx)
 	if err != nil {
-		return nil, &Error{Code: http.StatusInternalServerError, Message: "failed to load models", Err: err}
+		return nil, &Error{Status: http.StatusInternalServerError, Message: "failed to load models", Err: err}
 	}
 	modelMap := make(map[string]models.Model)
 	for _, status := range statuses {
 		now := status.UpdatedAt.Unix()
-		owned := status.ID
-		if owned == "" {
-			owned = defaultModelOwner
-		}
 		if len(status.ModelMeta) == 0 {
 			for _, name := range status.Models {
-				addModel(modelMap, name, now, owned)
+				addModel(modelMap, name, now, defaultModelOwner)
+				addAvailability(modelMap, status, redisstore.ModelInfo{Name: name})
 			}
 			continue
 		}
@@ -65,11 +95,8 @@ func (s *Service) collectModels(ctx context.Context) (map[string]models.Model, e
 			if created == 0 {
 				created = now
 			}
-			owner := meta.OwnedBy
-			if strings.TrimSpace(owner) == "" {
-				owner = owned
-			}
-			addModel(modelMap, meta.Name, created, owner)
+			addModel(modelMap, meta.Name, created, meta.OwnedBy)
+			addAvailability(modelMap, status, meta)
 		}
 	}
 	return modelMap, nil
@@ -105,4 +132,44 @@ func addModel(dest map[string]models.Model, name string, created int64, ownedBy
 	}
 }
 
+// addAvailability records where a model lives and folds its details into the catalog entry.
+func addAvailability(dest map[string]models.Model, status redisstore.BackendStatus, meta redisstore.ModelInfo) {
+	name := strings.TrimSpace(meta.Name)
+	entry, ok := dest[name]
+	if !ok {
+		return
+	}
+	entry.Backends = append(entry.Backends, models.ModelBackend{
+		BackendID:     status.ID,
+		Healthy:       status.Healthy,
+		Installed:     meta.Installed || contains(status.Models, name),
+		Loaded:        meta.Loaded || contains(status.LoadedModels, name),
+		Digest:        meta.Digest,
+		ContextLength: meta.ContextLength,
+		Capabilities:  append([]string(nil), meta.Capabilities...),
+	})
+
+	details := entry.Details
+	if details == nil {
+		details = &models.ModelMetadata{}
+	}
+	details.Format = firstModelName(details.Format, meta.Format)
+	details.Family = firstModelName(details.Family, meta.Family)
+	details.ParameterSize = firstModelName(details.ParameterSize, meta.ParameterSize)
+	details.QuantizationLevel = firstModelName(details.QuantizationLevel, meta.QuantizationLevel)
+	if len(details.Families) == 0 {
+		details.Families = append([]string(nil), meta.Families...)
+	}
+	if details.ContextLength == 0 {
+		details.ContextLength = meta.ContextLength
+	}
+	for _, capability := range meta.Capabilities {
+		if !slices.Contains(details.Capabilities, capability) {
+			details.Capabilities = append(details.Capabilities, capability)
+		}
+	}
+	entry.Details = details
+	dest[name] = entry
+}
+
 func timeNowUnix() int64 { return time.Now().Unix() }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/redisstore"
+)
+
+// CachedResponse is an LLM response replayed from the response cache.
+type CachedResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// CachedLLMResponse returns a previously stored response for the request hash.
+func (s *Service) CachedLLMResponse(ctx context.Context, hash string) (CachedResponse, bool, error) {
+	stored, found, err := s.store.GetResponse(ctx, hash)
+	if err != nil || !found {
+		return CachedResponse{}, false, err
+	}
+	return CachedResponse{
+		Status:      stored.Status,
+		ContentType: stored.ContentType,
+		Body:        stored.Body,
+	}, true, nil
+}
+
+// CacheLLMResponse stores a response under the request hash for the given TTL.
+func (s *Service) CacheLLMResponse(ctx context.Context, hash string, resp CachedResponse, ttl time.Duration) error {
+	return s.store.SaveResponse(ctx, hash, redisstore.CachedResponse{
+		Status:      resp.Status,
+		ContentType: resp.ContentType,
+		Body:        resp.Body,
+		StoredAt:    time.Now().UTC(),
+	}, ttl)
+}
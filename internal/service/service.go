@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/rhajizada/llamero/internal/models"
 	"github.com/rhajizada/llamero/internal/redisstore"
@@ -15,24 +16,33 @@ import (
 
 // Service contains the business logic that interacts with persistence.
 type Service struct {
+	db    *pgxpool.Pool
 	repo  *repository.Queries
 	store *redisstore.Store
 }
 
 // New creates a Service instance.
-func New(repo *repository.Queries, store *redisstore.Store) *Service {
+func New(pool *pgxpool.Pool, store *redisstore.Store) *Service {
 	return &Service{
-		repo:  repo,
+		db:    pool,
+		repo:  repository.New(pool),
 		store: store,
 	}
 }
 
+// inTx runs fn with queries bound to a transaction that commits when fn succeeds.
+func (s *Service) inTx(ctx context.Context, fn func(repo *repository.Queries) error) error {
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		return fn(s.repo.WithTx(tx))
+	})
+}
+
 // UpsertUser creates or updates a user record based on provider/sub.
 func (s *Service) UpsertUser(ctx context.Context, params repository.UpsertUserParams) (repository.User, error) {
 	user, err := s.repo.UpsertUser(ctx, params)
 	if err != nil {
 		return repository.User{}, &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to upsert user",
 			Err:     err,
 		}
@@ -46,13 +56,13 @@ func (s *Service) GetUser(ctx context.Context, id uuid.UUID) (models.User, error
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.User{}, &Error{
-				Code:    http.StatusNotFound,
+				Status:  http.StatusNotFound,
 				Message: "user not found",
 				Err:     err,
 			}
 		}
 		return models.User{}, &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to load user",
 			Err:     err,
 		}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+const (
+	// ServiceAccountProvider is the provider recorded for service accounts in place of an IdP.
+	ServiceAccountProvider = "service-account"
+	// ServiceAccountRole is the role service account tokens carry.
+	ServiceAccountRole = "service-account"
+
+	// serviceAccountEmailDomain is reserved (RFC 2606), so the placeholder addresses service
+	// accounts need cannot collide with real users.
+	serviceAccountEmailDomain = "service-accounts.invalid"
+
+	pgUniqueViolation = "23505"
+)
+
+var serviceAccountName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ServiceAccountParams describes a service account to create or update.
+type ServiceAccountParams struct {
+	Name        string
+	Description string
+	OwnerID     *uuid.UUID
+	Scopes      []string
+}
+
+// CreateServiceAccount stores a new service account.
+func (s *Service) CreateServiceAccount(
+	ctx context.Context,
+	params ServiceAccountParams,
+) (models.ServiceAccount, error) {
+	name := strings.TrimSpace(params.Name)
+	if !serviceAccountName.MatchString(name) {
+		return models.ServiceAccount{}, &Error{
+			Status:  http.StatusBadRequest,
+			Param:   "name",
+			Message: "name must be 1-63 lowercase letters, digits or dashes and start with a letter or digit",
+		}
+	}
+	if len(params.Scopes) == 0 {
+		return models.ServiceAccount{}, errScopesRequired()
+	}
+
+	account, err := s.repo.CreateServiceAccount(ctx, repository.CreateServiceAccountParams{
+		Name:        name,
+		Provider:    ServiceAccountProvider,
+		Email:       name + "@" + serviceAccountEmailDomain,
+		Role:        ServiceAccountRole,
+		Scopes:      params.Scopes,
+		Description: optionalString(params.Description),
+		OwnerID:     params.OwnerID,
+	})
+	if err != nil {
+		return models.ServiceAccount{}, serviceAccountWriteError(err, "failed to create service account")
+	}
+	return models.NewServiceAccountFromRepo(account), nil
+}
+
+// ListServiceAccounts returns every service account, newest first.
+func (s *Service) ListServiceAccounts(ctx context.Context) ([]models.ServiceAccount, error) {
+	records, err := s.repo.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to list service accounts",
+			Err:     err,
+		}
+	}
+	out := make([]models.ServiceAccount, 0, len(records))
+	for _, rec := range records {
+		out = append(out, models.NewServiceAccountFromRepo(rec))
+	}
+	return out, nil
+}
+
+// GetServiceAccount returns the user record backing a service account.
+func (s *Service) GetServiceAccount(ctx context.Context, id uuid.UUID) (repository.User, error) {
+	account, err := s.repo.GetServiceAccount(ctx, id)
+	if err != nil {
+		return repository.User{}, serviceAccountError(err, "failed to load service account")
+	}
+	return account, nil
+}
+
+// UpdateServiceAccount replaces a service account's description, owner and scopes. Tokens that
+// hold scopes outside the new set are revoked.
+func (s *Service) UpdateServiceAccount(
+	ctx context.Context,
+	id uuid.UUID,
+	params ServiceAccountParams,
+) (models.ServiceAccount, error) {
+	if len(params.Scopes) == 0 {
+		return models.ServiceAccount{}, errScopesRequired()
+	}
+	account, err := s.repo.UpdateServiceAccount(ctx, repository.UpdateServiceAccountParams{
+		ID:          id,
+		Description: optionalString(params.Description),
+		Scopes:      params.Scopes,
+		OwnerID:     params.OwnerID,
+	})
+	if err != nil {
+		return models.ServiceAccount{}, serviceAccountWriteError(err, "failed to update service account")
+	}
+	if err = s.revokeTokensOutsideScopes(ctx, id, params.Scopes); err != nil {
+		return models.ServiceAccount{}, err
+	}
+	return models.NewServiceAccountFromRepo(account), nil
+}
+
+// DeleteServiceAccount removes a service account and its tokens.
+func (s *Service) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteServiceAccount(ctx, id)
+	if err != nil {
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to delete service account",
+			Err:     err,
+		}
+	}
+	if deleted == 0 {
+		return errServiceAccountNotFound(nil)
+	}
+	return nil
+}
+
+// revokeTokensOutsideScopes revokes a principal's tokens that hold a scope the granted scopes no
+// longer cover.
+func (s *Service) revokeTokensOutsideScopes(ctx context.Context, userID uuid.UUID, granted []string) error {
+	tokens, err := s.repo.ListTokensByUser(ctx, userID)
+	if err != nil {
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to list tokens",
+			Err:     err,
+		}
+	}
+	for _, token := range tokens {
+		if auth.HasScopes(granted, token.Scopes) {
+			continue
+		}
+		_, err = s.repo.RevokeToken(ctx, repository.RevokeTokenParams{ID: token.ID, UserID: userID})
+		if err != nil {
+			return &Error{
+				Status:  http.StatusInternalServerError,
+				Message: "failed to revoke tokens",
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
+
+func errScopesRequired() *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Param:   "scopes",
+		Message: "at least one scope is required",
+	}
+}
+
+func errServiceAccountNotFound(err error) *Error {
+	return &Error{
+		Status:  http.StatusNotFound,
+		Message: "service account not found",
+		Err:     err,
+	}
+}
+
+func serviceAccountError(err error, message string) *Error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errServiceAccountNotFound(err)
+	}
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func serviceAccountWriteError(err error, message string) *Error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return &Error{
+				Status:  http.StatusConflict,
+				Param:   "name",
+				Message: "a service account with this name already exists",
+				Err:     err,
+			}
+		case pgForeignKeyViolation:
+			return &Error{
+				Status:  http.StatusBadRequest,
+				Param:   "owner_id",
+				Message: "owner does not exist",
+				Err:     err,
+			}
+		}
+	}
+	return serviceAccountError(err, message)
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// CreateSessionParams captures a new login session. Only the hash of the refresh token is stored.
+type CreateSessionParams struct {
+	UserID       uuid.UUID
+	JTI          string
+	RefreshToken string
+	UserAgent    string
+	IPAddress    string
+	ExpiresAt    time.Time
+}
+
+// CreateSession records a login session.
+func (s *Service) CreateSession(ctx context.Context, params CreateSessionParams) (repository.Session, error) {
+	if params.UserID == uuid.Nil || params.JTI == "" || params.RefreshToken == "" {
+		return repository.Session{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "incomplete session parameters",
+		}
+	}
+	session, err := s.repo.CreateSession(ctx, repository.CreateSessionParams{
+		UserID:           params.UserID,
+		Jti:              params.JTI,
+		RefreshTokenHash: auth.HashRefreshToken(params.RefreshToken),
+		UserAgent:        optionalString(params.UserAgent),
+		IPAddress:        optionalString(params.IPAddress),
+		ExpiresAt:        params.ExpiresAt,
+	})
+	if err != nil {
+		return repository.Session{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to create session",
+			Err:     err,
+		}
+	}
+	return session, nil
+}
+
+// RefreshSession exchanges a refresh token for its session and user, replacing the refresh token
+// with next and recording jti as the session's current access token. Presenting any refresh token
+// the session already replaced revokes the session, since it means the token leaked.
+func (s *Service) RefreshSession(
+	ctx context.Context,
+	refreshToken, next, jti string,
+) (repository.Session, repository.User, error) {
+	hash := auth.HashRefreshToken(refreshToken)
+	session, err := s.repo.GetSessionByRefreshToken(ctx, hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.revokeReusedSession(ctx, hash)
+		return repository.Session{}, repository.User{}, errInvalidRefreshToken(err)
+	}
+	if err != nil {
+		return repository.Session{}, repository.User{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to load session",
+			Err:     err,
+		}
+	}
+	if session.Revoked || time.Now().After(session.ExpiresAt) {
+		return repository.Session{}, repository.User{}, errInvalidRefreshToken(nil)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Session{}, repository.User{}, errInvalidRefreshToken(err)
+		}
+		return repository.Session{}, repository.User{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to load user",
+			Err:     err,
+		}
+	}
+	if user.Disabled {
+		return repository.Session{}, repository.User{}, errUserDisabled()
+	}
+
+	var rotated repository.Session
+	err = s.inTx(ctx, func(repo *repository.Queries) error {
+		var rotateErr error
+		rotated, rotateErr = repo.RotateSessionRefreshToken(ctx, repository.RotateSessionRefreshTokenParams{
+			Jti:                     jti,
+			RefreshTokenHash:        auth.HashRefreshToken(next),
+			ID:                      session.ID,
+			CurrentRefreshTokenHash: hash,
+		})
+		if rotateErr != nil {
+			return rotateErr
+		}
+		return repo.RetireSessionRefreshToken(ctx, repository.RetireSessionRefreshTokenParams{
+			RefreshTokenHash: hash,
+			SessionID:        session.ID,
+		})
+	})
+	if err != nil {
+		// A concurrent refresh with the same token won the race.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Session{}, repository.User{}, errInvalidRefreshToken(err)
+		}
+		return repository.Session{}, repository.User{}, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to refresh session",
+			Err:     err,
+		}
+	}
+	return rotated, user, nil
+}
+
+// RevokeSessionByRefreshToken ends the session a refresh token belongs to. Unknown tokens are
+// ignored so logging out twice succeeds.
+func (s *Service) RevokeSessionByRefreshToken(ctx context.Context, refreshToken string) error {
+	session, err := s.repo.GetSessionByRefreshToken(ctx, auth.HashRefreshToken(refreshToken))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to load session",
+			Err:     err,
+		}
+	}
+	return s.RevokeSession(ctx, session.UserID, session.ID)
+}
+
+// ListSessions returns a user's active sessions, most recently used first.
+func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	records, err := s.repo.ListActiveSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to list sessions",
+			Err:     err,
+		}
+	}
+	out := make([]models.Session, 0, len(records))
+	for _, rec := range records {
+		out = append(out, models.NewSessionFromRepo(rec))
+	}
+	return out, nil
+}
+
+// RevokeSession ends one of a user's sessions. Its access tokens are rejected from then on and its
+// refresh token stops working.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if userID == uuid.Nil || sessionID == uuid.Nil {
+		return &Error{
+			Status:  http.StatusBadRequest,
+			Message: "session id is required",
+		}
+	}
+	_, err := s.repo.RevokeSession(ctx, repository.RevokeSessionParams{
+		ID:     sessionID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &Error{
+				Status:  http.StatusNotFound,
+				Message: "session not found",
+				Err:     err,
+			}
+		}
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to revoke session",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// ValidateSession ensures the session behind a session token is still active and that the token is
+// the session's current access token, so tokens replaced by a refresh stop working. Session tokens
+// without a session ID are rejected.
+func (s *Service) ValidateSession(ctx context.Context, claims *auth.Claims) error {
+	if claims == nil {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "missing token claims",
+		}
+	}
+	if strings.TrimSpace(claims.SessionID) == "" {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "session token is not bound to a session",
+		}
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "invalid session identifier",
+			Err:     err,
+		}
+	}
+
+	session, err := s.repo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &Error{
+				Status:  http.StatusUnauthorized,
+				Message: "session has been revoked",
+				Err:     err,
+			}
+		}
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to validate session",
+			Err:     err,
+		}
+	}
+	if session.Revoked {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "session has been revoked",
+		}
+	}
+	if session.UserID.String() != claims.Subject {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "session subject mismatch",
+		}
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "session has expired",
+		}
+	}
+	if claims.ID == "" || claims.ID != session.Jti {
+		return &Error{
+			Status:  http.StatusUnauthorized,
+			Message: "access token has been replaced",
+		}
+	}
+	return nil
+}
+
+func (s *Service) revokeReusedSession(ctx context.Context, hash string) {
+	session, err := s.repo.GetSessionByRetiredRefreshToken(ctx, hash)
+	if err != nil {
+		return
+	}
+	_ = s.RevokeSession(ctx, session.UserID, session.ID)
+}
+
+func errInvalidRefreshToken(err error) *Error {
+	return &Error{
+		Status:  http.StatusUnauthorized,
+		Message: "invalid or expired refresh token",
+		Err:     err,
+	}
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rhajizada/llamero/internal/auth"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// ListSigningKeys returns rotated signing keys and the retirement records of configured keys,
+// newest first. It implements auth.KeyStore.
+func (s *Service) ListSigningKeys(ctx context.Context) ([]auth.StoredKey, error) {
+	rows, err := s.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]auth.StoredKey, 0, len(rows))
+	for _, row := range rows {
+		key := auth.StoredKey{
+			ID:          row.Kid,
+			Algorithm:   row.Algorithm,
+			PublicKey:   row.PublicKey,
+			CreatedAt:   row.CreatedAt,
+			RetiredAt:   row.RetiredAt,
+			VerifyUntil: row.VerifyUntil,
+		}
+		if row.PrivateKey != nil {
+			key.PrivateKey = *row.PrivateKey
+		}
+		out = append(out, key)
+	}
+	return out, nil
+}
+
+// RotateSigningKeys retires the supplied keys and stores next as the active signing key in one
+// transaction. Retired keys verify until verifyUntil or until the last active personal access
+// token expires, whichever is later. It implements auth.KeyStore.
+func (s *Service) RotateSigningKeys(
+	ctx context.Context,
+	retire []auth.StoredKey,
+	next auth.StoredKey,
+	verifyUntil time.Time,
+) error {
+	latestPAT, err := s.repo.LatestPersonalAccessTokenExpiry(ctx)
+	if err != nil {
+		return fmt.Errorf("load token expiry: %w", err)
+	}
+	if latestPAT.After(verifyUntil) {
+		verifyUntil = latestPAT
+	}
+
+	return s.inTx(ctx, func(repo *repository.Queries) error {
+		if createErr := repo.CreateSigningKey(ctx, repository.CreateSigningKeyParams{
+			Kid:        next.ID,
+			Algorithm:  next.Algorithm,
+			PublicKey:  next.PublicKey,
+			PrivateKey: &next.PrivateKey,
+		}); createErr != nil {
+			return fmt.Errorf("store signing key: %w", createErr)
+		}
+		for _, key := range retire {
+			if retireErr := repo.RetireSigningKey(ctx, repository.RetireSigningKeyParams{
+				Kid:         key.ID,
+				Algorithm:   key.Algorithm,
+				PublicKey:   key.PublicKey,
+				VerifyUntil: verifyUntil,
+			}); retireErr != nil {
+				return fmt.Errorf("retire signing key %s: %w", key.ID, retireErr)
+			}
+		}
+		return nil
+	})
+}
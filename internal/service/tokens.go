@@ -23,6 +23,8 @@ type CreateTokenParams struct {
 	TokenType string
 	JTI       string
 	ExpiresAt time.Time
+	// ResponseCache opts the token in to the LLM response cache.
+	ResponseCache bool
 }
 
 // CreatePersonalAccessToken stores PAT metadata for the supplied user.
@@ -33,31 +35,31 @@ func (s *Service) CreatePersonalAccessToken(
 	name := strings.TrimSpace(params.Name)
 	if name == "" {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "token name is required",
 		}
 	}
 	if params.UserID == uuid.Nil {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "user id is required",
 		}
 	}
 	if len(params.Scopes) == 0 {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "at least one scope is required",
 		}
 	}
 	if params.ExpiresAt.Before(time.Now()) {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "expiration must be in the future",
 		}
 	}
 	if params.JTI == "" {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "token identifier is required",
 		}
 	}
@@ -67,16 +69,17 @@ func (s *Service) CreatePersonalAccessToken(
 	}
 
 	record, err := s.repo.CreateToken(ctx, repository.CreateTokenParams{
-		UserID:    params.UserID,
-		Name:      name,
-		Scopes:    params.Scopes,
-		TokenType: tokenType,
-		Jti:       params.JTI,
-		ExpiresAt: params.ExpiresAt,
+		UserID:        params.UserID,
+		Name:          name,
+		Scopes:        params.Scopes,
+		TokenType:     tokenType,
+		Jti:           params.JTI,
+		ExpiresAt:     params.ExpiresAt,
+		ResponseCache: params.ResponseCache,
 	})
 	if err != nil {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to create token",
 			Err:     err,
 		}
@@ -91,14 +94,14 @@ func (s *Service) ListPersonalAccessTokens(
 ) ([]models.PersonalAccessToken, error) {
 	if userID == uuid.Nil {
 		return nil, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "user id is required",
 		}
 	}
 	records, err := s.repo.ListTokensByUser(ctx, userID)
 	if err != nil {
 		return nil, &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to list tokens",
 			Err:     err,
 		}
@@ -117,7 +120,7 @@ func (s *Service) GetPersonalAccessToken(
 ) (models.PersonalAccessToken, error) {
 	if userID == uuid.Nil || tokenID == uuid.Nil {
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "token id is required",
 		}
 	}
@@ -128,13 +131,13 @@ func (s *Service) GetPersonalAccessToken(
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.PersonalAccessToken{}, &Error{
-				Code:    http.StatusNotFound,
+				Status:  http.StatusNotFound,
 				Message: "token not found",
 				Err:     err,
 			}
 		}
 		return models.PersonalAccessToken{}, &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to load token",
 			Err:     err,
 		}
@@ -146,7 +149,7 @@ func (s *Service) GetPersonalAccessToken(
 func (s *Service) RevokePersonalAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
 	if userID == uuid.Nil || tokenID == uuid.Nil {
 		return &Error{
-			Code:    http.StatusBadRequest,
+			Status:  http.StatusBadRequest,
 			Message: "token id is required",
 		}
 	}
@@ -157,13 +160,13 @@ func (s *Service) RevokePersonalAccessToken(ctx context.Context, userID, tokenID
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return &Error{
-				Code:    http.StatusNotFound,
+				Status:  http.StatusNotFound,
 				Message: "token not found",
 				Err:     err,
 			}
 		}
 		return &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to revoke token",
 			Err:     err,
 		}
@@ -175,13 +178,13 @@ func (s *Service) RevokePersonalAccessToken(ctx context.Context, userID, tokenID
 func (s *Service) ValidatePAT(ctx context.Context, claims *auth.Claims) error {
 	if claims == nil {
 		return &Error{
-			Code:    http.StatusUnauthorized,
+			Status:  http.StatusUnauthorized,
 			Message: "missing token claims",
 		}
 	}
 	if strings.TrimSpace(claims.ID) == "" {
 		return &Error{
-			Code:    http.StatusUnauthorized,
+			Status:  http.StatusUnauthorized,
 			Message: "token identifier is missing",
 		}
 	}
@@ -190,13 +193,13 @@ func (s *Service) ValidatePAT(ctx context.Context, claims *auth.Claims) error {
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return &Error{
-				Code:    http.StatusUnauthorized,
+				Status:  http.StatusUnauthorized,
 				Message: "token has been revoked",
 				Err:     err,
 			}
 		}
 		return &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to validate token",
 			Err:     err,
 		}
@@ -204,31 +207,34 @@ func (s *Service) ValidatePAT(ctx context.Context, claims *auth.Claims) error {
 
 	if record.TokenType != auth.TokenTypePAT {
 		return &Error{
-			Code:    http.StatusUnauthorized,
+			Status:  http.StatusUnauthorized,
 			Message: "token type mismatch",
 		}
 	}
 	if record.Revoked {
 		return &Error{
-			Code:    http.StatusUnauthorized,
+			Status:  http.StatusUnauthorized,
 			Message: "token has been revoked",
 		}
 	}
 	if record.UserID.String() != claims.Subject {
 		return &Error{
-			Code:    http.StatusUnauthorized,
+			Status:  http.StatusUnauthorized,
 			Message: "token subject mismatch",
 		}
 	}
 	if time.Now().After(record.ExpiresAt) {
 		return &Error{
-			Code:    http.StatusUnauthorized,
+			Status:  http.StatusUnauthorized,
 			Message: "token has expired",
 		}
 	}
+	if err = s.ensureUserEnabled(ctx, record.UserID); err != nil {
+		return err
+	}
 	if tuErr := s.repo.MarkTokenUsed(ctx, record.ID); tuErr != nil {
 		return &Error{
-			Code:    http.StatusInternalServerError,
+			Status:  http.StatusInternalServerError,
 			Message: "failed to record token usage",
 			Err:     tuErr,
 		}
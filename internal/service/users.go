@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rhajizada/llamero/internal/models"
+	"github.com/rhajizada/llamero/internal/repository"
+)
+
+// ListUsers returns a page of users, newest first, whose email or display name contains search or
+// whose subject equals it. An empty search matches everyone. The total counts all matches.
+func (s *Service) ListUsers(
+	ctx context.Context,
+	search string,
+	limit, offset int32,
+) ([]models.User, int64, error) {
+	query := optionalString(search)
+	records, err := s.repo.ListUsers(ctx, repository.ListUsersParams{
+		Search:    query,
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to list users",
+			Err:     err,
+		}
+	}
+	total, err := s.repo.CountUsers(ctx, query)
+	if err != nil {
+		return nil, 0, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to count users",
+			Err:     err,
+		}
+	}
+	out := make([]models.User, 0, len(records))
+	for _, rec := range records {
+		out = append(out, models.NewUserFromRepo(rec))
+	}
+	return out, total, nil
+}
+
+// UpdateUserAccess sets a user's effective role and scopes and records the override that keeps
+// them across logins. Nil overrides let the next login restore the values mapped from IdP groups.
+// Personal access tokens holding scopes outside the new set are revoked.
+func (s *Service) UpdateUserAccess(
+	ctx context.Context,
+	params repository.UpdateUserAccessParams,
+) (models.User, error) {
+	if strings.TrimSpace(params.Role) == "" || len(params.Scopes) == 0 {
+		return models.User{}, &Error{
+			Status:  http.StatusBadRequest,
+			Message: "role and scopes are required",
+		}
+	}
+	user, err := s.repo.UpdateUserAccess(ctx, params)
+	if err != nil {
+		return models.User{}, userError(err, "failed to update user")
+	}
+	if err = s.revokeTokensOutsideScopes(ctx, user.ID, user.Scopes); err != nil {
+		return models.User{}, err
+	}
+	return models.NewUserFromRepo(user), nil
+}
+
+// SetUserDisabled disables or re-enables a user. Disabled users cannot log in, their sessions are
+// revoked and their personal access tokens are rejected until they are enabled again.
+func (s *Service) SetUserDisabled(ctx context.Context, userID uuid.UUID, disabled bool) (models.User, error) {
+	user, err := s.repo.SetUserDisabled(ctx, repository.SetUserDisabledParams{
+		ID:       userID,
+		Disabled: disabled,
+	})
+	if err != nil {
+		return models.User{}, userError(err, "failed to update user")
+	}
+	if disabled {
+		if err = s.repo.RevokeUserSessions(ctx, userID); err != nil {
+			return models.User{}, &Error{
+				Status:  http.StatusInternalServerError,
+				Message: "failed to revoke sessions",
+				Err:     err,
+			}
+		}
+	}
+	return models.NewUserFromRepo(user), nil
+}
+
+// DeleteUser removes a user together with their tokens and sessions.
+func (s *Service) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	deleted, err := s.repo.DeleteUser(ctx, userID)
+	if err != nil {
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to delete user",
+			Err:     err,
+		}
+	}
+	if deleted == 0 {
+		return &Error{
+			Status:  http.StatusNotFound,
+			Message: "user not found",
+		}
+	}
+	return nil
+}
+
+// ensureUserEnabled rejects credentials of users that were disabled or deleted.
+func (s *Service) ensureUserEnabled(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &Error{
+				Status:  http.StatusUnauthorized,
+				Message: "user no longer exists",
+				Err:     err,
+			}
+		}
+		return &Error{
+			Status:  http.StatusInternalServerError,
+			Message: "failed to load user",
+			Err:     err,
+		}
+	}
+	if user.Disabled {
+		return errUserDisabled()
+	}
+	return nil
+}
+
+func errUserDisabled() *Error {
+	return &Error{
+		Status:  http.StatusForbidden,
+		Message: "user account is disabled",
+	}
+}
+
+func userError(err error, message string) *Error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{
+			Status:  http.StatusNotFound,
+			Message: "user not found",
+			Err:     err,
+		}
+	}
+	return &Error{
+		Status:  http.StatusInternalServerError,
+		Message: message,
+		Err:     err,
+	}
+}
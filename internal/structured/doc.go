@@ -0,0 +1,3 @@
+// Package structured parses the response_format of chat completion requests, translates it into
+// Ollama's format field and validates completions against a requested JSON schema.
+package structured
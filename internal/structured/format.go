@@ -0,0 +1,156 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Response format types accepted in response_format.
+const (
+	TypeText       = "text"
+	TypeJSONObject = "json_object"
+	TypeJSONSchema = "json_schema"
+)
+
+// ollamaJSONFormat asks Ollama for any well-formed JSON value.
+const ollamaJSONFormat = `"json"`
+
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Format is the structured output requested by a chat completion.
+type Format struct {
+	Type   string
+	Name   string
+	Schema json.RawMessage
+	// Strict asks Llamero to validate non-streamed completions against Schema.
+	Strict bool
+
+	compiled *Schema
+}
+
+type formatSpec struct {
+	Type       string          `json:"type"`
+	JSONSchema *jsonSchemaSpec `json:"json_schema"`
+}
+
+type jsonSchemaSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Strict      *bool           `json:"strict"`
+}
+
+// Parse reads a response_format value. It returns nil when the value is absent or asks for plain
+// text.
+func Parse(raw json.RawMessage) (*Format, error) {
+	if len(raw) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, nil
+	}
+	var spec formatSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, errors.New("response_format must be an object")
+	}
+	switch spec.Type {
+	case "", TypeText:
+		return nil, nil
+	case TypeJSONObject:
+		return &Format{Type: TypeJSONObject}, nil
+	case TypeJSONSchema:
+		return parseJSONSchema(spec.JSONSchema)
+	default:
+		return nil, fmt.Errorf("unsupported response_format type %q", spec.Type)
+	}
+}
+
+func parseJSONSchema(spec *jsonSchemaSpec) (*Format, error) {
+	if spec == nil {
+		return nil, errors.New("response_format.json_schema is required for type json_schema")
+	}
+	if !schemaNamePattern.MatchString(spec.Name) {
+		return nil, errors.New("response_format.json_schema.name must be 1-64 letters, digits, '_' or '-'")
+	}
+	if len(bytes.TrimSpace(spec.Schema)) == 0 {
+		return nil, errors.New("response_format.json_schema.schema is required")
+	}
+	compiled, err := Compile(spec.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("response_format.json_schema.schema: %w", err)
+	}
+	return &Format{
+		Type:     TypeJSONSchema,
+		Name:     spec.Name,
+		Schema:   spec.Schema,
+		Strict:   spec.Strict != nil && *spec.Strict,
+		compiled: compiled,
+	}, nil
+}
+
+// Ollama returns the value for Ollama's format field: the schema itself, or "json" for
+// json_object. A nil Format yields nil.
+func (f *Format) Ollama() json.RawMessage {
+	if f == nil {
+		return nil
+	}
+	if f.Type == TypeJSONSchema {
+		return f.Schema
+	}
+	return json.RawMessage(ollamaJSONFormat)
+}
+
+// Validates reports whether completions must be checked against the schema.
+func (f *Format) Validates() bool {
+	return f != nil && f.Type == TypeJSONSchema && f.Strict
+}
+
+type completionChoices struct {
+	Choices []struct {
+		Index   int `json:"index"`
+		Message struct {
+			Content   string          `json:"content"`
+			ToolCalls json.RawMessage `json:"tool_calls"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// Validate checks the message content of every choice in a chat completion response. Choices that
+// only call tools are skipped.
+func (f *Format) Validate(completion []byte) error {
+	var resp completionChoices
+	if err := json.Unmarshal(completion, &resp); err != nil {
+		return fmt.Errorf("decode completion: %w", err)
+	}
+	for _, choice := range resp.Choices {
+		content := strings.TrimSpace(choice.Message.Content)
+		if content == "" && len(choice.Message.ToolCalls) > 0 {
+			continue
+		}
+		if err := f.validateContent(content); err != nil {
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				verr.Choice = choice.Index
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Format) validateContent(content string) error {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return &ValidationError{Path: "$", Reason: "output is not valid JSON"}
+	}
+	if f.compiled == nil {
+		if _, ok := value.(map[string]any); !ok {
+			return &ValidationError{Path: "$", Reason: "output is not a JSON object"}
+		}
+		return nil
+	}
+	return f.compiled.Validate(value)
+}
@@ -0,0 +1,568 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	refRoot        = "#"
+	refDefsPrefix  = "#/$defs/"
+	refDefinitions = "#/definitions/"
+
+	maxValidationDepth = 64
+)
+
+// Schema is a compiled JSON schema. It understands the keywords structured output relies on:
+// type, enum, const, properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf,
+// anyOf, oneOf and local $ref pointers into $defs or definitions. Other keywords are ignored.
+type Schema struct {
+	always *bool
+	ref    string
+	defs   *definitions
+
+	types    []string
+	enum     []any
+	constant any
+	hasConst bool
+
+	properties map[string]*Schema
+	required   []string
+	additional *Schema
+
+	items    *Schema
+	minItems *int
+	maxItems *int
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+}
+
+// definitions holds the targets $ref pointers resolve to.
+type definitions struct {
+	named map[string]*Schema
+	refs  []string
+}
+
+// ValidationError reports where a completion departs from the requested schema.
+type ValidationError struct {
+	Choice int
+	Path   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("choice %d: %s: %s", e.Choice, e.Path, e.Reason)
+}
+
+// Compile parses a JSON schema document.
+func Compile(raw json.RawMessage) (*Schema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.New("schema must be valid JSON")
+	}
+	defs := &definitions{named: map[string]*Schema{}}
+	root, err := compile(doc, defs)
+	if err != nil {
+		return nil, err
+	}
+	defs.named[refRoot] = root
+	if object, ok := doc.(map[string]any); ok {
+		for _, keyword := range []string{"$defs", "definitions"} {
+			if err = compileDefinitions(object[keyword], keyword, defs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, ref := range defs.refs {
+		if _, ok := defs.named[ref]; !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return root, nil
+}
+
+func compileDefinitions(node any, keyword string, defs *definitions) error {
+	if node == nil {
+		return nil
+	}
+	entries, ok := node.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s must be an object", keyword)
+	}
+	for name, entry := range entries {
+		schema, err := compile(entry, defs)
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", keyword, name, err)
+		}
+		defs.named["#/"+keyword+"/"+name] = schema
+	}
+	return nil
+}
+
+func compile(node any, defs *definitions) (*Schema, error) {
+	if value, ok := node.(bool); ok {
+		return &Schema{always: &value, defs: defs}, nil
+	}
+	object, ok := node.(map[string]any)
+	if !ok {
+		return nil, errors.New("schema must be an object or a boolean")
+	}
+	schema := &Schema{defs: defs}
+	steps := []func(map[string]any) error{
+		schema.compileRef,
+		schema.compileType,
+		schema.compileObject,
+		schema.compileArray,
+		schema.compileString,
+		schema.compileNumber,
+		schema.compileCombinators,
+	}
+	for _, step := range steps {
+		if err := step(object); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+func (s *Schema) compileRef(object map[string]any) error {
+	raw, ok := object["$ref"]
+	if !ok {
+		return nil
+	}
+	ref, _ := raw.(string)
+	if ref != refRoot && !strings.HasPrefix(ref, refDefsPrefix) && !strings.HasPrefix(ref, refDefinitions) {
+		return fmt.Errorf("unsupported $ref %q: only local $defs and definitions are resolved", ref)
+	}
+	s.ref = ref
+	s.defs.refs = append(s.defs.refs, ref)
+	return nil
+}
+
+func (s *Schema) compileType(object map[string]any) error {
+	switch value := object["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{value}
+	case []any:
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				return errors.New("type must be a string or an array of strings")
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return errors.New("type must be a string or an array of strings")
+	}
+	for _, name := range s.types {
+		switch name {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return fmt.Errorf("unknown type %q", name)
+		}
+	}
+	if raw, ok := object["enum"]; ok {
+		values, isArray := raw.([]any)
+		if !isArray {
+			return errors.New("enum must be an array")
+		}
+		s.enum = values
+	}
+	s.constant, s.hasConst = object["const"]
+	return nil
+}
+
+func (s *Schema) compileObject(object map[string]any) error {
+	if raw, ok := object["properties"]; ok {
+		properties, isObject := raw.(map[string]any)
+		if !isObject {
+			return errors.New("properties must be an object")
+		}
+		s.properties = make(map[string]*Schema, len(properties))
+		for name, node := range properties {
+			property, err := compile(node, s.defs)
+			if err != nil {
+				return fmt.Errorf("properties.%s: %w", name, err)
+			}
+			s.properties[name] = property
+		}
+	}
+	if raw, ok := object["required"]; ok {
+		names, isArray := raw.([]any)
+		if !isArray {
+			return errors.New("required must be an array of strings")
+		}
+		for _, item := range names {
+			name, isString := item.(string)
+			if !isString {
+				return errors.New("required must be an array of strings")
+			}
+			s.required = append(s.required, name)
+		}
+	}
+	if raw, ok := object["additionalProperties"]; ok {
+		additional, err := compile(raw, s.defs)
+		if err != nil {
+			return fmt.Errorf("additionalProperties: %w", err)
+		}
+		s.additional = additional
+	}
+	return nil
+}
+
+func (s *Schema) compileArray(object map[string]any) error {
+	if raw, ok := object["items"]; ok {
+		items, err := compile(raw, s.defs)
+		if err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+		s.items = items
+	}
+	var err error
+	if s.minItems, err = intKeyword(object, "minItems"); err != nil {
+		return err
+	}
+	s.maxItems, err = intKeyword(object, "maxItems")
+	return err
+}
+
+func (s *Schema) compileString(object map[string]any) error {
+	var err error
+	if s.minLength, err = intKeyword(object, "minLength"); err != nil {
+		return err
+	}
+	if s.maxLength, err = intKeyword(object, "maxLength"); err != nil {
+		return err
+	}
+	if raw, ok := object["pattern"]; ok {
+		pattern, isString := raw.(string)
+		if !isString {
+			return errors.New("pattern must be a string")
+		}
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) compileNumber(object map[string]any) error {
+	bounds := []struct {
+		keyword string
+		target  **float64
+	}{
+		{"minimum", &s.minimum},
+		{"maximum", &s.maximum},
+		{"exclusiveMinimum", &s.exclusiveMinimum},
+		{"exclusiveMaximum", &s.exclusiveMaximum},
+	}
+	for _, bound := range bounds {
+		raw, ok := object[bound.keyword]
+		if !ok {
+			continue
+		}
+		value, isNumber := numberValue(raw)
+		if !isNumber {
+			return fmt.Errorf("%s must be a number", bound.keyword)
+		}
+		*bound.target = &value
+	}
+	return nil
+}
+
+func (s *Schema) compileCombinators(object map[string]any) error {
+	combinators := []struct {
+		keyword string
+		target  *[]*Schema
+	}{
+		{"allOf", &s.allOf},
+		{"anyOf", &s.anyOf},
+		{"oneOf", &s.oneOf},
+	}
+	for _, combinator := range combinators {
+		raw, ok := object[combinator.keyword]
+		if !ok {
+			continue
+		}
+		nodes, isArray := raw.([]any)
+		if !isArray || len(nodes) == 0 {
+			return fmt.Errorf("%s must be a non-empty array", combinator.keyword)
+		}
+		for i, node := range nodes {
+			schema, err := compile(node, s.defs)
+			if err != nil {
+				return fmt.Errorf("%s[%d]: %w", combinator.keyword, i, err)
+			}
+			*combinator.target = append(*combinator.target, schema)
+		}
+	}
+	return nil
+}
+
+// Validate checks a value decoded with json.Decoder.UseNumber against the schema.
+func (s *Schema) Validate(value any) error {
+	return s.validate(value, "$", 0)
+}
+
+func (s *Schema) validate(value any, path string, depth int) error {
+	if depth > maxValidationDepth {
+		return &ValidationError{Path: path, Reason: "value is nested too deeply"}
+	}
+	if s.always != nil {
+		if !*s.always {
+			return &ValidationError{Path: path, Reason: "no value is allowed here"}
+		}
+		return nil
+	}
+	if s.ref != "" {
+		if err := s.defs.named[s.ref].validate(value, path, depth+1); err != nil {
+			return err
+		}
+	}
+	if err := s.validateValue(value, path); err != nil {
+		return err
+	}
+	var err error
+	switch typed := value.(type) {
+	case map[string]any:
+		err = s.validateObject(typed, path, depth)
+	case []any:
+		err = s.validateArray(typed, path, depth)
+	case string:
+		err = s.validateString(typed, path)
+	case json.Number:
+		err = s.validateNumber(typed, path)
+	}
+	if err != nil {
+		return err
+	}
+	return s.validateCombinators(value, path, depth)
+}
+
+func (s *Schema) validateValue(value any, path string) error {
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(name string) bool { return hasType(value, name) }) {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected %s", joinTypes(s.types))}
+	}
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(item any) bool { return equalJSON(item, value) }) {
+		return &ValidationError{Path: path, Reason: "value is not one of the allowed enum values"}
+	}
+	if s.hasConst && !equalJSON(s.constant, value) {
+		return &ValidationError{Path: path, Reason: "value does not match const"}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(object map[string]any, path string, depth int) error {
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("missing required property %q", name)}
+		}
+	}
+	for name, value := range object {
+		child := path + "." + name
+		if property, ok := s.properties[name]; ok {
+			if err := property.validate(value, child, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		if s.additional == nil {
+			continue
+		}
+		if s.additional.always != nil && !*s.additional.always {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("unexpected property %q", name)}
+		}
+		if err := s.additional.validate(value, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(items []any, path string, depth int) error {
+	if s.minItems != nil && len(items) < *s.minItems {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at least %d items", *s.minItems)}
+	}
+	if s.maxItems != nil && len(items) > *s.maxItems {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at most %d items", *s.maxItems)}
+	}
+	if s.items == nil {
+		return nil
+	}
+	for i, item := range items {
+		if err := s.items.validate(item, path+"["+strconv.Itoa(i)+"]", depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(value, path string) error {
+	length := utf8.RuneCountInString(value)
+	if s.minLength != nil && length < *s.minLength {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at least %d characters", *s.minLength)}
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at most %d characters", *s.maxLength)}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("value does not match pattern %q", s.pattern)}
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(number json.Number, path string) error {
+	value, err := number.Float64()
+	if err != nil {
+		return &ValidationError{Path: path, Reason: "value is not a valid number"}
+	}
+	switch {
+	case s.minimum != nil && value < *s.minimum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected a value >= %v", *s.minimum)}
+	case s.maximum != nil && value > *s.maximum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected a value <= %v", *s.maximum)}
+	case s.exclusiveMinimum != nil && value <= *s.exclusiveMinimum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected a value > %v", *s.exclusiveMinimum)}
+	case s.exclusiveMaximum != nil && value >= *s.exclusiveMaximum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected a value < %v", *s.exclusiveMaximum)}
+	}
+	return nil
+}
+
+func (s *Schema) validateCombinators(value any, path string, depth int) error {
+	for _, schema := range s.allOf {
+		if err := schema.validate(value, path, depth+1); err != nil {
+			return err
+		}
+	}
+	if len(s.anyOf) > 0 && s.matches(s.anyOf, value, path, depth) == 0 {
+		return &ValidationError{Path: path, Reason: "value matches none of the anyOf schemas"}
+	}
+	if len(s.oneOf) > 0 {
+		if matched := s.matches(s.oneOf, value, path, depth); matched != 1 {
+			return &ValidationError{
+				Path:   path,
+				Reason: fmt.Sprintf("value matches %d of the oneOf schemas, expected exactly one", matched),
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) matches(schemas []*Schema, value any, path string, depth int) int {
+	matched := 0
+	for _, schema := range schemas {
+		if schema.validate(value, path, depth+1) == nil {
+			matched++
+		}
+	}
+	return matched
+}
+
+func hasType(value any, name string) bool {
+	switch typed := value.(type) {
+	case nil:
+		return name == "null"
+	case bool:
+		return name == "boolean"
+	case map[string]any:
+		return name == "object"
+	case []any:
+		return name == "array"
+	case string:
+		return name == "string"
+	case json.Number:
+		if name == "number" {
+			return true
+		}
+		if name != "integer" {
+			return false
+		}
+		number, err := typed.Float64()
+		return err == nil && number == math.Trunc(number)
+	default:
+		return false
+	}
+}
+
+func joinTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("one of %v", types)
+}
+
+// equalJSON compares decoded JSON values, treating numbers by value.
+func equalJSON(a, b any) bool {
+	switch left := a.(type) {
+	case json.Number:
+		leftValue, leftOK := numberValue(left)
+		rightValue, rightOK := numberValue(b)
+		return leftOK && rightOK && leftValue == rightValue
+	case map[string]any:
+		right, ok := b.(map[string]any)
+		if !ok || len(left) != len(right) {
+			return false
+		}
+		for key, value := range left {
+			other, found := right[key]
+			if !found || !equalJSON(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		right, ok := b.([]any)
+		return ok && slices.EqualFunc(left, right, equalJSON)
+	default:
+		return a == b
+	}
+}
+
+func numberValue(value any) (float64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	parsed, err := number.Float64()
+	return parsed, err == nil
+}
+
+func intKeyword(object map[string]any, keyword string) (*int, error) {
+	raw, ok := object[keyword]
+	if !ok {
+		return nil, nil
+	}
+	number, isNumber := raw.(json.Number)
+	if !isNumber {
+		return nil, fmt.Errorf("%s must be a non-negative integer", keyword)
+	}
+	value, err := strconv.Atoi(number.String())
+	if err != nil || value < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer", keyword)
+	}
+	return &value, nil
+}
@@ -7,19 +7,37 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 
+	"github.com/rhajizada/llamero/internal/config"
 	"github.com/rhajizada/llamero/internal/service"
 )
 
 // Handler defines task handlers for Asynq.
 type Handler struct {
-	svc *service.Service
+	svc      *service.Service
+	batches  *service.BatchRunner
+	jobs     *service.JobRunner
+	tasks    *asynq.Client
+	webhooks config.WebhookConfig
 }
 
 // NewHandler creates a handler instance.
-func NewHandler(svc *service.Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(
+	svc *service.Service,
+	batches *service.BatchRunner,
+	jobs *service.JobRunner,
+	tasks *asynq.Client,
+	webhooks config.WebhookConfig,
+) *Handler {
+	return &Handler{
+		svc:      svc,
+		batches:  batches,
+		jobs:     jobs,
+		tasks:    tasks,
+		webhooks: webhooks,
+	}
 }
 
 // HandleSyncBackends refreshes metadata for all backends.
@@ -39,3 +57,60 @@ func (h *Handler) HandleSyncBackendByID(ctx context.Context, task *asynq.Task) e
 	}
 	return h.svc.SyncBackendByID(ctx, backendID)
 }
+
+// HandleRunBatch executes the pending requests of a batch.
+func (h *Handler) HandleRunBatch(ctx context.Context, task *asynq.Task) error {
+	var payload RunBatchPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	batchID, err := uuid.Parse(strings.TrimSpace(payload.BatchID))
+	if err != nil {
+		return fmt.Errorf("invalid batch id: %w", asynq.SkipRetry)
+	}
+	return h.batches.Run(ctx, batchID)
+}
+
+// HandleRunJob executes an asynchronous job and schedules its completion webhook.
+func (h *Handler) HandleRunJob(ctx context.Context, task *asynq.Task) error {
+	jobID, err := decodeJobID(task)
+	if err != nil {
+		return err
+	}
+	notify, err := h.jobs.Run(ctx, jobID)
+	if err != nil || !notify {
+		return err
+	}
+	webhook, err := NewDeliverWebhookTask(jobID.String(), h.webhooks.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if _, err = h.tasks.EnqueueContext(ctx, webhook); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("enqueue webhook: %w", err)
+	}
+	return nil
+}
+
+// HandleDeliverWebhook posts a job's completion webhook. The last failed attempt marks the webhook
+// dead and leaves the task archived.
+func (h *Handler) HandleDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	jobID, err := decodeJobID(task)
+	if err != nil {
+		return err
+	}
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return h.jobs.DeliverWebhook(ctx, jobID, retried >= maxRetry)
+}
+
+func decodeJobID(task *asynq.Task) (uuid.UUID, error) {
+	var payload JobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return uuid.Nil, fmt.Errorf("decode payload: %w", err)
+	}
+	jobID, err := uuid.Parse(strings.TrimSpace(payload.JobID))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid job id: %w", asynq.SkipRetry)
+	}
+	return jobID, nil
+}
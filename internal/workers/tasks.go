@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/hibiken/asynq"
 )
@@ -11,6 +12,18 @@ import (
 const (
 	TypeSyncBackends    = "backends:sync"
 	TypeSyncBackendByID = "backends:sync_by_id"
+	TypeRunBatch        = "batches:run"
+	TypeRunJob          = "jobs:run"
+	TypeDeliverWebhook  = "jobs:webhook"
+
+	// QueueLow holds background work that should yield to backend syncs.
+	QueueLow = "low"
+
+	runBatchMaxRetry = 10
+	// runBatchGrace leaves time to write output files after a batch expires.
+	runBatchGrace = 15 * time.Minute
+
+	runJobMaxRetry = 3
 )
 
 // SyncBackendPayload defines the task payload for syncing a single backend.
@@ -18,6 +31,16 @@ type SyncBackendPayload struct {
 	BackendID string `json:"backend_id"`
 }
 
+// RunBatchPayload defines the task payload for executing a batch.
+type RunBatchPayload struct {
+	BatchID string `json:"batch_id"`
+}
+
+// JobPayload defines the task payload for running a job or delivering its webhook.
+type JobPayload struct {
+	JobID string `json:"job_id"`
+}
+
 // NewSyncBackendsTask enqueues a full backend sync.
 func NewSyncBackendsTask() (*asynq.Task, error) {
 	return asynq.NewTask(TypeSyncBackends, nil), nil
@@ -35,3 +58,62 @@ func NewSyncBackendByIDTask(backendID string) (*asynq.Task, error) {
 	}
 	return asynq.NewTask(TypeSyncBackendByID, payload), nil
 }
+
+// NewRunBatchTask enqueues a batch on the low priority queue. The task timeout covers the batch's
+// completion window so an expiring batch can still write its output files.
+func NewRunBatchTask(batchID string, expiresAt time.Time) (*asynq.Task, error) {
+	batchID = strings.TrimSpace(batchID)
+	if batchID == "" {
+		return nil, errors.New("batch id is required")
+	}
+	payload, err := json.Marshal(RunBatchPayload{BatchID: batchID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeRunBatch,
+		payload,
+		asynq.Queue(QueueLow),
+		asynq.TaskID(TypeRunBatch+":"+batchID),
+		asynq.MaxRetry(runBatchMaxRetry),
+		asynq.Timeout(time.Until(expiresAt)+runBatchGrace),
+	), nil
+}
+
+// NewRunJobTask enqueues an asynchronous generation job.
+func NewRunJobTask(jobID string, timeout time.Duration) (*asynq.Task, error) {
+	payload, err := newJobPayload(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeRunJob,
+		payload,
+		asynq.TaskID(TypeRunJob+":"+jobID),
+		asynq.MaxRetry(runJobMaxRetry),
+		asynq.Timeout(timeout),
+	), nil
+}
+
+// NewDeliverWebhookTask enqueues delivery of a job's completion webhook. Failed deliveries are
+// retried with backoff until maxAttempts is reached.
+func NewDeliverWebhookTask(jobID string, maxAttempts int) (*asynq.Task, error) {
+	payload, err := newJobPayload(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(
+		TypeDeliverWebhook,
+		payload,
+		asynq.TaskID(TypeDeliverWebhook+":"+jobID),
+		asynq.MaxRetry(max(maxAttempts-1, 0)),
+	), nil
+}
+
+func newJobPayload(jobID string) ([]byte, error) {
+	jobID = strings.TrimSpace(jobID)
+	if jobID == "" {
+		return nil, errors.New("job id is required")
+	}
+	return json.Marshal(JobPayload{JobID: jobID})
+}
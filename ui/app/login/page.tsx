@@ -7,7 +7,7 @@ import { LoginCard } from "@/components/LoginCard";
 
 export default function LoginPage() {
   const router = useRouter();
-  const { isAuthenticated, setSession } = useAuth();
+  const { isAuthenticated, setSession, setCookieSession } = useAuth();
 
   useEffect(() => {
     if (typeof window === "undefined") return;
@@ -22,6 +22,14 @@ export default function LoginPage() {
     }
 
     const params = new URLSearchParams(hash);
+    const csrfCookie = params.get("csrf_cookie");
+    if (params.get("session") === "cookie" && csrfCookie) {
+      setCookieSession(csrfCookie);
+      window.location.hash = "";
+      router.replace("/");
+      return;
+    }
+
     const token = params.get("token");
     const expires = params.get("expires_in");
 
@@ -31,7 +39,7 @@ export default function LoginPage() {
       window.location.hash = "";
       router.replace("/");
     }
-  }, [isAuthenticated, router, setSession]);
+  }, [isAuthenticated, router, setCookieSession, setSession]);
 
   return (
     <section className="flex min-h-[70vh] items-center justify-center">
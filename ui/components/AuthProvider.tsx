@@ -1,24 +1,36 @@
 "use client";
 
 import { createContext, useCallback, useContext, useEffect, useMemo, useState } from "react";
+import type { Api } from "@/lib/api/Api";
 import type { User } from "@/lib/api/data-contracts";
-import { createApiClient } from "@/lib/api-client";
-import { clearStoredAuth, loadStoredAuth, persistAuth } from "@/lib/auth-storage";
+import { createApiClient, createAuthFetch, logoutCookieSession } from "@/lib/api-client";
+import {
+  clearStoredAuth,
+  loadStoredAuth,
+  persistAuth,
+  persistCookieSession,
+} from "@/lib/auth-storage";
 import { decodeJwt, type JwtClaims } from "@/lib/jwt";
 import { toast } from "sonner";
 import { getErrorMessage } from "@/lib/error-message";
 
 interface AuthContextValue {
   token: string | null;
+  // cookieSession is true when the session lives in HttpOnly cookies and there is no token.
+  cookieSession: boolean;
   isAuthenticated: boolean;
   expiresAt: number | null;
   profile: User | null;
   claims: JwtClaims | null;
+  // api and authFetch are null while signed out.
+  api: Api<string> | null;
+  authFetch: typeof fetch | null;
   loading: boolean;
   error: string | null;
   login: () => void;
   logout: () => void;
   setSession: (token: string, expiresInSeconds?: number | null) => void;
+  setCookieSession: (csrfCookie: string) => void;
   refreshProfile: () => Promise<void>;
 }
 
@@ -26,6 +38,7 @@ const AuthContext = createContext<AuthContextValue | undefined>(undefined);
 
 export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
   const [token, setToken] = useState<string | null>(null);
+  const [cookieSession, setCookieSessionState] = useState(false);
   const [expiresAt, setExpiresAt] = useState<number | null>(null);
   const [profile, setProfile] = useState<User | null>(null);
   const [loading, setLoading] = useState(false);
@@ -33,6 +46,10 @@ export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
 
   useEffect(() => {
     const stored = loadStoredAuth();
+    if (stored.csrfCookie) {
+      setCookieSessionState(true);
+      return;
+    }
     if (stored.token) {
       if (stored.expiresAt && stored.expiresAt < Date.now()) {
         clearStoredAuth();
@@ -43,40 +60,60 @@ export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
     }
   }, []);
 
-  const logout = useCallback(() => {
+  const clearSession = useCallback(() => {
     clearStoredAuth();
     setToken(null);
+    setCookieSessionState(false);
     setExpiresAt(null);
     setProfile(null);
   }, []);
 
+  const logout = useCallback(() => {
+    if (cookieSession) {
+      logoutCookieSession().catch((err) => console.error("logout", err));
+    }
+    clearSession();
+  }, [clearSession, cookieSession]);
+
   const setSession = useCallback((newToken: string, expiresInSeconds?: number | null) => {
     const derivedExpiry = expiresInSeconds
       ? Date.now() + expiresInSeconds * 1000
       : null;
     setToken(newToken);
+    setCookieSessionState(false);
     setExpiresAt(derivedExpiry);
     persistAuth(newToken, derivedExpiry);
   }, []);
 
+  const setCookieSession = useCallback((csrfCookie: string) => {
+    persistCookieSession(csrfCookie);
+    setToken(null);
+    setExpiresAt(null);
+    setCookieSessionState(true);
+  }, []);
+
   const login = useCallback(() => {
     if (typeof window === "undefined") return;
     window.location.href = "/auth/login";
   }, []);
 
+  const signedIn = Boolean(token) || cookieSession;
+
+  const api = useMemo(() => (signedIn ? createApiClient(token) : null), [signedIn, token]);
+  const authFetch = useMemo(() => (signedIn ? createAuthFetch(token) : null), [signedIn, token]);
+
   const refreshProfile = useCallback(async () => {
-    if (!token) return;
+    if (!api) return;
     setLoading(true);
     setError(null);
     try {
-      const api = createApiClient(token);
       const response = await api.profileList();
       setProfile(response.data ?? null);
     } catch (err) {
       console.error("load profile", err);
       const error = err as { status?: number };
       if (error?.status === 401) {
-        logout();
+        clearSession();
         setError("Session expired. Please sign in again.");
       } else {
         const message = getErrorMessage(err, "Unable to load profile");
@@ -86,33 +123,64 @@ export const AuthProvider = ({ children }: { children: React.ReactNode }) => {
     } finally {
       setLoading(false);
     }
-  }, [logout, token]);
+  }, [api, clearSession]);
 
   useEffect(() => {
-    if (!token) {
+    if (!api) {
       setProfile(null);
       return;
     }
     refreshProfile();
-  }, [token, refreshProfile]);
+  }, [api, refreshProfile]);
 
-  const claims = useMemo(() => (token ? decodeJwt(token) : null), [token]);
+  // Cookie sessions keep the access token out of reach of scripts, so the role and scopes come
+  // from the profile instead.
+  const claims = useMemo<JwtClaims | null>(() => {
+    if (token) return decodeJwt(token);
+    if (!cookieSession || !profile) return null;
+    return {
+      sub: profile.id,
+      email: profile.email,
+      role: profile.role,
+      scopes: profile.scopes,
+    };
+  }, [cookieSession, profile, token]);
 
   const value = useMemo<AuthContextValue>(
     () => ({
       token,
-      isAuthenticated: Boolean(token),
+      cookieSession,
+      isAuthenticated: signedIn,
       expiresAt,
       profile,
       claims,
+      api,
+      authFetch,
       loading,
       error,
       login,
       logout,
       setSession,
+      setCookieSession,
       refreshProfile,
     }),
-    [claims, error, expiresAt, loading, login, logout, profile, setSession, token, refreshProfile],
+    [
+      api,
+      authFetch,
+      claims,
+      cookieSession,
+      error,
+      expiresAt,
+      loading,
+      login,
+      logout,
+      profile,
+      refreshProfile,
+      setCookieSession,
+      setSession,
+      signedIn,
+      token,
+    ],
   );
 
   return <AuthContext.Provider value={value}>{children}</AuthContext.Provider>;
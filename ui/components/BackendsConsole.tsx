@@ -1,7 +1,6 @@
 "use client";
 
 import { FormEvent, useCallback, useEffect, useMemo, useRef, useState } from "react";
-import { createApiClient } from "@/lib/api-client";
 import { useAuth } from "@/components/AuthProvider";
 import type { Backend } from "@/lib/api/data-contracts";
 import { toast } from "sonner";
@@ -170,7 +169,7 @@ const formatUpdated = (value?: string) => {
 };
 
 export const BackendsConsole = () => {
-  const { token } = useAuth();
+  const { api, authFetch } = useAuth();
   const [backends, setBackends] = useState<Backend[]>([]);
   const [selectedBackend, setSelectedBackend] = useState<string>("");
   const [action, setAction] = useState<AdminAction>("ps");
@@ -189,11 +188,10 @@ export const BackendsConsole = () => {
   }, [isStreaming, result]);
 
   const fetchBackends = useCallback(async () => {
-    if (!token) return;
+    if (!api) return;
     setListLoading(true);
     setError(null);
     try {
-      const api = createApiClient(token);
       const response = await api.backendsList();
       setBackends(response.data ?? []);
       setSelectedBackend((prev) => {
@@ -208,37 +206,35 @@ export const BackendsConsole = () => {
     } finally {
       setListLoading(false);
     }
-  }, [token]);
+  }, [api]);
 
   useEffect(() => {
     fetchBackends();
   }, [fetchBackends]);
 
-  const createRequestInit = useCallback(
-    (request: ActionRequest): RequestInit => {
-      if (!token) {
-        throw new Error("missing token");
+  const sendRequest = useCallback(
+    (request: ActionRequest): Promise<Response> => {
+      if (!authFetch) {
+        throw new Error("not signed in");
       }
       const payload = sanitizeBody(request.body);
       const shouldSendBody = request.method !== "GET" && payload !== undefined;
-      const headers: Record<string, string> = {
-        Authorization: `Bearer ${token}`,
-      };
+      const headers: Record<string, string> = {};
       if (shouldSendBody) {
         headers["Content-Type"] = "application/json";
       }
-      return {
+      return authFetch(request.path, {
         method: request.method,
         headers,
         body: shouldSendBody ? JSON.stringify(payload) : undefined,
-      };
+      });
     },
-    [token],
+    [authFetch],
   );
 
   const executeStandardRequest = useCallback(
     async (request: ActionRequest) => {
-      const response = await fetch(request.path, createRequestInit(request));
+      const response = await sendRequest(request);
       const text = await response.text();
       if (!response.ok) {
         throw new Error(text || response.statusText);
@@ -251,12 +247,12 @@ export const BackendsConsole = () => {
       }
       setResult(printable);
     },
-    [createRequestInit],
+    [sendRequest],
   );
 
   const executeStreamingRequest = useCallback(
     async (request: ActionRequest) => {
-      const response = await fetch(request.path, createRequestInit(request));
+      const response = await sendRequest(request);
       if (!response.ok) {
         const text = await response.text();
         throw new Error(text || response.statusText);
@@ -280,12 +276,12 @@ export const BackendsConsole = () => {
         setResult("Stream completed");
       }
     },
-    [createRequestInit],
+    [sendRequest],
   );
 
   const onSubmit = async (event: FormEvent) => {
     event.preventDefault();
-    if (!token) {
+    if (!api) {
       setError("Sign in before running console actions");
       return;
     }
@@ -295,7 +291,6 @@ export const BackendsConsole = () => {
       setError(null);
       setResult("");
       try {
-        const api = createApiClient(token);
         const resp = await api.backendsTagsList(selectedBackend);
         setResult(JSON.stringify(resp.data, null, 2));
       } catch (err) {
@@ -1,7 +1,6 @@
 "use client";
 
 import { useEffect, useMemo, useState } from "react";
-import { createApiClient } from "@/lib/api-client";
 import { useAuth } from "@/components/AuthProvider";
 import { toast } from "sonner";
 import { getErrorMessage } from "@/lib/error-message";
@@ -23,18 +22,17 @@ const formatDate = (value?: string | number) => {
 };
 
 export const ModelsPanel = () => {
-  const { token } = useAuth();
+  const { api } = useAuth();
   const [models, setModels] = useState<Model[]>([]);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
 
   useEffect(() => {
     const fetchModels = async () => {
-      if (!token) return;
+      if (!api) return;
       setLoading(true);
       setError(null);
       try {
-        const api = createApiClient(token);
         const response = await api.modelsList();
         const list = (response.data as ModelList | undefined)?.data || [];
         setModels(list);
@@ -48,7 +46,7 @@ export const ModelsPanel = () => {
       }
     };
     fetchModels();
-  }, [token]);
+  }, [api]);
 
   const tableRows = useMemo(() => models || [], [models]);
 
@@ -1,7 +1,6 @@
 "use client";
 
 import { FormEvent, useCallback, useEffect, useMemo, useState } from "react";
-import { createApiClient } from "@/lib/api-client";
 import { TokenExpiryPicker } from "@/components/TokenExpiryPicker";
 import { useAuth } from "@/components/AuthProvider";
 import { toast } from "sonner";
@@ -54,7 +53,7 @@ const formatDate = (value?: string) => {
 };
 
 export const PatPanel = () => {
-  const { token, claims } = useAuth();
+  const { api, claims } = useAuth();
   const [tokens, setTokens] = useState<PersonalAccessToken[]>([]);
   const [loading, setLoading] = useState(false);
   const [submitting, setSubmitting] = useState(false);
@@ -89,11 +88,10 @@ export const PatPanel = () => {
   );
 
   const refreshTokens = useCallback(async () => {
-    if (!token) return;
+    if (!api) return;
     setLoading(true);
     setError(null);
     try {
-      const api = createApiClient(token);
       const response = await api.profileTokensList();
       setTokens(response.data ?? []);
     } catch (err) {
@@ -104,7 +102,7 @@ export const PatPanel = () => {
     } finally {
       setLoading(false);
     }
-  }, [token]);
+  }, [api]);
 
   useEffect(() => {
     refreshTokens();
@@ -112,12 +110,11 @@ export const PatPanel = () => {
 
   const onSubmit = async (event: FormEvent) => {
     event.preventDefault();
-    if (!token) return;
+    if (!api) return;
     setSubmitting(true);
     setError(null);
     setIssuedToken(null);
     try {
-      const api = createApiClient(token);
       const expiresInSeconds = form.expiresAt
         ? Math.max(
             Math.floor((form.expiresAt.getTime() - Date.now()) / 1000),
@@ -149,9 +146,8 @@ export const PatPanel = () => {
   };
 
   const onDelete = async (tokenId?: string) => {
-    if (!token || !tokenId) return;
+    if (!api || !tokenId) return;
     try {
-      const api = createApiClient(token);
       await api.profileTokensDelete(tokenId);
       await refreshTokens();
     } catch (err) {
@@ -1,6 +1,77 @@
 import { Api } from "@/lib/api/Api";
+import { readCsrfToken } from "@/lib/auth-storage";
+
+const CSRF_HEADER = "X-CSRF-Token";
+const SAFE_METHODS = new Set(["GET", "HEAD", "OPTIONS"]);
+
+let pendingRefresh: Promise<boolean> | null = null;
+
+const csrfHeaders = (method: string, init?: HeadersInit): Headers => {
+  const headers = new Headers(init);
+  if (!SAFE_METHODS.has(method.toUpperCase())) {
+    const csrf = readCsrfToken();
+    if (csrf) headers.set(CSRF_HEADER, csrf);
+  }
+  return headers;
+};
+
+// Refresh tokens work once, so concurrent 401s share a single refresh request.
+export const refreshCookieSession = (): Promise<boolean> => {
+  if (!pendingRefresh) {
+    pendingRefresh = fetch("/auth/refresh", {
+      method: "POST",
+      credentials: "same-origin",
+      headers: csrfHeaders("POST"),
+    })
+      .then((response) => response.ok)
+      .catch(() => false)
+      .finally(() => {
+        pendingRefresh = null;
+      });
+  }
+  return pendingRefresh;
+};
+
+export const logoutCookieSession = async () => {
+  await fetch("/auth/logout", {
+    method: "POST",
+    credentials: "same-origin",
+    headers: csrfHeaders("POST"),
+  });
+};
+
+// cookieFetch authenticates with the session cookies, echoes the CSRF cookie on requests that
+// change state, and refreshes the session once when the access token has expired.
+const cookieFetch: typeof fetch = async (input, init) => {
+  const send = () =>
+    fetch(input, {
+      ...init,
+      credentials: "same-origin",
+      headers: csrfHeaders(init?.method ?? "GET", init?.headers),
+    });
+  const response = await send();
+  if (response.status !== 401 || !(await refreshCookieSession())) {
+    return response;
+  }
+  return send();
+};
+
+// createAuthFetch returns a fetch that sends the bearer token, or the session cookies when the
+// session lives in cookies and there is no token.
+export const createAuthFetch = (token?: string | null): typeof fetch => {
+  if (!token) return cookieFetch;
+  return (input, init) => {
+    const headers = new Headers(init?.headers);
+    headers.set("Authorization", `Bearer ${token}`);
+    return fetch(input, { ...init, headers });
+  };
+};
+
+export const createApiClient = (token?: string | null) => {
+  if (!token) {
+    return new Api<string>({ baseUrl: "", customFetch: cookieFetch });
+  }
 
-export const createApiClient = (token?: string) => {
   const client = new Api<string>({
     baseUrl: "",
     securityWorker: (securityData) => {
@@ -12,10 +83,7 @@ export const createApiClient = (token?: string) => {
       };
     },
   });
-
-  if (token) {
-    client.setSecurityData(token);
-  }
+  client.setSecurityData(token);
 
   return client;
 };
@@ -1,9 +1,12 @@
 const TOKEN_KEY = "llamero.token";
 const EXP_KEY = "llamero.token_exp";
+const CSRF_COOKIE_KEY = "llamero.csrf_cookie";
 
 export type StoredAuth = {
   token: string | null;
   expiresAt: number | null;
+  // csrfCookie is set when the session lives in HttpOnly cookies instead of a stored token.
+  csrfCookie: string | null;
 };
 
 const readNumber = (value: string | null): number | null => {
@@ -14,17 +17,19 @@ const readNumber = (value: string | null): number | null => {
 
 export const loadStoredAuth = (): StoredAuth => {
   if (typeof window === "undefined") {
-    return { token: null, expiresAt: null };
+    return { token: null, expiresAt: null, csrfCookie: null };
   }
 
   return {
     token: window.localStorage.getItem(TOKEN_KEY),
     expiresAt: readNumber(window.localStorage.getItem(EXP_KEY)),
+    csrfCookie: window.localStorage.getItem(CSRF_COOKIE_KEY),
   };
 };
 
 export const persistAuth = (token: string, expiresAt?: number | null) => {
   if (typeof window === "undefined") return;
+  window.localStorage.removeItem(CSRF_COOKIE_KEY);
   window.localStorage.setItem(TOKEN_KEY, token);
   if (expiresAt && Number.isFinite(expiresAt)) {
     window.localStorage.setItem(EXP_KEY, String(expiresAt));
@@ -33,8 +38,27 @@ export const persistAuth = (token: string, expiresAt?: number | null) => {
   }
 };
 
+export const persistCookieSession = (csrfCookie: string) => {
+  if (typeof window === "undefined") return;
+  window.localStorage.removeItem(TOKEN_KEY);
+  window.localStorage.removeItem(EXP_KEY);
+  window.localStorage.setItem(CSRF_COOKIE_KEY, csrfCookie);
+};
+
 export const clearStoredAuth = () => {
   if (typeof window === "undefined") return;
   window.localStorage.removeItem(TOKEN_KEY);
   window.localStorage.removeItem(EXP_KEY);
+  window.localStorage.removeItem(CSRF_COOKIE_KEY);
+};
+
+export const readCsrfToken = (): string | null => {
+  if (typeof document === "undefined") return null;
+  const name = loadStoredAuth().csrfCookie;
+  if (!name) return null;
+  for (const entry of document.cookie.split(";")) {
+    const [key, ...rest] = entry.trim().split("=");
+    if (key === name) return decodeURIComponent(rest.join("="));
+  }
+  return null;
 };
@@ -19,8 +19,11 @@ export const getErrorMessage = (err: unknown, fallback: string): string => {
 
   const candidatePaths: string[][] = [
     ["error"],
+    ["error", "message"],
     ["error", "error"],
+    ["error", "error", "message"],
     ["data", "error"],
+    ["data", "error", "message"],
     ["message"],
     ["statusText"],
   ];